@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/alerts"
+	"github.com/Rajchodisetti/trading-app/internal/broker"
+	"github.com/Rajchodisetti/trading-app/internal/decision"
+	"github.com/Rajchodisetti/trading-app/internal/observ"
+	"github.com/Rajchodisetti/trading-app/internal/outbox"
+	"github.com/Rajchodisetti/trading-app/internal/portfolio"
+	"github.com/Rajchodisetti/trading-app/internal/risk"
+)
+
+// submitOrder sizes an actionable decision, rechecks it through the outbox
+// guard and submits it to the broker. The order is recorded in the outbox as
+// NEW before submission, then as SENT or REJECTED.
+func (p *pipeline) submitOrder(act decision.ProposedAction, feat decision.Features, decidedAt time.Time) error {
+	side := broker.SideForIntent(act.Intent)
+	if side == "" {
+		return nil
+	}
+
+	now := p.clock.Now().UTC()
+
+	// Parse reason to get fused score for idempotency key
+	var reason struct {
+		FusedScore float64 `json:"fused_score"`
+	}
+	if err := json.Unmarshal([]byte(act.ReasonJSON), &reason); err != nil {
+		return fmt.Errorf("parse reason for idempotency: %w", err)
+	}
+
+	// Generate idempotency key
+	idempotencyKey := outbox.GenerateIdempotencyKey(act.Symbol, act.Intent, now, reason.FusedScore)
+
+	// Check for recent duplicate
+	hasRecent, err := p.ob.HasRecentOrder(idempotencyKey)
+	if err != nil {
+		return fmt.Errorf("check recent orders: %w", err)
+	}
+	if hasRecent {
+		observ.IncCounter("paper_order_dedupe_total", map[string]string{"symbol": act.Symbol})
+		return nil
+	}
+
+	// Size the notional into shares against the current price
+	positionQty := 0
+	if p.portfolioMgr != nil {
+		if pos, ok := p.portfolioMgr.GetPosition(act.Symbol); ok {
+			positionQty = pos.Quantity
+		}
+	}
+	size := p.sizer.Size(risk.SizeRequest{
+		Symbol:      act.Symbol,
+		Intent:      act.Intent,
+		NotionalUSD: act.ScaledNotional,
+		Price:       feat.Last,
+		PositionQty: positionQty,
+	})
+	if size.Quantity == 0 {
+		observ.Log("order_sized_to_zero", map[string]any{
+			"symbol":   act.Symbol,
+			"intent":   act.Intent,
+			"notional": act.ScaledNotional,
+			"price":    feat.Last,
+			"reason":   size.Skipped,
+		})
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Choose order type and limit against the book the decision saw
+	market := broker.Market{
+		Bid:        feat.Bid,
+		Ask:        feat.Ask,
+		Last:       feat.Last,
+		AfterHours: feat.Premarket || feat.Postmarket,
+	}
+	req, protected := p.pricing.Price(broker.OrderRequest{
+		ClientOrderID: idempotencyKey,
+		Symbol:        act.Symbol,
+		Side:          side,
+		Quantity:      size.Quantity,
+		Intent:        act.Intent,
+	}, market)
+	if protected {
+		observ.IncCounter("order_price_protection_total", map[string]string{"symbol": act.Symbol})
+		observ.Log("order_price_protected", map[string]any{
+			"symbol":      act.Symbol,
+			"intent":      act.Intent,
+			"bid":         feat.Bid,
+			"ask":         feat.Ask,
+			"spread_bps":  feat.SpreadBps,
+			"after_hours": feat.Premarket || feat.Postmarket,
+			"limit_price": req.LimitPrice,
+		})
+	}
+
+	order := outbox.Order{
+		ID:             idempotencyKey,
+		Symbol:         act.Symbol,
+		Intent:         act.Intent,
+		Timestamp:      now,
+		Status:         string(broker.StatusNew),
+		IdempotencyKey: idempotencyKey,
+		Side:           side,
+		Quantity:       req.Quantity,
+		NotionalUSD:    size.NotionalUSD,
+		OrderType:      req.Type,
+		LimitPrice:     req.LimitPrice,
+		TimeInForce:    broker.TimeInForce(req.Type),
+	}
+
+	if p.guard != nil {
+		// Cancel instead of sending if the price moved or the decision aged out
+		guardReq := risk.CreateOrderRequest(order, decidedAt, risk.DecisionContext{
+			Symbol:        act.Symbol,
+			Intent:        act.Intent,
+			Quantity:      req.Quantity,
+			Price:         market.Mid(),
+			Score:         reason.FusedScore,
+			CorrelationID: idempotencyKey,
+			Timestamp:     decidedAt,
+		}, &risk.ExposureInfo{MidPrice: market.Mid()}, nil)
+		result, err := p.guard.Validate(guardReq)
+		if err != nil {
+			return err
+		}
+		if !result.Approved {
+			if err := p.guard.WriteCancellation(p.ob, guardReq, result); err != nil {
+				return err
+			}
+			observ.Log("order_guard_cancelled", map[string]any{
+				"symbol":              act.Symbol,
+				"intent":              act.Intent,
+				"reason":              result.Reason,
+				"price_drift_pct":     result.PriceDrift,
+				"time_since_decision": result.TimeSinceDecision.Seconds(),
+			})
+			return nil
+		}
+	}
+
+	// Journal the order as NEW before the broker sees it, so a crash
+	// mid-submit leaves a record for startup recovery to resolve
+	if err := p.orders.begin(order); err != nil {
+		return fmt.Errorf("write order: %w", err)
+	}
+
+	state, err := p.broker.Submit(ctx, req)
+	if err != nil {
+		order.Timestamp = p.clock.Now().UTC()
+		order.Status = string(broker.StatusRejected)
+		order.Reason = err.Error()
+		if werr := p.orders.finish(order); werr != nil {
+			log.Printf("write rejected order %s: %v", order.ID, werr)
+		}
+		observ.IncCounter("paper_order_rejects_total", map[string]string{"symbol": act.Symbol})
+		return fmt.Errorf("submit order: %w", err)
+	}
+
+	sent := orderFromState(state, idempotencyKey)
+	sent.NotionalUSD = size.NotionalUSD
+	if err := p.orders.finish(sent); err != nil {
+		return fmt.Errorf("write order: %w", err)
+	}
+
+	// Feed the caps and cooldown gates
+	if p.capsMgr != nil {
+		p.capsMgr.RecordTrade(act.Symbol, side, size.NotionalUSD)
+	}
+	if p.cooldownMgr != nil {
+		p.cooldownMgr.RecordTrade(act.Symbol, act.Intent, now)
+	}
+
+	observ.IncCounter("paper_orders_total", map[string]string{
+		"symbol": act.Symbol,
+		"intent": act.Intent,
+	})
+	return nil
+}
+
+// consumeBroker records broker fills and status changes until both streams close
+func (p *pipeline) consumeBroker() {
+	fills, updates := p.broker.Fills(), p.broker.Updates()
+	for fills != nil || updates != nil {
+		select {
+		case fill, ok := <-fills:
+			if !ok {
+				fills = nil
+				continue
+			}
+			p.recordFill(fill)
+		case state, ok := <-updates:
+			if !ok {
+				updates = nil
+				continue
+			}
+			// submitOrder records NEW and SENT itself; record later transitions
+			if state.Status == broker.StatusNew || state.Status == broker.StatusSent {
+				continue
+			}
+			if err := p.orders.update(orderFromState(state, state.ClientOrderID)); err != nil {
+				log.Printf("write order update for %s: %v", state.ID, err)
+			}
+		}
+	}
+}
+
+// orderJournal writes order records to the outbox in lifecycle order. The
+// broker publishes from its own goroutine, so a fill's status update can
+// arrive before submitOrder has recorded the order as SENT; such updates are
+// held until then instead of being overwritten by the older status.
+type orderJournal struct {
+	mu       sync.Mutex
+	ob       *outbox.Outbox
+	inFlight map[string][]outbox.Order // order id -> updates held until submit is recorded
+}
+
+func newOrderJournal(ob *outbox.Outbox) *orderJournal {
+	return &orderJournal{ob: ob, inFlight: make(map[string][]outbox.Order)}
+}
+
+// begin records a NEW order and holds its updates until finish
+func (j *orderJournal) begin(order outbox.Order) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.ob.WriteOrder(order); err != nil {
+		return err
+	}
+	j.inFlight[order.ID] = nil
+	return nil
+}
+
+// finish records the submit outcome, then any updates held meanwhile
+func (j *orderJournal) finish(order outbox.Order) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	held := j.inFlight[order.ID]
+	delete(j.inFlight, order.ID)
+
+	if err := j.ob.WriteOrder(order); err != nil {
+		return err
+	}
+	for _, update := range held {
+		if err := j.ob.WriteOrder(update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// update records a broker status change, holding it while the order's
+// submit is still in flight
+func (j *orderJournal) update(order outbox.Order) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if held, ok := j.inFlight[order.ID]; ok {
+		j.inFlight[order.ID] = append(held, order)
+		return nil
+	}
+	return j.ob.WriteOrder(order)
+}
+
+// recordFill writes a fill to the outbox and applies it to the portfolio
+func (p *pipeline) recordFill(fill outbox.Fill) {
+	if err := p.ob.WriteFill(fill); err != nil {
+		log.Printf("write fill for %s: %v", fill.OrderID, err)
+		return
+	}
+
+	// Update portfolio state on fill
+	if p.portfolioMgr != nil {
+		qty := int(fill.Quantity)
+		if fill.Side == "SELL" {
+			qty = -qty
+		}
+		if err := p.portfolioMgr.ApplyFill(fill.OrderID, fill.Symbol, qty, fill.Price, portfolio.Costs{Commission: fill.Commission, Fees: fill.Fees}, fill.Timestamp); err != nil {
+			log.Printf("update portfolio position for %s: %v", fill.Symbol, err)
+		}
+	}
+
+	observ.IncCounter("paper_fills_total", map[string]string{
+		"symbol": fill.Symbol,
+		"side":   fill.Side,
+	})
+	observ.Observe("paper_fill_latency_ms", float64(fill.LatencyMs), map[string]string{"symbol": fill.Symbol})
+	observ.Observe("paper_fill_slippage_bps", float64(fill.SlippageBps), map[string]string{"symbol": fill.Symbol})
+}
+
+// orderFromState converts the broker's view of an order into an outbox record
+func orderFromState(state broker.OrderState, idempotencyKey string) outbox.Order {
+	return outbox.Order{
+		ID:             state.ID,
+		Symbol:         state.Symbol,
+		Intent:         state.Intent,
+		Timestamp:      state.UpdatedAt,
+		Status:         string(state.Status),
+		IdempotencyKey: idempotencyKey,
+		Side:           state.Side,
+		Quantity:       state.Quantity,
+		OrderType:      state.Type,
+		LimitPrice:     state.LimitPrice,
+		TimeInForce:    broker.TimeInForce(state.Type),
+		FilledQty:      state.FilledQty,
+	}
+}
+
+// recheckRecovered runs a lost order's remainder through the outbox guard
+// before recovery resubmits it. The decision price is the order's limit, or
+// its sized notional per share for market orders.
+func (p *pipeline) recheckRecovered(ctx context.Context, order outbox.Order, placedAt time.Time) (string, error) {
+	price := order.LimitPrice
+	if price == 0 && order.Quantity > 0 {
+		price = order.NotionalUSD / float64(order.Quantity)
+	}
+	guardReq := risk.CreateOrderRequest(order, placedAt, risk.DecisionContext{
+		Symbol:        order.Symbol,
+		Intent:        order.Intent,
+		Quantity:      order.Quantity,
+		Price:         price,
+		CorrelationID: order.IdempotencyKey,
+		Timestamp:     placedAt,
+	}, &risk.ExposureInfo{MidPrice: price}, nil)
+	result, err := p.guard.Validate(guardReq)
+	if err != nil {
+		return "", err
+	}
+	if result.Approved {
+		return "", nil
+	}
+	return result.Reason, nil
+}
+
+// recoverOrders resolves orders a previous run left open in the outbox,
+// applies recovered fills to the portfolio, and reports what it did
+func (p *pipeline) recoverOrders() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Lost orders are only sent again while their decision is still fresh
+	var policy broker.RecoveryPolicy
+	if p.guard != nil {
+		policy.MaxAge = p.guard.MaxDecisionAge()
+		policy.Guard = p.recheckRecovered
+	}
+	report, err := broker.Reconcile(ctx, p.ob, p.broker, p.clock.Now().UTC(), policy)
+	if err != nil {
+		log.Printf("outbox recovery: %v", err)
+		return
+	}
+	if report.Open == 0 {
+		return
+	}
+
+	for _, fill := range report.Recovered {
+		if p.portfolioMgr == nil {
+			break
+		}
+		qty := int(fill.Quantity)
+		if fill.Side == "SELL" {
+			qty = -qty
+		}
+		if err := p.portfolioMgr.ApplyFill(fill.OrderID, fill.Symbol, qty, fill.Price, portfolio.Costs{Commission: fill.Commission, Fees: fill.Fees}, fill.Timestamp); err != nil {
+			log.Printf("apply recovered fill for %s: %v", fill.Symbol, err)
+		}
+	}
+
+	// Keep the log line bounded after long unattended runs
+	orders := report.Orders
+	if len(orders) > 50 {
+		orders = orders[:50]
+	}
+	counts := report.Counts()
+	observ.Log("outbox_recovery", map[string]any{
+		"open":      report.Open,
+		"resolved":  counts,
+		"recovered": len(report.Recovered),
+		"orders":    orders,
+		"errors":    report.Errors,
+	})
+
+	if p.slackClient != nil {
+		var parts []string
+		for status, n := range counts {
+			parts = append(parts, fmt.Sprintf("%d %s", n, status))
+		}
+		sort.Strings(parts)
+		summary := fmt.Sprintf("%d open orders at startup: %s; %d fills recovered", report.Open, strings.Join(parts, ", "), len(report.Recovered))
+		if len(report.Errors) > 0 {
+			summary += fmt.Sprintf("; %d unresolved", len(report.Errors))
+		}
+		p.slackClient.SendAlert(alerts.AlertRequest{
+			Symbol:      "OUTBOX",
+			Intent:      "RECONCILE",
+			TradingMode: p.cfg.TradingMode,
+			GlobalPause: p.cfg.GlobalPause,
+			Timestamp:   time.Now(),
+			Summary:     summary,
+		})
+	}
+}
@@ -10,65 +10,38 @@ import (
 	"net/url"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/Rajchodisetti/trading-app/internal/adapters"
 	"github.com/Rajchodisetti/trading-app/internal/alerts"
+	"github.com/Rajchodisetti/trading-app/internal/broker"
+	"github.com/Rajchodisetti/trading-app/internal/clock"
 	"github.com/Rajchodisetti/trading-app/internal/config"
 	"github.com/Rajchodisetti/trading-app/internal/decision"
+	"github.com/Rajchodisetti/trading-app/internal/durable"
+	"github.com/Rajchodisetti/trading-app/internal/ingest"
 	"github.com/Rajchodisetti/trading-app/internal/observ"
 	"github.com/Rajchodisetti/trading-app/internal/outbox"
 	"github.com/Rajchodisetti/trading-app/internal/portfolio"
 	"github.com/Rajchodisetti/trading-app/internal/risk"
+	"github.com/Rajchodisetti/trading-app/internal/strategy"
 	"github.com/Rajchodisetti/trading-app/internal/transport"
 )
 
 type haltsFile struct {
-	Halts []struct {
-		Symbol string `json:"symbol"`
-		Halted bool   `json:"halted"`
-	} `json:"halts"`
+	Halts []ingest.Halt `json:"halts"`
 }
 
 type newsFile struct {
-	News []struct {
-		ID             string   `json:"id"`
-		Provider       string   `json:"provider"`
-		PublishedAt    string   `json:"published_at_utc"`
-		Headline       string   `json:"headline"`
-		Body           string   `json:"body"`
-		URLs           []string `json:"urls"`
-		Tickers        []string `json:"tickers"`
-		IsPR           bool     `json:"is_press_release"`
-		IsCorrection   bool     `json:"is_correction"`
-		SupersedesID   *string  `json:"supersedes_id"`
-		SourceWeight   float64  `json:"source_weight"`
-		Hash           string   `json:"headline_hash"`
-	} `json:"news"`
+	News []ingest.NewsItem `json:"news"`
 }
 
 type ticksFile struct {
-	Ticks []struct {
-		Symbol     string  `json:"symbol"`
-		Last       float64 `json:"last"`
-		VWAP5m     float64 `json:"vwap_5m"`
-		RelVolume  float64 `json:"rel_volume"`
-		Halted     bool    `json:"halted"`
-		Premarket  bool    `json:"premarket"`
-		Postmarket bool    `json:"postmarket"`
-		Bid        float64 `json:"bid"`
-		Ask        float64 `json:"ask"`
-	} `json:"ticks"`
+	Ticks []ingest.Tick `json:"ticks"`
 }
 
 type earningsFile struct {
-	Earnings []struct {
-		Symbol   string `json:"symbol"`
-		StartUTC string `json:"start_utc"`
-		EndUTC   string `json:"end_utc"`
-		Status   string `json:"status"`
-	} `json:"earnings"`
+	Earnings []ingest.Earnings `json:"earnings"`
 }
 
 // Wire event structures
@@ -167,176 +140,6 @@ func (w *WireClient) WaitForHealth() error {
 	return fmt.Errorf("wire server health check failed after 30 attempts")
 }
 
-// Process wire events into internal data structures
-func processWireEvents(events []WireEvent) (haltsFile, newsFile, ticksFile, earningsFile, error) {
-	var hf haltsFile
-	var nf newsFile
-	var tf ticksFile
-	var ef earningsFile
-	
-	for _, event := range events {
-		switch event.Type {
-		case "news":
-			// Extract nested payload from wire event structure
-			payloadBytes, err := json.Marshal(event.Payload)
-			if err != nil {
-				log.Printf("Failed to marshal news payload %s: %v", event.ID, err)
-				continue
-			}
-			
-			var wireEventStruct struct {
-				Payload json.RawMessage `json:"payload"`
-			}
-			if err := json.Unmarshal(payloadBytes, &wireEventStruct); err != nil {
-				log.Printf("Failed to parse wire event structure %s: %v", event.ID, err)
-				continue
-			}
-			
-			var newsItem struct {
-				ID             string   `json:"id"`
-				Provider       string   `json:"provider"`
-				PublishedAtUTC string   `json:"published_at_utc"`
-				Headline       string   `json:"headline"`
-				Body           string   `json:"body"`
-				URLs           []string `json:"urls"`
-				Tickers        []string `json:"tickers"`
-				IsPressRelease bool     `json:"is_press_release"`
-				IsCorrection   bool     `json:"is_correction"`
-				SupersedesID   *string  `json:"supersedes_id"`
-				SourceWeight   float64  `json:"source_weight"`
-				HeadlineHash   string   `json:"headline_hash"`
-			}
-			if err := json.Unmarshal(wireEventStruct.Payload, &newsItem); err != nil {
-				log.Printf("Failed to parse news data %s: %v", event.ID, err)
-				continue
-			}
-			nf.News = append(nf.News, struct {
-				ID             string   `json:"id"`
-				Provider       string   `json:"provider"`
-				PublishedAt    string   `json:"published_at_utc"`
-				Headline       string   `json:"headline"`
-				Body           string   `json:"body"`
-				URLs           []string `json:"urls"`
-				Tickers        []string `json:"tickers"`
-				IsPR           bool     `json:"is_press_release"`
-				IsCorrection   bool     `json:"is_correction"`
-				SupersedesID   *string  `json:"supersedes_id"`
-				SourceWeight   float64  `json:"source_weight"`
-				Hash           string   `json:"headline_hash"`
-			}{
-				ID:           newsItem.ID,
-				Provider:     newsItem.Provider,
-				PublishedAt:  newsItem.PublishedAtUTC,
-				Headline:     newsItem.Headline,
-				Body:         newsItem.Body,
-				URLs:         newsItem.URLs,
-				Tickers:      newsItem.Tickers,
-				IsPR:         newsItem.IsPressRelease,
-				IsCorrection: newsItem.IsCorrection,
-				SupersedesID: newsItem.SupersedesID,
-				SourceWeight: newsItem.SourceWeight,
-				Hash:         newsItem.HeadlineHash,
-			})
-			
-		case "tick":
-			// The event.Payload contains the full wire event structure, we need the nested "payload" field
-			payloadBytes, err := json.Marshal(event.Payload)
-			if err != nil {
-				log.Printf("Failed to marshal tick payload %s: %v", event.ID, err)
-				continue
-			}
-			
-			// First unmarshal to get the wire event structure
-			var wireEventStruct struct {
-				Payload json.RawMessage `json:"payload"`
-			}
-			if err := json.Unmarshal(payloadBytes, &wireEventStruct); err != nil {
-				log.Printf("Failed to parse wire event structure %s: %v", event.ID, err)
-				continue
-			}
-			
-			// Now unmarshal the nested payload to our tick struct
-			var tick struct {
-				Symbol     string  `json:"symbol"`
-				Last       float64 `json:"last"`
-				VWAP5m     float64 `json:"vwap_5m"`
-				RelVolume  float64 `json:"rel_volume"`
-				Halted     bool    `json:"halted"`
-				Bid        float64 `json:"bid"`
-				Ask        float64 `json:"ask"`
-			}
-			if err := json.Unmarshal(wireEventStruct.Payload, &tick); err != nil {
-				log.Printf("Failed to parse tick data %s: %v", event.ID, err)
-				continue
-			}
-			// Removed debug logging
-			tf.Ticks = append(tf.Ticks, struct {
-				Symbol     string  `json:"symbol"`
-				Last       float64 `json:"last"`
-				VWAP5m     float64 `json:"vwap_5m"`
-				RelVolume  float64 `json:"rel_volume"`
-				Halted     bool    `json:"halted"`
-				Premarket  bool    `json:"premarket"`
-				Postmarket bool    `json:"postmarket"`
-				Bid        float64 `json:"bid"`
-				Ask        float64 `json:"ask"`
-			}{
-				Symbol:     tick.Symbol,
-				Last:       tick.Last,
-				VWAP5m:     tick.VWAP5m,
-				RelVolume:  tick.RelVolume,
-				Halted:     tick.Halted,
-				Premarket:  false, // Wire data doesn't include market session info
-				Postmarket: false, // Wire data doesn't include market session info
-				Bid:        tick.Bid,
-				Ask:        tick.Ask,
-			})
-			
-		case "halt":
-			var halt struct {
-				Symbol string `json:"symbol"`
-				Halted bool   `json:"halted"`
-			}
-			if err := json.Unmarshal(event.Payload, &halt); err != nil {
-				log.Printf("Failed to parse halt event %s: %v", event.ID, err)
-				continue
-			}
-			hf.Halts = append(hf.Halts, struct {
-				Symbol string `json:"symbol"`
-				Halted bool   `json:"halted"`
-			}{
-				Symbol: halt.Symbol,
-				Halted: halt.Halted,
-			})
-			
-		case "earnings":
-			var earning struct {
-				Symbol string `json:"symbol"`
-				Start  string `json:"start_utc"`
-				End    string `json:"end_utc"`
-				Type   string `json:"type"`
-			}
-			if err := json.Unmarshal(event.Payload, &earning); err != nil {
-				log.Printf("Failed to parse earnings event %s: %v", event.ID, err)
-				continue
-			}
-			ef.Earnings = append(ef.Earnings, struct {
-				Symbol   string `json:"symbol"`
-				StartUTC string `json:"start_utc"`
-				EndUTC   string `json:"end_utc"`
-				Status   string `json:"status"`
-			}{
-				Symbol:   earning.Symbol,
-				StartUTC: earning.Start,
-				EndUTC:   earning.End,
-				Status:   earning.Type,
-			})
-		}
-	}
-	
-	return hf, nf, tf, ef, nil
-}
-
 func loadRuntimeOverrides(path string) (RuntimeOverrides, error) {
 	var ro RuntimeOverrides
 	
@@ -465,25 +268,33 @@ func waitForHealth(url string) error {
 
 func main() {
 	var cfgPath string
+	var capsPath string
 	var earningsPath string
 	var oneShot bool
 	var wireMode bool
 	var wireURL string
 	var maxEvents int
 	var durationSeconds int
+	var recordPath string
 	flag.StringVar(&cfgPath, "config", "config/config.yaml", "config path")
+	flag.StringVar(&capsPath, "caps-config", "config/caps_cooldown.yaml", "caps/cooldown config path")
 	flag.StringVar(&earningsPath, "earnings", "fixtures/earnings_calendar.json", "earnings calendar path")
 	flag.BoolVar(&oneShot, "oneshot", true, "exit after emitting decisions (set false to keep /metrics server)")
 	flag.BoolVar(&wireMode, "wire-mode", false, "enable wire polling mode")
 	flag.StringVar(&wireURL, "wire-url", "", "wire server URL (overrides config)")
 	flag.IntVar(&maxEvents, "max-events", 0, "stop after processing max events (for CI)")
 	flag.IntVar(&durationSeconds, "duration-seconds", 0, "stop after duration (for CI)")
+	flag.StringVar(&recordPath, "record-events", "", "append every wire envelope to this JSONL file for cmd/replay")
 	flag.Parse()
 
 	cfg, err := config.Load(cfgPath)
 	if err != nil {
 		log.Fatalf("load config: %v (did you copy config.example.yaml?)", err)
 	}
+	capsCfg, err := config.LoadCapsCooldown(capsPath)
+	if err != nil {
+		log.Fatalf("load caps config: %v", err)
+	}
 
 	// Apply environment variable overrides
 	if os.Getenv("GLOBAL_PAUSE") != "" {
@@ -495,6 +306,9 @@ func main() {
 	if os.Getenv("WIRE_ENABLED") != "" {
 		cfg.Wire.Enabled = os.Getenv("WIRE_ENABLED") == "true"
 	}
+	if os.Getenv("BROKER") != "" {
+		cfg.Adapters.Broker = os.Getenv("BROKER")
+	}
 	if os.Getenv("SLACK_ENABLED") != "" {
 		cfg.Slack.Enabled = os.Getenv("SLACK_ENABLED") == "true"
 	}
@@ -535,10 +349,26 @@ func main() {
 		})
 	}
 
+	// Every component reads market time from one clock
+	var clk clock.Clock = clock.Real{}
+
+	syncPolicy, err := durable.ParseSyncPolicy(cfg.Durability.Fsync)
+	if err != nil {
+		log.Fatalf("durability: %v", err)
+	}
+
 	// Initialize portfolio manager
 	var portfolioMgr *portfolio.Manager
 	if cfg.Portfolio.Enabled {
 		portfolioMgr = portfolio.NewManager(cfg.Portfolio.StateFilePath, cfg.BaseUSD)
+		portfolioMgr.SetSyncPolicy(syncPolicy)
+		portfolioMgr.SetClock(clk)
+		if err := portfolioMgr.SetLotMethod(cfg.Portfolio.LotMethod); err != nil {
+			log.Fatalf("portfolio: %v", err)
+		}
+		if err := portfolioMgr.SetMaxClosedLots(cfg.Portfolio.MaxClosedLots); err != nil {
+			log.Fatalf("portfolio: %v", err)
+		}
 		if err := portfolioMgr.Load(); err != nil {
 			log.Fatalf("load portfolio state: %v", err)
 		}
@@ -547,22 +377,31 @@ func main() {
 			"capital_base": cfg.BaseUSD,
 			"max_position_usd": cfg.Portfolio.MaxPositionSizeUSD,
 			"max_exposure_pct": cfg.Portfolio.MaxPortfolioExposurePct,
+			"lot_method": cfg.Portfolio.LotMethod,
 		})
 	}
 
 	// Initialize outbox for paper trading
 	var ob *outbox.Outbox
-	var fillSim *outbox.FillSimulator
 	if cfg.TradingMode == "paper" {
 		var err error
-		ob, err = outbox.New(cfg.Paper.OutboxPath, cfg.Paper.DedupeWindowSecs)
+		ob, err = outbox.NewWithOptions(cfg.Paper.OutboxPath, cfg.Paper.DedupeWindowSecs, outbox.Options{
+			Segments: outbox.SegmentConfig{
+				MaxBytes: int64(cfg.Paper.OutboxSegmentMB) << 20,
+				Daily:    cfg.Paper.OutboxRotateDaily,
+				Compress: cfg.Paper.OutboxCompress,
+			},
+			Sync: durable.SyncConfig{
+				Policy:        syncPolicy,
+				BatchRecords:  cfg.Durability.BatchRecords,
+				BatchInterval: time.Duration(cfg.Durability.BatchIntervalMs) * time.Millisecond,
+			},
+			Clock: clk,
+		})
 		if err != nil {
 			log.Fatalf("create outbox: %v", err)
 		}
-		fillSim = outbox.NewFillSimulator(
-			cfg.Paper.LatencyMsMin, cfg.Paper.LatencyMsMax,
-			cfg.Paper.SlippageBpsMin, cfg.Paper.SlippageBpsMax,
-		)
+		defer ob.Close()
 		observ.Log("outbox_init", map[string]any{
 			"outbox_path": cfg.Paper.OutboxPath,
 			"dedupe_window_secs": cfg.Paper.DedupeWindowSecs,
@@ -575,7 +414,7 @@ func main() {
 	var drawdownMgr *risk.DrawdownManager
 
 	if cfg.RiskControls.StopLoss.Enabled {
-		stopLossMgr = risk.NewStopLossManager(ob)
+		stopLossMgr = risk.NewStopLossManager()
 		observ.Log("stoploss_init", map[string]any{
 			"default_stop_pct": cfg.RiskControls.StopLoss.DefaultStopLossPct,
 			"cooldown_hours":   cfg.RiskControls.StopLoss.CooldownHours,
@@ -632,13 +471,280 @@ func main() {
 		"adapter_type": cfg.Quotes.Adapter,
 	})
 
-	// Load data: either from wire streaming or fixtures
-	var hf haltsFile
-	var nf newsFile
-	var tf ticksFile
-	var ef earningsFile
+	// Initialize broker (BROKER=paper|alpaca|ibkr)
+	var orderBroker broker.Broker
+	if cfg.TradingMode == "paper" {
+		orderBroker, err = broker.New(cfg.Adapters.Broker, broker.PaperConfig{
+			LatencyMsMin:   cfg.Paper.LatencyMsMin,
+			LatencyMsMax:   cfg.Paper.LatencyMsMax,
+			SlippageBpsMin: cfg.Paper.SlippageBpsMin,
+			SlippageBpsMax: cfg.Paper.SlippageBpsMax,
+			ParticipationRate: cfg.Paper.ParticipationRate,
+			PartialMinQty:     cfg.Paper.PartialMinQty,
+			PartialIntervalMs: cfg.Paper.PartialIntervalMs,
+			Clock:             clk,
+			Fees:              broker.FeeSchedule(cfg.Paper.Fees),
+		}, quotesAdapter)
+		if err != nil {
+			log.Fatalf("create broker: %v", err)
+		}
+		if pb, ok := orderBroker.(*broker.PaperBroker); ok {
+			pb.Start(context.Background())
+		}
+		defer orderBroker.Close()
+		observ.Log("broker_init", map[string]any{
+			"broker": orderBroker.Name(),
+		})
+	}
+
+	// Position caps and cooldowns from caps_cooldown.yaml
+	var capsMgr *risk.PositionCapsManager
+	var cooldownMgr *risk.CooldownManager
+	if portfolioMgr != nil {
+		capsMgr = risk.NewPositionCapsManager(portfolioMgr, quotesAdapter, risk.CapsConfig{
+			Enforce:              capsCfg.CapsCooldown.Enforce,
+			DefaultSymbolCapUSD:  capsCfg.CapsCooldown.DefaultSymbolCapUSD,
+			MaxSingleSymbolPct:   capsCfg.CapsCooldown.MaxSingleSymbolPct,
+			DailyTradeLimit:      capsCfg.CapsCooldown.DailyTradeLimit,
+			SymbolSpecificCaps:   capsCfg.CapsCooldown.SymbolSpecificCaps,
+			PortfolioCapsEnabled: capsCfg.CapsCooldown.PortfolioCapsEnabled,
+			RTHOpenHour:          capsCfg.CapsCooldown.RTHOpenHour,
+			RTHOpenMinute:        capsCfg.CapsCooldown.RTHOpenMinute,
+			PersistPath:          capsCfg.CapsCooldown.PersistPath,
+		})
+		cd := capsCfg.CapsCooldown.Cooldown
+		cooldownMgr = risk.NewCooldownManager(risk.CooldownConfig{
+			Enforce:                 cd.Enforce,
+			DefaultCooldownSec:      cd.DefaultCooldownSec,
+			GlobalCooldownSec:       cd.GlobalCooldownSec,
+			SameSideCooldownSec:     cd.SameSideCooldownSec,
+			IntentSpecificCooldowns: cd.IntentCooldowns,
+			SymbolCooldowns:         cd.SymbolCooldowns,
+			OppositeTradesAllowed:   cd.OppositeTradesAllowed,
+			VolatilityAdjustments:   cd.VolatilityAdjustments,
+			PersistPath:             cd.PersistPath,
+		})
+		capsMgr.SetClock(clk)
+		cooldownMgr.SetClock(clk)
+	}
+
+	// Mark-to-market: open positions are priced from quotes, not entry cost
+	var marker *risk.MarkToMarket
+	if cfg.MarkToMarket.Enabled && portfolioMgr != nil {
+		if cfg.MarkToMarket.PriceSource != risk.MarkMid && cfg.MarkToMarket.PriceSource != risk.MarkLast {
+			log.Fatalf("unknown mark_to_market price source %q (want mid or last)", cfg.MarkToMarket.PriceSource)
+		}
+		marker = risk.NewMarkToMarket(portfolioMgr, quotesAdapter, risk.MarkConfig{
+			IntervalSeconds: cfg.MarkToMarket.IntervalSeconds,
+			PriceSource:     cfg.MarkToMarket.PriceSource,
+			StaleAfterMs:    cfg.MarkToMarket.StaleAfterMs,
+			MaxSpreadBps:    cfg.MarkToMarket.MaxSpreadBps,
+		})
+		marker.SetClock(clk)
+	}
+
+	// The engine config carries the gate classification the risk manager shares
+	engineCfg := decision.ConfigFrom(cfg, capsCfg.Integration)
+
+	// NAV tracking, circuit breaker and the caps/cooldown soft gates
+	var riskMgr *risk.RiskManager
+	if cfg.RiskManager.Enabled && portfolioMgr != nil {
+		riskMgr = risk.NewRiskManager(portfolioMgr, quotesAdapter, risk.RiskManagerConfig{
+			NAVTracker: risk.NAVTrackerConfig{
+				UpdateIntervalSeconds:     cfg.RiskManager.UpdateIntervalSeconds,
+				QuoteStalenessThresholdMs: cfg.RiskManager.QuoteStalenessThresholdMs,
+				UseMidPrice:               true,
+				PersistPath:               cfg.RiskManager.NAVStatePath,
+			},
+			EventLogPath:          cfg.RiskManager.EventLogPath,
+			UpdateIntervalSeconds: cfg.RiskManager.UpdateIntervalSeconds,
+		})
+		riskMgr.SetClock(clk)
+		riskMgr.SetPositionControls(capsMgr, cooldownMgr)
+		if marker != nil {
+			// The NAV tracker marks the book on every update
+			riskMgr.SetMarker(marker)
+		}
+		// One gate classification for the engine and the risk manager
+		riskMgr.SetGateClasses(engineCfg.Gates.Hard, engineCfg.Gates.Soft)
+		if err := riskMgr.Start(); err != nil {
+			log.Fatalf("start risk manager: %v", err)
+		}
+		defer riskMgr.Stop()
+		observ.Log("risk_manager_init", map[string]any{
+			"event_log":       cfg.RiskManager.EventLogPath,
+			"caps_enforced":   capsCfg.CapsCooldown.Enforce,
+			"cooldown_enforced": capsCfg.CapsCooldown.Cooldown.Enforce,
+		})
+	} else if cfg.RiskManager.Enabled {
+		log.Printf("risk manager needs portfolio.enabled; running without it")
+	}
+	if marker != nil {
+		if riskMgr == nil {
+			// Without a NAV tracker, the service marks on its own schedule
+			mtmCtx, stopMarks := context.WithCancel(context.Background())
+			defer stopMarks()
+			go marker.Start(mtmCtx)
+		}
+		observ.Log("mark_to_market_init", map[string]any{
+			"price_source":    cfg.MarkToMarket.PriceSource,
+			"stale_after_ms":  cfg.MarkToMarket.StaleAfterMs,
+			"max_spread_bps":  cfg.MarkToMarket.MaxSpreadBps,
+			"via_nav_tracker": riskMgr != nil,
+		})
+	}
+
+	// Every order is rechecked against a fresh quote before it is sent
+	var guard *risk.OutboxGuard
+	if ob != nil {
+		guard = risk.NewOutboxGuardWithConfig(capsMgr, quotesAdapter, nil, risk.OutboxGuardConfig{
+			MaxPriceDriftPct:        capsCfg.RiskMitigation.MaxPriceDriftPct,
+			MaxDecisionStalenessSec: capsCfg.RiskMitigation.MaxDecisionStalenessSec,
+		})
+		guard.SetClock(clk)
+		observ.Log("outbox_guard_init", map[string]any{
+			"max_price_drift_pct":        capsCfg.RiskMitigation.MaxPriceDriftPct,
+			"max_decision_staleness_sec": capsCfg.RiskMitigation.MaxDecisionStalenessSec,
+			"caps_enforced":              capsMgr != nil && capsCfg.CapsCooldown.Enforce,
+		})
+	}
+
+	// Corporate actions: splits, renames and delistings adjust held positions
+	if cfg.CorporateActions.Enabled && portfolioMgr != nil {
+		normalizer := adapters.NewSymbolNormalizer()
+		var actions adapters.CorporateActionsConfig
+		for _, a := range cfg.CorporateActions.Actions {
+			ac := adapters.CorporateActionConfig{
+				Symbol:        a.Symbol,
+				NewSymbol:     a.NewSymbol,
+				ActionType:    a.ActionType,
+				EffectiveDate: a.EffectiveDate,
+			}
+			if a.Ratio != nil {
+				ac.Ratio = &adapters.SplitRatio{From: a.Ratio.From, To: a.Ratio.To}
+			}
+			actions.Actions = append(actions.Actions, ac)
+		}
+		if err := normalizer.LoadCorporateActionsFromConfig(actions); err != nil {
+			log.Fatalf("load corporate actions: %v", err)
+		}
+		actionProcessor := risk.NewCorporateActionProcessor(normalizer, portfolioMgr, stopLossMgr, capsMgr)
+		actionProcessor.SetClock(clk)
+		// Apply anything already in effect before the first decision
+		applied := actionProcessor.Process()
+		actionsCtx, stopActions := context.WithCancel(context.Background())
+		defer stopActions()
+		go actionProcessor.Start(actionsCtx, time.Duration(cfg.CorporateActions.IntervalSeconds)*time.Second)
+		observ.Log("corporate_actions_init", map[string]any{
+			"actions": len(cfg.CorporateActions.Actions),
+			"applied": applied,
+		})
+	}
+
+	// Short sales need a locate: only easy-to-borrow symbols are shorted
+	var borrowList *risk.BorrowList
+	if cfg.Short.Enabled {
+		borrowList, err = risk.LoadBorrowList(cfg.Short.ETBListPath)
+		if err != nil {
+			log.Fatalf("load easy-to-borrow list: %v", err)
+		}
+		observ.Log("short_init", map[string]any{
+			"entry_score": cfg.Short.EntryScore,
+			"etb_list":    cfg.Short.ETBListPath,
+			"etb_symbols": borrowList.Len(),
+		})
+	}
+
+	// Config → engine
+	engine, err := decision.NewEngine(engineCfg,
+		decision.WithPortfolio(portfolioMgr),
+		decision.WithStopLoss(stopLossMgr),
+		decision.WithSectorExposure(sectorMgr),
+		decision.WithDrawdown(drawdownMgr),
+		decision.WithBorrowList(borrowList),
+		decision.WithClock(clk),
+	)
+	if err != nil {
+		log.Fatalf("invalid engine config: %v", err)
+	}
+	if !broker.ValidOrderType(cfg.Execution.OrderType) {
+		log.Fatalf("unknown execution order type %q", cfg.Execution.OrderType)
+	}
+	riskState := decision.RiskState{
+		GlobalPause:     cfg.GlobalPause,
+		BlockPremarket:  cfg.Session.BlockPremarket,
+		BlockPostmarket: cfg.Session.BlockPostmarket,
+		MaxSpreadBps:    cfg.Liquidity.MaxSpreadBps,
+		FrozenSymbols:   frozenSymbols,
+	}
+
+	// Build advice strategies from config
+	strategies, err := strategy.DefaultRegistry().Build(cfg.Strategies)
+	if err != nil {
+		log.Fatalf("build strategies: %v", err)
+	}
+	strategyNames := make([]string, 0, len(strategies))
+	for _, s := range strategies {
+		strategyNames = append(strategyNames, s.Name()+"@"+s.Version())
+	}
+	observ.Log("strategies_init", map[string]any{
+		"strategies": strategyNames,
+	})
+
+	world := ingest.NewWorld()
+	p := &pipeline{
+		cfg:           &cfg,
+		engine:        engine,
+		clock:         clk,
+		riskState:     riskState,
+		world:         world,
+		strategies:    strategies,
+		quotesAdapter: quotesAdapter,
+		portfolioMgr:  portfolioMgr,
+		stopLossMgr:   stopLossMgr,
+		sectorMgr:     sectorMgr,
+		drawdownMgr:   drawdownMgr,
+		ob:            ob,
+		orders:        newOrderJournal(ob),
+		broker:        orderBroker,
+		guard:         guard,
+		riskMgr:       riskMgr,
+		capsMgr:       capsMgr,
+		cooldownMgr:   cooldownMgr,
+		// No breaker here: the risk manager already scales the notional by
+		// its multiplier in ApplyRiskResult
+		sizer: risk.NewSizer(risk.SizingConfig{
+			LotSize:        cfg.Sizing.LotSize,
+			MinNotionalUSD: cfg.Sizing.MinNotionalUSD,
+		}, drawdownMgr, nil),
+		fees: broker.FeeSchedule(cfg.Paper.Fees),
+		pricing: broker.PricingConfig{
+			OrderType:      cfg.Execution.OrderType,
+			LimitOffsetBps: cfg.Execution.LimitOffsetBps,
+			Protection: broker.PriceProtection{
+				AfterHours:     cfg.Execution.Protection.AfterHours,
+				SpreadBps:      cfg.Execution.Protection.SpreadBps,
+				MaxSlippageBps: cfg.Execution.Protection.MaxSlippageBps,
+			},
+		},
+		slackClient:   slackClient,
+		refreshOverrides: !oneShot,
+		lastRefresh:   time.Now(),
+		// TEST_MODE=fixtures skips quote adapter to use pure fixture data
+		useQuotes: os.Getenv("TEST_MODE") != "fixtures",
+	}
+
+	if ob != nil {
+		// Resolve orders a previous run left open before placing new ones, and
+		// before consuming broker updates so resubmitted orders are journaled first
+		p.recoverOrders()
+	}
+	if orderBroker != nil && ob != nil {
+		go p.consumeBroker()
+	}
+
 	var eventsProcessed int
-	
+
 	if cfg.Wire.Enabled {
 		// Wire mode: use configured transport (SSE or HTTP)
 		log.Printf("Wire mode enabled with transport: %s", cfg.Wire.Transport)
@@ -691,8 +797,19 @@ func main() {
 		}
 		defer client.Close()
 		
-		// Consume events from transport channel
-		allEvents := []WireEvent{}
+		// Record envelopes exactly as received so cmd/replay can reproduce the session
+		var recorder *json.Encoder
+		if recordPath != "" {
+			f, err := os.OpenFile(recordPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				log.Fatalf("open event recording: %v", err)
+			}
+			defer f.Close()
+			recorder = json.NewEncoder(f)
+		}
+		
+		// React to each event as it arrives: update world state, then
+		// re-evaluate every symbol whose inputs changed
 		startTime := time.Now()
 		
 		for {
@@ -703,17 +820,30 @@ func main() {
 					goto done
 				}
 				
-				// Convert EventEnvelope to WireEvent
-				wireEvent := WireEvent{
-					Type: envelope.Type,
-					ID: envelope.ID,
-					Payload: envelope.Payload,
+				eventsProcessed++
+				log.Printf("Received %s event %s, total: %d", envelope.Type, envelope.ID, eventsProcessed)
+				if recorder != nil {
+					if err := recorder.Encode(envelope); err != nil {
+						log.Printf("record event %s: %v", envelope.ID, err)
+					}
 				}
 				
-				allEvents = append(allEvents, wireEvent)
-				eventsProcessed++
+				ev, err := ingest.ParseEnvelope(envelope)
+				if err != nil {
+					log.Printf("Skipping wire event: %v", err)
+					observ.IncCounter("wire_events_invalid_total", map[string]string{"type": envelope.Type})
+					continue
+				}
 				
-				log.Printf("Received %s event %s, total: %d", envelope.Type, envelope.ID, eventsProcessed)
+				affected := world.Apply(ev)
+				p.maybeRefreshOverrides()
+				for _, sym := range affected {
+					p.evaluate(sym)
+				}
+				
+				if !envelope.TS.IsZero() {
+					observ.Observe("event_to_decision_latency_ms", float64(time.Since(envelope.TS).Milliseconds()), map[string]string{"type": envelope.Type})
+				}
 				
 				// Stop conditions for CI
 				if maxEvents > 0 && eventsProcessed >= maxEvents {
@@ -728,359 +858,50 @@ func main() {
 		}
 		
 		done:
-		// Process all wire events into internal structures
-		hf, nf, tf, ef, err = processWireEvents(allEvents)
-		if err != nil {
-			log.Fatalf("process wire events: %v", err)
-		}
-		
 		observ.Log("wire_ingestion_complete", map[string]any{
 			"events_processed": eventsProcessed,
 			"duration_ms": time.Since(startTime).Milliseconds(),
 		})
 	} else {
-		// Fixture mode: load static files
+		// Fixture mode: load static files into the same world state, then
+		// evaluate every symbol once
+		var hf haltsFile
+		var nf newsFile
+		var tf ticksFile
+		var ef earningsFile
 		mustRead("fixtures/halts.json", &hf)
 		mustRead("fixtures/news.json", &nf)
 		mustRead("fixtures/ticks.json", &tf)
 		mustRead(earningsPath, &ef)
 		
-		observ.Log("fixture_loading_complete", map[string]any{
-			"mode": "static_files",
-		})
-	}
-
-	// world state
-	halted := map[string]bool{}
-	for _, h := range hf.Halts {
-		halted[strings.ToUpper(h.Symbol)] = h.Halted
-	}
-
-	type key struct{ sym string }
-	features := map[key]decision.Features{}
-	
-	// Extract features from ticks file (for backward compatibility)
-	for _, t := range tf.Ticks {
-		// Calculate spread in basis points
-		spreadBps := 0.0
-		if t.Ask > 0 && t.Bid > 0 {
-			spreadBps = ((t.Ask - t.Bid) / ((t.Ask + t.Bid) / 2)) * 10000
-		}
-		features[key{strings.ToUpper(t.Symbol)}] = decision.Features{
-			Symbol:     strings.ToUpper(t.Symbol),
-			Halted:     t.Halted,
-			Last:       t.Last,
-			VWAP5m:     t.VWAP5m,
-			RelVolume:  t.RelVolume,
-			Premarket:  t.Premarket,
-			Postmarket: t.Postmarket,
-			SpreadBps:  spreadBps,
-		}
-		// Removed debug logging
-	}
-	
-	// Enrich features with real quotes from adapter
-	symbols := make([]string, 0, len(features))
-	for k := range features {
-		symbols = append(symbols, k.sym)
-	}
-	
-	// Fetch quotes from adapter if we have symbols to evaluate
-	// TEST_MODE=fixtures skips quote adapter to use pure fixture data
-	testMode := os.Getenv("TEST_MODE")
-	if len(symbols) > 0 && testMode != "fixtures" {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		
-		quotes, err := quotesAdapter.GetQuotes(ctx, symbols)
-		if err != nil {
-			log.Printf("Failed to fetch quotes: %v", err)
-			observ.IncCounter("quote_fetch_errors_total", map[string]string{
-				"error_type": "batch_fetch_failed",
-			})
-		} else {
-			// Update features with real quote data
-			for symbol, quote := range quotes {
-				if err := adapters.ValidateQuote(quote); err != nil {
-					log.Printf("Invalid quote for %s: %v", symbol, err)
-					observ.IncCounter("quote_validation_errors_total", map[string]string{
-						"symbol": symbol,
-						"error":  "validation_failed",
-					})
-					continue
-				}
-				
-				k := key{strings.ToUpper(symbol)}
-				if existingFeatures, exists := features[k]; exists {
-					// Update with real quote data
-					features[k] = decision.Features{
-						Symbol:     strings.ToUpper(symbol),
-						Halted:     quote.Halted || existingFeatures.Halted, // Respect halt from both sources
-						Last:       quote.Last,
-						VWAP5m:     existingFeatures.VWAP5m,     // Keep existing VWAP
-						RelVolume:  existingFeatures.RelVolume, // Keep existing relative volume
-						Premarket:  quote.Session == "PRE",
-						Postmarket: quote.Session == "POST",
-						SpreadBps:  quote.SpreadBps(),
-					}
-				} else {
-					// Create new features from quote
-					features[k] = decision.Features{
-						Symbol:     strings.ToUpper(symbol),
-						Halted:     quote.Halted,
-						Last:       quote.Last,
-						VWAP5m:     quote.Last,   // Use last as fallback for VWAP
-						RelVolume:  1.0,          // Default relative volume
-						Premarket:  quote.Session == "PRE",
-						Postmarket: quote.Session == "POST",
-						SpreadBps:  quote.SpreadBps(),
-					}
-				}
-				
-				observ.IncCounter("quote_feature_updated_total", map[string]string{
-					"symbol": symbol,
-					"source": quote.Source,
-				})
-				observ.Observe("quote_staleness_ms", float64(quote.StalenessMs), map[string]string{
-					"symbol": symbol,
-					"source": quote.Source,
-				})
-			}
+		for _, h := range hf.Halts {
+			world.ApplyHalt(h)
 		}
-	}
-
-	// Dedup by headline hash
-	seenHash := map[string]bool{}
-	advBySym := map[string][]decision.Advice{}
-	for _, n := range nf.News {
-		if seenHash[n.Hash] {
-			continue
-		}
-		seenHash[n.Hash] = true
-		score, conf, sw := 0.6, 0.8, 1.0
-		if n.IsPR {
-			score, conf, sw = 0.8, 0.8, 1.2
+		for _, t := range tf.Ticks {
+			world.ApplyTick(t)
 		}
-		// For corroboration test cases, adjust to make PR primary driver
-		if n.Provider == "reuters" && (n.Hash == "ed-biox-3" || n.Hash == "ed-biox-2") {
-			score, conf, sw = 0.4, 0.6, 0.8
+		for _, n := range nf.News {
+			world.ApplyNews(n)
 		}
-		
-		// Parse published time
-		publishedAt, err := time.Parse(time.RFC3339, n.PublishedAt)
-		if err != nil {
-			log.Printf("Invalid published_at_utc for %s: %v", n.ID, err)
-			publishedAt = time.Now()
-		}
-		
-		for _, sym := range n.Tickers {
-			sym = strings.ToUpper(sym)
-			advBySym[sym] = append(advBySym[sym], decision.Advice{
-				Symbol: sym, Score: score, Confidence: conf, SourceWeight: sw,
-				Provider: n.Provider, IsPR: n.IsPR, PublishedAt: publishedAt,
-			})
-			observ.Log("advice", map[string]any{
-				"symbol": sym, "score": score, "confidence": conf, "source_weight": sw,
-				"provider": n.Provider, "is_pr": n.IsPR,
-			})
-		}
-	}
-	for sym, feat := range features {
-		if feat.Last > feat.VWAP5m {
-			advBySym[sym.sym] = append(advBySym[sym.sym], decision.Advice{
-				Symbol: sym.sym, Score: 0.6, Confidence: 0.7, SourceWeight: 1.0,
-				Provider: "trend-lite", IsPR: false, PublishedAt: time.Now(),
-			})
-			observ.Log("advice", map[string]any{
-				"symbol": sym.sym, "score": 0.6, "confidence": 0.7, "source_weight": 1.0, "strategy": "trend-lite",
-			})
-		}
-	}
-
-	// Parse earnings events
-	var earningsEvents []decision.EarningsEvent
-	for _, e := range ef.Earnings {
-		startUTC, err := time.Parse(time.RFC3339, e.StartUTC)
-		if err != nil {
-			log.Printf("Invalid start_utc for %s: %v", e.Symbol, err)
-			continue
-		}
-		endUTC, err := time.Parse(time.RFC3339, e.EndUTC)
-		if err != nil {
-			log.Printf("Invalid end_utc for %s: %v", e.Symbol, err)
-			continue
-		}
-		earningsEvents = append(earningsEvents, decision.EarningsEvent{
-			Symbol:   strings.ToUpper(e.Symbol),
-			StartUTC: startUTC,
-			EndUTC:   endUTC,
-			Status:   e.Status,
-		})
-	}
-
-	// Config → engine
-	engineCfg := decision.Config{
-		Positive: cfg.Thresholds.Positive,
-		VeryPos:  cfg.Thresholds.VeryPos,
-		BaseUSD:  cfg.BaseUSD,
-		Corroboration: decision.CorroborationConfig{
-			RequirePositivePR: cfg.Corroboration.RequirePositivePR,
-			WindowSeconds:     cfg.Corroboration.WindowSeconds,
-		},
-		EarningsEmbargo: decision.EarningsEmbargoConfig{
-			Enabled:          cfg.EarningsEmbargo.Enabled,
-			BlockOnEstimated: cfg.EarningsEmbargo.BlockOnEstimated,
-			MinutesBefore:    cfg.EarningsEmbargo.MinutesBefore,
-			MinutesAfter:     cfg.EarningsEmbargo.MinutesAfter,
-		},
-		Portfolio: decision.PortfolioConfig{
-			Enabled:                     cfg.Portfolio.Enabled,
-			MaxPositionSizeUSD:          cfg.Portfolio.MaxPositionSizeUSD,
-			MaxPortfolioExposurePct:     cfg.Portfolio.MaxPortfolioExposurePct,
-			DailyTradeLimitPerSymbol:    cfg.Portfolio.DailyTradeLimitPerSymbol,
-			CooldownMinutesPerSymbol:    cfg.Portfolio.CooldownMinutesPerSymbol,
-			MaxDailyExposureIncreasePct: cfg.Portfolio.MaxDailyExposureIncreasePct,
-		},
-		RiskControls: decision.RiskControlsConfig{
-			StopLoss: risk.StopLossConfig{
-				Enabled:              cfg.RiskControls.StopLoss.Enabled,
-				DefaultStopLossPct:   cfg.RiskControls.StopLoss.DefaultStopLossPct,
-				EmergencyStopLossPct: cfg.RiskControls.StopLoss.EmergencyStopLossPct,
-				AllowAfterHours:      cfg.RiskControls.StopLoss.AllowAfterHours,
-				CooldownHours:        cfg.RiskControls.StopLoss.CooldownHours,
-			},
-			SectorLimits: risk.SectorLimitsConfig{
-				Enabled:              cfg.RiskControls.SectorLimits.Enabled,
-				MaxSectorExposurePct: cfg.RiskControls.SectorLimits.MaxSectorExposurePct,
-				SectorMap:            cfg.RiskControls.SectorLimits.SectorMap,
-			},
-			Drawdown: risk.DrawdownConfig{
-				Enabled:                    cfg.RiskControls.Drawdown.Enabled,
-				DailyWarningPct:            cfg.RiskControls.Drawdown.DailyWarningPct,
-				DailyPausePct:              cfg.RiskControls.Drawdown.DailyPausePct,
-				WeeklyWarningPct:           cfg.RiskControls.Drawdown.WeeklyWarningPct,
-				WeeklyPausePct:             cfg.RiskControls.Drawdown.WeeklyPausePct,
-				SizeMultiplierOnWarningPct: cfg.RiskControls.Drawdown.SizeMultiplierOnWarningPct,
-			},
-		},
-	}
-	risk := decision.RiskState{
-		GlobalPause:     cfg.GlobalPause,
-		BlockPremarket:  cfg.Session.BlockPremarket,
-		BlockPostmarket: cfg.Session.BlockPostmarket,
-		MaxSpreadBps:    cfg.Liquidity.MaxSpreadBps,
-		FrozenSymbols:   frozenSymbols,
-	}
-
-	// Evaluate a small set to prove the path
-	syms := []string{"AAPL", "NVDA", "BIOX"}
-	
-	// Periodic runtime override refresh for server mode
-	lastRefresh := time.Now()
-	
-	for _, sym := range syms {
-		// Refresh runtime overrides periodically (in server mode)
-		if !oneShot && time.Since(lastRefresh) > time.Duration(cfg.RuntimeOverrides.RefreshIntervalMs)*time.Millisecond {
-			if newFrozenSymbols, err := applyRuntimeOverrides(&cfg, cfg.RuntimeOverrides.FilePath); err == nil {
-				risk.GlobalPause = cfg.GlobalPause
-				risk.FrozenSymbols = newFrozenSymbols
-				// Update portfolio config in engine
-				engineCfg.Portfolio.MaxPositionSizeUSD = cfg.Portfolio.MaxPositionSizeUSD
-				engineCfg.Portfolio.MaxPortfolioExposurePct = cfg.Portfolio.MaxPortfolioExposurePct
-				engineCfg.Portfolio.DailyTradeLimitPerSymbol = cfg.Portfolio.DailyTradeLimitPerSymbol
-				engineCfg.Portfolio.CooldownMinutesPerSymbol = cfg.Portfolio.CooldownMinutesPerSymbol
-				engineCfg.Portfolio.MaxDailyExposureIncreasePct = cfg.Portfolio.MaxDailyExposureIncreasePct
-				lastRefresh = time.Now()
+		for _, e := range ef.Earnings {
+			if len(world.ApplyEarnings(e)) == 0 {
+				log.Printf("Invalid earnings window for %s", e.Symbol)
 			}
 		}
-		feat := features[key{sym}]
-		if h, ok := halted[sym]; ok {
-			feat.Halted = h
-		}
-
-		start := time.Now()
-		act := decision.Evaluate(sym, advBySym[sym], feat, risk, engineCfg, earningsEvents, portfolioMgr, stopLossMgr, sectorMgr, drawdownMgr)
-		latMs := float64(time.Since(start).Microseconds()) / 1000.0
-
-		// Record metrics
-		observ.IncCounter("decisions_total", map[string]string{
-			"symbol": sym, "intent": act.Intent,
+		
+		observ.Log("fixture_loading_complete", map[string]any{
+			"mode": "static_files",
 		})
-		observ.Observe("decision_latency_ms", latMs, map[string]string{"symbol": sym})
-
-		// Parse reason to increment gate-block counters
-		var reason struct {
-			GatesBlocked []string `json:"gates_blocked"`
-		}
-		if err := json.Unmarshal([]byte(act.ReasonJSON), &reason); err == nil {
-			for _, g := range reason.GatesBlocked {
-				observ.IncCounter("decision_gate_blocks_total", map[string]string{"gate": g, "symbol": sym})
-			}
-		}
-
-		// Update portfolio NAV for drawdown calculations
-		if drawdownMgr != nil && portfolioMgr != nil {
-			currentNAV := portfolioMgr.GetNAV()
-			drawdownMgr.UpdateNAV(currentNAV, time.Now(), engineCfg.RiskControls.Drawdown)
-		}
-
-		// Check stop-loss triggers for existing positions
-		if stopLossMgr != nil && portfolioMgr != nil && feat.Last > 0 {
-			if entryVWAP, hasPosition := portfolioMgr.GetEntryVWAP(sym); hasPosition {
-				isAfterHours := feat.Premarket || feat.Postmarket
-				if triggered, err := stopLossMgr.CheckStopLoss(sym, feat.Last, entryVWAP, engineCfg.RiskControls.StopLoss, isAfterHours, time.Now()); err != nil {
-					log.Printf("stop-loss check error for %s: %v", sym, err)
-				} else if triggered {
-					observ.Log("stop_loss_triggered", map[string]any{
-						"symbol":      sym,
-						"entry_vwap":  entryVWAP,
-						"current":     feat.Last,
-						"loss_pct":    ((entryVWAP - feat.Last) / entryVWAP) * 100,
-					})
-				}
-			}
-		}
-
-		// Handle outbox for paper trading
-		if cfg.TradingMode == "paper" && ob != nil && fillSim != nil {
-			if err := processOrderForPaper(act, feat, ob, fillSim, portfolioMgr); err != nil {
-				log.Printf("outbox error for %s: %v", sym, err)
-			}
-		}
-
-		// Send Slack alert if enabled
-		if slackClient != nil {
-			var reason struct {
-				FusedScore   float64  `json:"fused_score"`
-				GatesBlocked []string `json:"gates_blocked"`
-			}
-			if err := json.Unmarshal([]byte(act.ReasonJSON), &reason); err == nil {
-				alertReq := alerts.AlertRequest{
-					Symbol:       sym,
-					Intent:       act.Intent,
-					Score:        reason.FusedScore,
-					GatesBlocked: reason.GatesBlocked,
-					TradingMode:  cfg.TradingMode,
-					GlobalPause:  cfg.GlobalPause,
-					Timestamp:    time.Now(),
-				}
-				slackClient.SendAlert(alertReq)
-			}
+		
+		for _, sym := range world.Symbols() {
+			p.evaluate(sym)
 		}
-
-		// Emit decision as a structured log
-		observ.Log("decision", map[string]any{
-			"symbol":     sym,
-			"intent":     act.Intent,
-			"reason":     json.RawMessage(act.ReasonJSON),
-			"latency_ms": latMs,
-		})
-
-		// Also print a human line
-		fmt.Printf("%s -> %s\n", sym, act.Intent)
 	}
 
-	observ.Log("done", map[string]any{"evaluated_symbols": syms})
+	observ.Log("done", map[string]any{
+		"evaluated_symbols": world.Symbols(),
+		"decisions":         p.decisions,
+	})
 
 	if !oneShot {
 		mux := http.NewServeMux()
@@ -1148,81 +969,3 @@ func main() {
 	}
 
 }
-
-func processOrderForPaper(act decision.ProposedAction, feat decision.Features, ob *outbox.Outbox, fillSim *outbox.FillSimulator, portfolioMgr *portfolio.Manager) error {
-	// Only process BUY and REDUCE intents
-	if act.Intent != "BUY_1X" && act.Intent != "BUY_5X" && act.Intent != "REDUCE" {
-		return nil
-	}
-
-	now := time.Now().UTC()
-	
-	// Parse reason to get fused score for idempotency key
-	var reason struct {
-		FusedScore float64 `json:"fused_score"`
-	}
-	if err := json.Unmarshal([]byte(act.ReasonJSON), &reason); err != nil {
-		return fmt.Errorf("parse reason for idempotency: %w", err)
-	}
-
-	// Generate idempotency key
-	idempotencyKey := outbox.GenerateIdempotencyKey(act.Symbol, act.Intent, now, reason.FusedScore)
-
-	// Check for recent duplicate
-	hasRecent, err := ob.HasRecentOrder(idempotencyKey)
-	if err != nil {
-		return fmt.Errorf("check recent orders: %w", err)
-	}
-	if hasRecent {
-		observ.IncCounter("paper_order_dedupe_total", map[string]string{"symbol": act.Symbol})
-		return nil
-	}
-
-	// Create order
-	order := outbox.Order{
-		ID:             outbox.GenerateOrderID(act.Symbol, now),
-		Symbol:         act.Symbol,
-		Intent:         act.Intent,
-		Timestamp:      now,
-		Status:         "pending",
-		IdempotencyKey: idempotencyKey,
-	}
-
-	// Write order to outbox
-	if err := ob.WriteOrder(order); err != nil {
-		return fmt.Errorf("write order: %w", err)
-	}
-
-	observ.IncCounter("paper_orders_total", map[string]string{
-		"symbol": act.Symbol,
-		"intent": act.Intent,
-	})
-
-	// Simulate fill
-	fill, latency := fillSim.SimulateFill(order, feat.Last)
-	
-	// Schedule fill write after latency
-	go func() {
-		time.Sleep(latency)
-		if err := ob.WriteFill(fill); err != nil {
-			log.Printf("write fill for %s: %v", order.ID, err)
-			return
-		}
-		
-		// Update portfolio state on fill
-		if portfolioMgr != nil {
-			if err := portfolioMgr.UpdatePosition(fill.Symbol, int(fill.Quantity), fill.Price, fill.Timestamp); err != nil {
-				log.Printf("update portfolio position for %s: %v", fill.Symbol, err)
-			}
-		}
-		
-		observ.IncCounter("paper_fills_total", map[string]string{
-			"symbol": fill.Symbol,
-			"side":   fill.Side,
-		})
-		observ.Observe("paper_fill_latency_ms", float64(fill.LatencyMs), map[string]string{"symbol": fill.Symbol})
-		observ.Observe("paper_fill_slippage_bps", float64(fill.SlippageBps), map[string]string{"symbol": fill.Symbol})
-	}()
-
-	return nil
-}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/adapters"
+	"github.com/Rajchodisetti/trading-app/internal/alerts"
+	"github.com/Rajchodisetti/trading-app/internal/broker"
+	"github.com/Rajchodisetti/trading-app/internal/clock"
+	"github.com/Rajchodisetti/trading-app/internal/config"
+	"github.com/Rajchodisetti/trading-app/internal/decision"
+	"github.com/Rajchodisetti/trading-app/internal/ingest"
+	"github.com/Rajchodisetti/trading-app/internal/observ"
+	"github.com/Rajchodisetti/trading-app/internal/outbox"
+	"github.com/Rajchodisetti/trading-app/internal/portfolio"
+	"github.com/Rajchodisetti/trading-app/internal/risk"
+	"github.com/Rajchodisetti/trading-app/internal/strategy"
+)
+
+// pipeline holds everything needed to re-evaluate a single symbol against
+// the current world state. It is driven once per affected symbol for every
+// incoming event in wire mode, and once per symbol in fixture mode.
+type pipeline struct {
+	cfg        *config.Root
+	engine     *decision.Engine
+	clock      clock.Clock // market time for decisions, orders and risk state
+	riskState  decision.RiskState
+	world      *ingest.World
+	strategies []strategy.Strategy
+
+	quotesAdapter adapters.QuotesAdapter
+	portfolioMgr  *portfolio.Manager
+	stopLossMgr   *risk.StopLossManager
+	sectorMgr     *risk.SectorExposureManager
+	drawdownMgr   *risk.DrawdownManager
+	ob            *outbox.Outbox
+	orders        *orderJournal // serializes order records written to ob
+	broker        broker.Broker
+	guard         *risk.OutboxGuard
+	riskMgr       *risk.RiskManager
+	capsMgr       *risk.PositionCapsManager
+	cooldownMgr   *risk.CooldownManager
+	sizer         *risk.Sizer
+	pricing       broker.PricingConfig
+	fees          broker.FeeSchedule // borrow accrued on shorts; fills are charged by the broker
+	slackClient   *alerts.SlackClient
+
+	refreshOverrides bool
+	lastRefresh      time.Time
+	useQuotes        bool
+
+	decisions int
+}
+
+// maybeRefreshOverrides reloads runtime overrides when the refresh interval has elapsed (server mode only)
+func (p *pipeline) maybeRefreshOverrides() {
+	if !p.refreshOverrides || time.Since(p.lastRefresh) <= time.Duration(p.cfg.RuntimeOverrides.RefreshIntervalMs)*time.Millisecond {
+		return
+	}
+	newFrozenSymbols, err := applyRuntimeOverrides(p.cfg, p.cfg.RuntimeOverrides.FilePath)
+	if err != nil {
+		return
+	}
+	p.riskState.GlobalPause = p.cfg.GlobalPause
+	p.riskState.FrozenSymbols = newFrozenSymbols
+	// Update portfolio config in engine
+	engineCfg := p.engine.Config()
+	engineCfg.Portfolio.MaxPositionSizeUSD = p.cfg.Portfolio.MaxPositionSizeUSD
+	engineCfg.Portfolio.MaxPortfolioExposurePct = p.cfg.Portfolio.MaxPortfolioExposurePct
+	engineCfg.Portfolio.DailyTradeLimitPerSymbol = p.cfg.Portfolio.DailyTradeLimitPerSymbol
+	engineCfg.Portfolio.CooldownMinutesPerSymbol = p.cfg.Portfolio.CooldownMinutesPerSymbol
+	engineCfg.Portfolio.MaxDailyExposureIncreasePct = p.cfg.Portfolio.MaxDailyExposureIncreasePct
+	if err := p.engine.SetConfig(engineCfg); err != nil {
+		observ.Log("runtime_overrides_rejected", map[string]any{"error": err.Error()})
+		return
+	}
+	p.lastRefresh = time.Now()
+}
+
+// accrueBorrow charges borrow on open shorts for any day that has passed
+// since the last accrual
+func (p *pipeline) accrueBorrow() {
+	if p.portfolioMgr == nil {
+		return
+	}
+	charged, err := p.portfolioMgr.AccrueBorrow(p.clock.Now(), p.fees.BorrowFee)
+	if err != nil {
+		log.Printf("accrue borrow: %v", err)
+		return
+	}
+	if charged > 0 {
+		observ.Log("borrow_accrued", map[string]any{"amount_usd": charged})
+	}
+}
+
+// enrichWithQuote updates the symbol's features with a live quote from the
+// adapter; symbols no tick has covered are priced from the quote alone
+func (p *pipeline) enrichWithQuote(sym string) {
+	if !p.useQuotes || p.quotesAdapter == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	quote, err := p.quotesAdapter.GetQuote(ctx, sym)
+	if err != nil {
+		log.Printf("Failed to fetch quote for %s: %v", sym, err)
+		observ.IncCounter("quote_fetch_errors_total", map[string]string{
+			"error_type": "fetch_failed",
+		})
+		return
+	}
+	if err := adapters.ValidateQuote(quote); err != nil {
+		log.Printf("Invalid quote for %s: %v", sym, err)
+		observ.IncCounter("quote_validation_errors_total", map[string]string{
+			"symbol": sym,
+			"error":  "validation_failed",
+		})
+		return
+	}
+
+	if p.world.ApplyQuote(quote) {
+		observ.IncCounter("quote_feature_updated_total", map[string]string{
+			"symbol": sym,
+			"source": quote.Source,
+		})
+		observ.Observe("quote_staleness_ms", float64(quote.StalenessMs), map[string]string{
+			"symbol": sym,
+			"source": quote.Source,
+		})
+	}
+}
+
+// buildAdvice runs every configured strategy over the symbol's current inputs
+func (p *pipeline) buildAdvice(sym string, feat decision.Features) []decision.Advice {
+	advs := strategy.ScoreAll(p.strategies, strategy.Inputs{
+		Symbol:   sym,
+		News:     p.world.News(sym),
+		Ticks:    p.world.Ticks(sym),
+		Features: feat,
+		Now:      p.clock.Now(),
+	})
+	for _, a := range advs {
+		observ.Log("advice", map[string]any{
+			"symbol": sym, "score": a.Score, "confidence": a.Confidence, "source_weight": a.SourceWeight,
+			"provider": a.Provider, "is_pr": a.IsPR, "strategy": a.Strategy, "version": a.Version,
+		})
+	}
+	return advs
+}
+
+// evaluate runs the decision engine for one symbol against the current world state
+func (p *pipeline) evaluate(sym string) {
+	sym = strings.ToUpper(sym)
+	p.accrueBorrow()
+	p.enrichWithQuote(sym)
+
+	feat := p.world.Features(sym)
+	advs := p.buildAdvice(sym, feat)
+
+	start := time.Now()
+	decidedAt := p.clock.Now()
+	act, err := p.engine.Evaluate(context.Background(), sym, decision.Inputs{
+		Advice:   advs,
+		Features: feat,
+		Risk:     p.riskState,
+		Earnings: p.world.Earnings(),
+	})
+	if err != nil {
+		return
+	}
+	if p.riskMgr != nil && broker.SideForIntent(act.Intent) != "" {
+		act = decision.ApplyRiskResult(act, p.riskMgr.EvaluateDecision(p.riskContext(act, feat, decidedAt)))
+	}
+	latMs := float64(time.Since(start).Microseconds()) / 1000.0
+	p.decisions++
+
+	// Record metrics
+	observ.IncCounter("decisions_total", map[string]string{
+		"symbol": sym, "intent": act.Intent,
+	})
+	observ.Observe("decision_latency_ms", latMs, map[string]string{"symbol": sym})
+
+	// Parse reason to increment gate-block counters
+	var reason struct {
+		FusedScore   float64  `json:"fused_score"`
+		GatesBlocked []string `json:"gates_blocked"`
+	}
+	if err := json.Unmarshal([]byte(act.ReasonJSON), &reason); err == nil {
+		for _, g := range reason.GatesBlocked {
+			observ.IncCounter("decision_gate_blocks_total", map[string]string{"gate": g, "symbol": sym})
+		}
+	}
+
+	// Update portfolio NAV for drawdown calculations
+	if p.drawdownMgr != nil && p.portfolioMgr != nil {
+		currentNAV := p.portfolioMgr.GetNAV()
+		p.drawdownMgr.UpdateNAV(currentNAV, p.clock.Now(), p.engine.Config().RiskControls.Drawdown)
+	}
+
+	// Check stop-loss triggers for existing positions
+	stopped := false
+	if p.stopLossMgr != nil && p.portfolioMgr != nil && feat.Last > 0 {
+		stopCfg := p.engine.Config().RiskControls.StopLoss
+		if entryVWAP, hasPosition := p.portfolioMgr.GetStopEntry(sym, stopCfg.EntryReference); hasPosition {
+			pos, _ := p.portfolioMgr.GetPosition(sym)
+			short := pos.Quantity < 0
+			isAfterHours := feat.Premarket || feat.Postmarket
+			if triggered, err := p.stopLossMgr.CheckStopLoss(sym, feat.Last, entryVWAP, short, stopCfg, isAfterHours, p.clock.Now()); err != nil {
+				log.Printf("stop-loss check error for %s: %v", sym, err)
+			} else if triggered {
+				stopped = true
+				lossPct := ((entryVWAP - feat.Last) / entryVWAP) * 100
+				if short {
+					lossPct = -lossPct
+				}
+				observ.Log("stop_loss_triggered", map[string]any{
+					"symbol":     sym,
+					"entry_vwap": entryVWAP,
+					"current":    feat.Last,
+					"loss_pct":   lossPct,
+					"short":      short,
+				})
+				// Close the position through the same sizing, guard and broker path
+				if p.cfg.TradingMode == "paper" && p.ob != nil && p.broker != nil {
+					if err := p.submitOrder(decision.StopLossAction(sym, pos.Quantity, feat.Last), feat, p.clock.Now()); err != nil {
+						log.Printf("stop order error for %s: %v", sym, err)
+					} else {
+						observ.IncCounter("stop_orders_sent_total", map[string]string{"symbol": sym})
+					}
+				}
+			}
+		}
+	}
+
+	// Route orders to the broker for paper trading; a stop-loss exit
+	// replaces the decision's own order this time
+	if p.cfg.TradingMode == "paper" && p.ob != nil && p.broker != nil && !stopped {
+		if err := p.submitOrder(act, feat, decidedAt); err != nil {
+			log.Printf("order error for %s: %v", sym, err)
+		}
+	}
+
+	// Send Slack alert if enabled
+	if p.slackClient != nil {
+		p.slackClient.SendAlert(alerts.AlertRequest{
+			Symbol:       sym,
+			Intent:       act.Intent,
+			Score:        reason.FusedScore,
+			GatesBlocked: reason.GatesBlocked,
+			TradingMode:  p.cfg.TradingMode,
+			GlobalPause:  p.cfg.GlobalPause,
+			Timestamp:    time.Now(),
+		})
+	}
+
+	// Emit decision as a structured log
+	observ.Log("decision", map[string]any{
+		"symbol":     sym,
+		"intent":     act.Intent,
+		"reason":     json.RawMessage(act.ReasonJSON),
+		"latency_ms": latMs,
+	})
+
+	// Also print a human line
+	fmt.Printf("%s -> %s\n", sym, act.Intent)
+}
+
+// riskContext describes an actionable decision to the risk manager. The
+// quantity is estimated from the notional; the order is sized later.
+func (p *pipeline) riskContext(act decision.ProposedAction, feat decision.Features, decidedAt time.Time) risk.DecisionContext {
+	var reason struct {
+		FusedScore float64 `json:"fused_score"`
+	}
+	_ = json.Unmarshal([]byte(act.ReasonJSON), &reason)
+
+	qty := 0
+	if feat.Last > 0 {
+		qty = int(act.ScaledNotional / feat.Last)
+	}
+	return risk.DecisionContext{
+		Symbol:   act.Symbol,
+		Intent:   act.Intent,
+		Quantity: qty,
+		Price:    feat.Last,
+		Strategy: "fusion",
+		Score:    reason.FusedScore,
+		Features: map[string]interface{}{
+			"spread_bps": feat.SpreadBps,
+			"rel_volume": feat.RelVolume,
+			"vwap_5m":    feat.VWAP5m,
+		},
+		CorrelationID: fmt.Sprintf("%s_%d", act.Symbol, decidedAt.UnixNano()),
+		Timestamp:     decidedAt,
+	}
+}
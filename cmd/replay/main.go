@@ -1,125 +1,148 @@
 package main
 
 import (
-	"encoding/json"
-	"fmt"
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"io"
 	"log"
 	"os"
-	"strings"
 
-	"github.com/Rajchodisetti/trading-app/internal/decision"
+	"github.com/Rajchodisetti/trading-app/internal/backtest"
+	"github.com/Rajchodisetti/trading-app/internal/config"
+	"github.com/Rajchodisetti/trading-app/internal/observ"
+	"github.com/Rajchodisetti/trading-app/internal/replay"
+	"github.com/Rajchodisetti/trading-app/internal/transport"
 )
 
-type haltsFile struct {
-	Halts []struct {
-		Symbol string `json:"symbol"`
-		Halted bool   `json:"halted"`
-	} `json:"halts"`
-}
-
-type newsFile struct {
-	News []struct {
-		ID       string   `json:"id"`
-		Tickers  []string `json:"tickers"`
-		IsPR     bool     `json:"is_press_release"`
-		HeadHash string   `json:"headline_hash"`
-		Provider string   `json:"provider"`
-	} `json:"news"`
-}
-
-type ticksFile struct {
-	Ticks []struct {
-		Symbol    string  `json:"symbol"`
-		Last      float64 `json:"last"`
-		VWAP5m    float64 `json:"vwap_5m"`
-		RelVolume float64 `json:"rel_volume"`
-		Halted    bool    `json:"halted"`
-	} `json:"ticks"`
-}
-
-func mustRead(path string, v any) {
-	b, err := os.ReadFile(path)
+func writeReport(path string, write func(io.Writer) error) {
+	f, err := os.Create(path)
 	if err != nil {
-		log.Fatalf("read %s: %v", path, err)
+		log.Fatalf("create report: %v", err)
 	}
-	if err := json.Unmarshal(b, v); err != nil {
-		log.Fatalf("json %s: %v", path, err)
+	defer f.Close()
+	if err := write(f); err != nil {
+		log.Fatalf("write report %s: %v", path, err)
 	}
 }
 
 func main() {
 	log.SetFlags(0)
-	// Load fixtures
-	var hf haltsFile
-	mustRead("fixtures/halts.json", &hf)
-	var nf newsFile
-	mustRead("fixtures/news.json", &nf)
-	var tf ticksFile
-	mustRead("fixtures/ticks.json", &tf)
+	var cfgPath string
+	var capsPath string
+	var eventsPath string
+	var fixturesDir string
+	var earningsPath string
+	var journalPath string
+	var workDir string
+	var seed int64
+	var drainSeconds int
+	var reportPath string
+	var reportHTMLPath string
+	var capital float64
+	flag.StringVar(&cfgPath, "config", "config/config.yaml", "config path")
+	flag.StringVar(&capsPath, "caps-config", "config/caps_cooldown.yaml", "caps/cooldown config path")
+	flag.StringVar(&eventsPath, "events", "", "recorded event log (JSONL wire envelopes); empty replays the fixtures")
+	flag.StringVar(&fixturesDir, "fixtures", "fixtures", "fixture directory used when -events is empty")
+	flag.StringVar(&earningsPath, "earnings", "fixtures/earnings_calendar.json", "earnings calendar used when -events is empty")
+	flag.StringVar(&journalPath, "journal", "replay_journal.jsonl", "decision journal output path")
+	flag.StringVar(&workDir, "dir", "", "directory for the replay outbox and portfolio state (default: a temp dir removed on exit)")
+	flag.Int64Var(&seed, "seed", 1, "seed for simulated fill latency and slippage")
+	flag.IntVar(&drainSeconds, "drain-seconds", 300, "simulated seconds working orders may fill after the last event")
+	flag.StringVar(&reportPath, "report", "", "write a JSON backtest report here")
+	flag.StringVar(&reportHTMLPath, "report-html", "", "write an HTML backtest summary here")
+	flag.Float64Var(&capital, "capital", 0, "starting capital for the backtest report (default: base_usd)")
+	flag.Parse()
 
-	// Build a tiny world-state
-	halted := map[string]bool{}
-	for _, h := range hf.Halts {
-		halted[strings.ToUpper(h.Symbol)] = h.Halted
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	capsCfg, err := config.LoadCapsCooldown(capsPath)
+	if err != nil {
+		log.Fatalf("load caps config: %v", err)
 	}
 
-	type key struct{ sym string }
-	features := map[key]decision.Features{}
-	for _, t := range tf.Ticks {
-		features[key{strings.ToUpper(t.Symbol)}] = decision.Features{
-			Symbol:    strings.ToUpper(t.Symbol),
-			Halted:    t.Halted,
-			Last:      t.Last,
-			VWAP5m:    t.VWAP5m,
-			RelVolume: t.RelVolume,
-		}
+	var events []transport.EventEnvelope
+	if eventsPath != "" {
+		events, err = replay.LoadEvents(eventsPath)
+	} else {
+		events, err = replay.FixtureEvents(fixturesDir, earningsPath)
+	}
+	if err != nil {
+		log.Fatalf("load events: %v", err)
 	}
-	// Basic dedupe by headline hash
-	seenHash := map[string]bool{}
 
-	// Create advices from news + a tiny trend hint when last>VWAP5m
-	advBySym := map[string][]decision.Advice{}
-	for _, n := range nf.News {
-		if seenHash[n.HeadHash] {
-			continue
-		}
-		seenHash[n.HeadHash] = true
-		score := 0.6 // pretend positive editorial
-		conf := 0.8
-		sw := 1.0
-		if n.IsPR {
-			score = 0.4
-			conf = 0.6
-			sw = 0.4
-		}
-		for _, sym := range n.Tickers {
-			advBySym[strings.ToUpper(sym)] = append(advBySym[strings.ToUpper(sym)], decision.Advice{
-				Symbol: strings.ToUpper(sym), Score: score, Confidence: conf, SourceWeight: sw,
-			})
+	if workDir == "" {
+		workDir, err = os.MkdirTemp("", "replay-")
+		if err != nil {
+			log.Fatalf("create replay dir: %v", err)
 		}
+		defer os.RemoveAll(workDir)
 	}
-	for sym, feat := range features {
-		if feat.Last > feat.VWAP5m {
-			advBySym[sym.sym] = append(advBySym[sym.sym], decision.Advice{
-				Symbol: sym.sym, Score: 0.3, Confidence: 0.7, SourceWeight: 1.0, // trend-lite
-			})
-		}
+
+	// The journal gets its own file; stdout carries the structured logs
+	f, err := os.Create(journalPath)
+	if err != nil {
+		log.Fatalf("create journal: %v", err)
 	}
+	defer f.Close()
+	// Keep a copy of the journal for the backtest report
+	var journal bytes.Buffer
+	w := bufio.NewWriter(io.MultiWriter(f, &journal))
 
-	cfg := decision.Config{Positive: 0.35, VeryPos: 0.65, BaseUSD: 2000}
-	risk := decision.RiskState{GlobalPause: true} // rails ON for session #1
+	summary, err := replay.Run(context.Background(), replay.Options{
+		Config:       cfg,
+		Caps:         capsCfg,
+		Seed:         seed,
+		Dir:          workDir,
+		DrainSeconds: drainSeconds,
+	}, events, w)
+	if err != nil {
+		log.Fatalf("replay: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatalf("write journal: %v", err)
+	}
 
-	// Evaluate for AAPL & NVDA if present
-	for _, sym := range []string{"AAPL", "NVDA"} {
-		feat := features[key{sym}]
-		// If halts fixture says halted, reflect it
-		if h, ok := halted[sym]; ok {
-			feat.Halted = h
-		}
+	observ.Log("replay_complete", map[string]any{
+		"journal":   journalPath,
+		"events":    summary.Events,
+		"skipped":   summary.Skipped,
+		"decisions": summary.Decisions,
+		"orders":    summary.Orders,
+		"fills":     summary.Fills,
+		"nav":       summary.NAV,
+		"start_utc": summary.Start,
+		"end_utc":   summary.End,
+	})
 
-		// Create nil risk managers for replay (legacy mode)
-		act := decision.Evaluate(sym, advBySym[sym], feat, risk, cfg, []decision.EarningsEvent{}, nil, nil, nil, nil)
-		fmt.Printf("{\"symbol\":\"%s\",\"intent\":\"%s\",\"reason\":%s}\n",
-			sym, act.Intent, act.ReasonJSON)
+	if reportPath == "" && reportHTMLPath == "" {
+		return
+	}
+	entries, err := replay.ReadJournal(&journal)
+	if err != nil {
+		log.Fatalf("read journal: %v", err)
+	}
+	if capital == 0 {
+		capital = cfg.BaseUSD
+	}
+	report, err := backtest.Build(entries, capital, cfg.Portfolio.LotMethod)
+	if err != nil {
+		log.Fatalf("build report: %v", err)
+	}
+	if reportPath != "" {
+		writeReport(reportPath, func(w io.Writer) error { return backtest.WriteJSON(w, report) })
+	}
+	if reportHTMLPath != "" {
+		writeReport(reportHTMLPath, func(w io.Writer) error { return backtest.WriteHTML(w, report) })
 	}
+	observ.Log("backtest_report", map[string]any{
+		"pnl":              report.PnL,
+		"max_drawdown_pct": report.MaxDrawdownPct,
+		"sharpe":           report.Sharpe,
+		"trades":           report.Trades.Trades,
+		"hit_rate":         report.Trades.HitRate,
+	})
 }
@@ -3,6 +3,7 @@ package adapters
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -297,8 +298,11 @@ func (sn *SymbolNormalizer) GetCorporateAction(symbol string) (*CorporateAction,
 
 // isCorporateActionActive checks if a corporate action is currently active
 func (sn *SymbolNormalizer) isCorporateActionActive(action *CorporateAction) bool {
-	now := time.Now()
-	
+	return actionActiveAt(action, time.Now())
+}
+
+// actionActiveAt checks if a corporate action is in effect at now
+func actionActiveAt(action *CorporateAction, now time.Time) bool {
 	// Action is active if we're past the effective date
 	if now.Before(action.EffectiveDate) {
 		return false
@@ -401,6 +405,27 @@ func (sn *SymbolNormalizer) GetActiveCorporateActions() map[string]*CorporateAct
 	return active
 }
 
+// CorporateActionsAsOf returns copies of the corporate actions in effect at
+// now, oldest effective date first
+func (sn *SymbolNormalizer) CorporateActionsAsOf(now time.Time) []CorporateAction {
+	sn.mu.RLock()
+	defer sn.mu.RUnlock()
+	
+	var actions []CorporateAction
+	for _, action := range sn.corporateActions {
+		if actionActiveAt(action, now) {
+			actions = append(actions, *action)
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		if !actions[i].EffectiveDate.Equal(actions[j].EffectiveDate) {
+			return actions[i].EffectiveDate.Before(actions[j].EffectiveDate)
+		}
+		return actions[i].Symbol < actions[j].Symbol
+	})
+	return actions
+}
+
 // CleanupExpiredActions removes expired corporate actions
 func (sn *SymbolNormalizer) CleanupExpiredActions() int {
 	sn.mu.Lock()
@@ -43,6 +43,7 @@ type AlertRequest struct {
 	TradingMode  string    `json:"trading_mode"`
 	GlobalPause  bool      `json:"global_pause"`
 	Timestamp    time.Time `json:"timestamp"`
+	Summary      string    `json:"summary,omitempty"` // free-form details for operational alerts
 }
 
 type queuedAlert struct {
@@ -154,6 +155,8 @@ func (s *SlackClient) shouldAlert(req AlertRequest) bool {
 		return s.cfg.AlertOnBuy1x && req.Score >= 0.65 // High score threshold
 	case "REJECT":
 		return s.cfg.AlertOnRejectGates && len(req.GatesBlocked) > 0
+	case "RECONCILE":
+		return true // startup recovery found orders left open
 	default:
 		return false
 	}
@@ -365,6 +368,9 @@ func (s *SlackClient) formatMessage(req AlertRequest) SlackMessage {
 	case "REJECT":
 		emoji = "🛑"
 		color = "danger"
+	case "RECONCILE":
+		emoji = "🔁"
+		color = "warning"
 	}
 
 	text := fmt.Sprintf("%s %s Alert: %s", emoji, req.Intent, req.Symbol)
@@ -388,6 +394,10 @@ func (s *SlackClient) formatMessage(req AlertRequest) SlackMessage {
 		{Title: "Time", Value: req.Timestamp.Format("15:04:05 MST"), Short: true},
 	}
 
+	if req.Summary != "" {
+		fields = append(fields, SlackField{Title: "Details", Value: req.Summary})
+	}
+
 	// Add trading mode if not standard
 	if req.TradingMode != "paper" || req.GlobalPause {
 		mode := req.TradingMode
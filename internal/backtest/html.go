@@ -0,0 +1,89 @@
+package backtest
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+)
+
+// WriteJSON writes the report as indented JSON
+func WriteJSON(w io.Writer, r Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteHTML writes a self-contained HTML summary of the report
+func WriteHTML(w io.Writer, r Report) error {
+	return reportTemplate.Execute(w, r)
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"pct": func(f float64) float64 { return f * 100 },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Backtest {{.Start.Format "2006-01-02"}} to {{.End.Format "2006-01-02"}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+</style>
+</head>
+<body>
+<h1>Backtest {{.Start.Format "2006-01-02 15:04"}} to {{.End.Format "2006-01-02 15:04"}} UTC</h1>
+
+<h2>Summary</h2>
+<table>
+<tr><td>Starting capital</td><td>{{printf "%.2f" .StartingCapital}}</td></tr>
+<tr><td>Ending equity</td><td>{{printf "%.2f" .EndingEquity}}</td></tr>
+<tr><td>P&amp;L</td><td>{{printf "%.2f" .PnL}} ({{printf "%.2f" .ReturnPct}}%)</td></tr>
+<tr><td>Commissions and fees</td><td>{{printf "%.2f" .Costs}}</td></tr>
+<tr><td>Borrow</td><td>{{printf "%.2f" .Borrow}}</td></tr>
+<tr><td>Max drawdown</td><td>{{printf "%.2f" .MaxDrawdownUSD}} ({{printf "%.2f" .MaxDrawdownPct}}%)</td></tr>
+<tr><td>Sharpe (daily, annualized)</td><td>{{printf "%.2f" .Sharpe}}</td></tr>
+<tr><td>Trades</td><td>{{.Trades.Trades}}</td></tr>
+<tr><td>Hit rate</td><td>{{printf "%.1f" (pct .Trades.HitRate)}}%</td></tr>
+<tr><td>Avg holding (min)</td><td>{{printf "%.1f" .Trades.AvgHoldingMinutes}}</td></tr>
+</table>
+
+<h2>Daily P&amp;L</h2>
+<table>
+<tr><th>Date</th><th>Equity</th><th>P&amp;L</th><th>Return %</th><th>Drawdown %</th></tr>
+{{range .Daily}}<tr><td>{{.Date}}</td><td>{{printf "%.2f" .Equity}}</td><td>{{printf "%.2f" .PnL}}</td><td>{{printf "%.2f" .ReturnPct}}</td><td>{{printf "%.2f" .DrawdownPct}}</td></tr>
+{{end}}</table>
+
+<h2>By intent</h2>
+<table>
+<tr><th>Intent</th><th>Trades</th><th>Hit rate %</th><th>P&amp;L</th><th>Avg holding (min)</th></tr>
+{{range $k, $v := .ByIntent}}<tr><td>{{$k}}</td><td>{{$v.Trades}}</td><td>{{printf "%.1f" (pct $v.HitRate)}}</td><td>{{printf "%.2f" $v.PnL}}</td><td>{{printf "%.1f" $v.AvgHoldingMinutes}}</td></tr>
+{{end}}</table>
+
+<h2>By source</h2>
+<table>
+<tr><th>Source</th><th>Trades</th><th>Hit rate %</th><th>P&amp;L</th><th>Avg holding (min)</th></tr>
+{{range $k, $v := .BySource}}<tr><td>{{$k}}</td><td>{{$v.Trades}}</td><td>{{printf "%.1f" (pct $v.HitRate)}}</td><td>{{printf "%.2f" $v.PnL}}</td><td>{{printf "%.1f" $v.AvgHoldingMinutes}}</td></tr>
+{{end}}</table>
+
+<h2>Decisions</h2>
+<table>
+<tr><th>Intent</th><th>Count</th></tr>
+{{range $k, $v := .Decisions}}<tr><td>{{$k}}</td><td>{{$v}}</td></tr>
+{{end}}</table>
+
+<h2>Gate blocks</h2>
+<table>
+<tr><th>Gate</th><th>Decisions blocked</th></tr>
+{{range $k, $v := .GateBlocks}}<tr><td>{{$k}}</td><td>{{$v}}</td></tr>
+{{end}}</table>
+
+<h2>Round trips</h2>
+<table>
+<tr><th>Symbol</th><th>Intent</th><th>Source</th><th>Qty</th><th>Entry</th><th>Exit</th><th>Opened</th><th>Closed</th><th>P&amp;L</th></tr>
+{{range .RoundTrips}}<tr><td>{{.Symbol}}{{if .Short}} (short){{end}}</td><td>{{.Intent}}</td><td>{{.Source}}</td><td>{{.Qty}}</td><td>{{printf "%.2f" .Entry}}</td><td>{{printf "%.2f" .Exit}}{{if .Open}} (open){{end}}</td><td>{{.Opened.Format "2006-01-02 15:04:05"}}</td><td>{{.Closed.Format "2006-01-02 15:04:05"}}</td><td>{{printf "%.2f" .PnL}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,319 @@
+// Package backtest turns a replay journal into performance figures: daily
+// P&L and drawdown, a Sharpe-like ratio, hit rates by intent and by news
+// source, holding times, and how often each gate blocked a decision.
+package backtest
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/outbox"
+	"github.com/Rajchodisetti/trading-app/internal/portfolio"
+	"github.com/Rajchodisetti/trading-app/internal/replay"
+)
+
+// tradingDays annualizes the daily Sharpe-like ratio
+const tradingDays = 252
+
+// Report is the result of one backtest
+type Report struct {
+	Start           time.Time             `json:"start_utc"`
+	End             time.Time             `json:"end_utc"`
+	StartingCapital float64               `json:"starting_capital"`
+	EndingEquity    float64               `json:"ending_equity"`
+	PnL             float64               `json:"pnl"`
+	Costs           float64               `json:"costs"`  // commissions and fees charged on fills
+	Borrow          float64               `json:"borrow"` // stock borrow charged on shorts
+	ReturnPct       float64               `json:"return_pct"`
+	MaxDrawdownUSD  float64               `json:"max_drawdown_usd"`
+	MaxDrawdownPct  float64               `json:"max_drawdown_pct"`
+	Sharpe          float64               `json:"sharpe"` // annualized mean over stdev of daily returns
+	Trades          TradeStats            `json:"trades"`
+	ByIntent        map[string]TradeStats `json:"by_intent"`
+	BySource        map[string]TradeStats `json:"by_source"`
+	Decisions       map[string]int        `json:"decisions"`   // intent -> count
+	GateBlocks      map[string]int        `json:"gate_blocks"` // gate -> decisions it blocked
+	Daily           []Day                 `json:"daily"`
+	RoundTrips      []Trade               `json:"round_trips"`
+}
+
+// Day is the equity and P&L at the last journal entry of a UTC day
+type Day struct {
+	Date        string  `json:"date"`
+	Equity      float64 `json:"equity"`
+	PnL         float64 `json:"pnl"`
+	ReturnPct   float64 `json:"return_pct"`
+	DrawdownPct float64 `json:"drawdown_pct"`
+}
+
+// Trade is one lot from its opening fill to the fill that closed it. Lots
+// still open at the end are marked at the last known price. Qty is always
+// positive; Short marks a lot opened by a short sale.
+type Trade struct {
+	Symbol string    `json:"symbol"`
+	Intent string    `json:"intent"`
+	Source string    `json:"source"` // pr, editorial, pr+editorial or none
+	Qty    int       `json:"qty"`
+	Entry  float64   `json:"entry"`
+	Exit   float64   `json:"exit"`
+	Opened time.Time `json:"opened_utc"`
+	Closed time.Time `json:"closed_utc"`
+	PnL    float64   `json:"pnl"`
+	Short  bool      `json:"short,omitempty"`
+	Open   bool      `json:"open,omitempty"`
+}
+
+// TradeStats aggregates trades
+type TradeStats struct {
+	Trades            int     `json:"trades"`
+	Wins              int     `json:"wins"`
+	HitRate           float64 `json:"hit_rate"`
+	PnL               float64 `json:"pnl"`
+	AvgHoldingMinutes float64 `json:"avg_holding_minutes"`
+
+	holding time.Duration
+}
+
+func (s *TradeStats) add(t Trade) {
+	s.Trades++
+	if t.PnL > 0 {
+		s.Wins++
+	}
+	s.PnL += t.PnL
+	s.holding += t.Closed.Sub(t.Opened)
+	s.HitRate = float64(s.Wins) / float64(s.Trades)
+	s.AvgHoldingMinutes = s.holding.Minutes() / float64(s.Trades)
+}
+
+// attribution is what the decision behind an order was
+type attribution struct {
+	intent string
+	source string
+}
+
+// Build replays the journal's fills against a cash ledger starting at
+// capital, net of each fill's commission and fees and of the borrow charged
+// on shorts, so ending equity matches the run's NAV. Equity is cash plus
+// positions marked at the last price a decision saw. Closes match lots by
+// lotMethod, the way the portfolio matched them. Round-trip P&L is gross of
+// costs.
+func Build(entries []replay.Entry, capital float64, lotMethod string) (Report, error) {
+	lotMethod, err := portfolio.ValidateLotMethod(lotMethod)
+	if err != nil {
+		return Report{}, err
+	}
+	r := Report{
+		StartingCapital: capital,
+		ByIntent:        map[string]TradeStats{},
+		BySource:        map[string]TradeStats{},
+		Decisions:       map[string]int{},
+		GateBlocks:      map[string]int{},
+	}
+
+	cash := capital
+	positions := map[string]int{}
+	marks := map[string]float64{}
+	lots := map[string][]portfolio.Lot{}
+	lastDecision := map[string]attribution{}
+	orders := map[string]attribution{}
+
+	equity := func() float64 {
+		e := cash
+		for sym, qty := range positions {
+			e += float64(qty) * marks[sym]
+		}
+		return e
+	}
+
+	peak := capital
+	prevEquity := capital
+	var day string
+	var returns []float64
+	closeDay := func() {
+		if day == "" {
+			return
+		}
+		eq := equity()
+		d := Day{Date: day, Equity: eq, PnL: eq - prevEquity}
+		if prevEquity != 0 {
+			d.ReturnPct = d.PnL / prevEquity * 100
+			returns = append(returns, d.PnL/prevEquity)
+		}
+		if peak > 0 {
+			d.DrawdownPct = (peak - eq) / peak * 100
+		}
+		r.Daily = append(r.Daily, d)
+		prevEquity = eq
+	}
+
+	for _, e := range entries {
+		if r.Start.IsZero() {
+			r.Start = e.TS
+		}
+		r.End = e.TS
+		if date := e.TS.UTC().Format("2006-01-02"); date != day {
+			closeDay()
+			day = date
+		}
+
+		switch e.Type {
+		case "decision":
+			r.Decisions[e.Intent]++
+			var reason struct {
+				GatesBlocked []string `json:"gates_blocked"`
+			}
+			if json.Unmarshal(e.Reason, &reason) == nil {
+				for _, g := range reason.GatesBlocked {
+					r.GateBlocks[g]++
+				}
+			}
+			if e.Price > 0 {
+				marks[e.Symbol] = e.Price
+			}
+			lastDecision[e.Symbol] = attribution{intent: e.Intent, source: sourceLabel(e.Sources)}
+		case "order":
+			if e.Order == nil {
+				continue
+			}
+			if _, ok := orders[e.Order.ID]; !ok {
+				a := lastDecision[e.Symbol]
+				a.intent = e.Order.Intent
+				orders[e.Order.ID] = a
+			}
+		case "borrow":
+			cash -= e.Amount
+			r.Borrow += e.Amount
+		case "fill":
+			if e.Fill == nil {
+				continue
+			}
+			f := *e.Fill
+			qty := int(f.Quantity)
+			if _, ok := marks[f.Symbol]; !ok {
+				marks[f.Symbol] = f.Price
+			}
+			if f.Side == "SELL" {
+				qty = -qty
+			}
+			cash -= float64(qty)*f.Price + f.Commission + f.Fees
+			r.Costs += f.Commission + f.Fees
+			positions[f.Symbol] += qty
+			lots[f.Symbol] = r.fillLots(lots[f.Symbol], f, qty, lotMethod, orders)
+		}
+
+		eq := equity()
+		if eq > peak {
+			peak = eq
+		}
+		if dd := peak - eq; dd > r.MaxDrawdownUSD {
+			r.MaxDrawdownUSD = dd
+			if peak > 0 {
+				r.MaxDrawdownPct = dd / peak * 100
+			}
+		}
+	}
+	closeDay()
+
+	// Mark what is still open so short runs still show a hit rate
+	symbols := make([]string, 0, len(lots))
+	for sym := range lots {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+	for _, sym := range symbols {
+		for _, l := range lots[sym] {
+			a := orders[l.OrderID]
+			r.record(Trade{
+				Symbol: sym, Intent: a.intent, Source: a.source, Qty: max(l.Quantity, -l.Quantity),
+				Entry: l.Price, Exit: marks[sym], Opened: l.OpenedAt, Closed: r.End,
+				PnL: float64(l.Quantity) * (marks[sym] - l.Price), Short: l.Quantity < 0, Open: true,
+			})
+		}
+	}
+
+	r.EndingEquity = equity()
+	r.PnL = r.EndingEquity - capital
+	if capital != 0 {
+		r.ReturnPct = r.PnL / capital * 100
+	}
+	r.Sharpe = sharpe(returns)
+	return r, nil
+}
+
+// fillLots applies a signed fill: it closes lots on the other side in
+// method order, recording each round trip against the decision that opened
+// the lot, and opens a lot with whatever is left
+func (r *Report) fillLots(open []portfolio.Lot, f outbox.Fill, qty int, method string, orders map[string]attribution) []portfolio.Lot {
+	if len(open) > 0 && (open[0].Quantity > 0) != (qty > 0) {
+		var closed []portfolio.ClosedLot
+		open, closed, qty = portfolio.CloseLots(f.Symbol, open, qty, f.Price, method, f.Timestamp, f.OrderID)
+		for _, c := range closed {
+			a := orders[c.OpenOrderID]
+			r.record(Trade{
+				Symbol: c.Symbol, Intent: a.intent, Source: a.source, Qty: max(c.Quantity, -c.Quantity),
+				Entry: c.EntryPrice, Exit: c.ExitPrice, Opened: c.OpenedAt, Closed: c.ClosedAt,
+				PnL: c.RealizedPnL, Short: c.Quantity < 0,
+			})
+		}
+	}
+	if qty != 0 {
+		open = append(open, portfolio.Lot{Quantity: qty, Price: f.Price, OpenedAt: f.Timestamp, OrderID: f.OrderID})
+	}
+	return open
+}
+
+func (r *Report) record(t Trade) {
+	r.RoundTrips = append(r.RoundTrips, t)
+	r.Trades.add(t)
+	byIntent := r.ByIntent[t.Intent]
+	byIntent.add(t)
+	r.ByIntent[t.Intent] = byIntent
+	bySource := r.BySource[t.Source]
+	bySource.add(t)
+	r.BySource[t.Source] = bySource
+}
+
+// sourceLabel collapses a decision's news sources into one attribution bucket
+func sourceLabel(sources []string) string {
+	var pr, editorial bool
+	for _, s := range sources {
+		switch s {
+		case "pr":
+			pr = true
+		case "editorial":
+			editorial = true
+		}
+	}
+	switch {
+	case pr && editorial:
+		return "pr+editorial"
+	case pr:
+		return "pr"
+	case editorial:
+		return "editorial"
+	}
+	return "none"
+}
+
+// sharpe is the annualized mean over sample stdev of daily returns, 0 when undefined
+func sharpe(returns []float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, x := range returns {
+		mean += x
+	}
+	mean /= float64(len(returns))
+	var variance float64
+	for _, x := range returns {
+		variance += (x - mean) * (x - mean)
+	}
+	std := math.Sqrt(variance / float64(len(returns)-1))
+	if std == 0 {
+		return 0
+	}
+	return mean / std * math.Sqrt(tradingDays)
+}
@@ -0,0 +1,194 @@
+package backtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/broker"
+	"github.com/Rajchodisetti/trading-app/internal/outbox"
+	"github.com/Rajchodisetti/trading-app/internal/portfolio"
+	"github.com/Rajchodisetti/trading-app/internal/replay"
+)
+
+var day1 = time.Date(2025, 8, 25, 14, 0, 0, 0, time.UTC)
+
+func decision(at time.Time, sym, intent string, price float64, sources []string, blocked ...string) replay.Entry {
+	reason, _ := json.Marshal(map[string]any{"gates_blocked": blocked})
+	return replay.Entry{TS: at, Type: "decision", Symbol: sym, Intent: intent, Price: price, Sources: sources, Reason: reason}
+}
+
+func order(at time.Time, id, sym, intent string) replay.Entry {
+	return replay.Entry{TS: at, Type: "order", Symbol: sym, Intent: intent, Order: &broker.OrderState{ID: id, Symbol: sym, Intent: intent}}
+}
+
+func fill(at time.Time, id, sym, side string, qty, price float64) replay.Entry {
+	return replay.Entry{TS: at, Type: "fill", Symbol: sym, Fill: &outbox.Fill{OrderID: id, Symbol: sym, Side: side, Quantity: qty, Price: price, Timestamp: at}}
+}
+
+func build(t *testing.T, entries []replay.Entry, lotMethod string) Report {
+	t.Helper()
+	r, err := Build(entries, 10000, lotMethod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func journal() []replay.Entry {
+	day2 := day1.Add(24 * time.Hour)
+	return []replay.Entry{
+		decision(day1, "AAPL", "BUY_1X", 100, []string{"editorial"}),
+		order(day1, "o1", "AAPL", "BUY_1X"),
+		fill(day1.Add(time.Second), "o1", "AAPL", "BUY", 10, 100),
+		decision(day1.Add(time.Minute), "NVDA", "BUY_5X", 50, []string{"pr"}),
+		order(day1.Add(time.Minute), "o2", "NVDA", "BUY_5X"),
+		fill(day1.Add(time.Minute+time.Second), "o2", "NVDA", "BUY", 20, 50),
+		decision(day1.Add(time.Hour), "NVDA", "HOLD", 45, nil, "corroboration"),
+		decision(day2, "AAPL", "EXIT", 110, nil),
+		order(day2, "o3", "AAPL", "EXIT"),
+		fill(day2.Add(time.Second), "o3", "AAPL", "SELL", 10, 110),
+		decision(day2.Add(time.Hour), "NVDA", "REJECT", 40, nil, "halt", "corroboration"),
+	}
+}
+
+func TestBuild_PnLDrawdownAndAttribution(t *testing.T) {
+	r := build(t, journal(), portfolio.LotFIFO)
+
+	// AAPL round trip +100; NVDA still open, marked at 40: -200
+	if math.Abs(r.PnL-(-100)) > 1e-9 || math.Abs(r.EndingEquity-9900) > 1e-9 {
+		t.Fatalf("want pnl -100 and equity 9900, got %+v", r)
+	}
+	if len(r.Daily) != 2 || r.Daily[0].Date != "2025-08-25" || math.Abs(r.Daily[0].PnL-(-100)) > 1e-9 {
+		t.Fatalf("unexpected daily P&L: %+v", r.Daily)
+	}
+	if math.Abs(r.Daily[1].PnL) > 1e-9 {
+		t.Fatalf("want a flat second day, got %+v", r.Daily[1])
+	}
+	// NVDA's drop to 45 is offset by AAPL's gain before it falls to 40
+	if math.Abs(r.MaxDrawdownUSD-100) > 1e-9 || math.Abs(r.MaxDrawdownPct-1) > 1e-9 {
+		t.Fatalf("want max drawdown 100 (1%%), got %v (%v%%)", r.MaxDrawdownUSD, r.MaxDrawdownPct)
+	}
+
+	if r.Trades.Trades != 2 || r.Trades.Wins != 1 || r.Trades.HitRate != 0.5 {
+		t.Fatalf("unexpected trade stats: %+v", r.Trades)
+	}
+	if s := r.ByIntent["BUY_1X"]; s.Trades != 1 || s.Wins != 1 || math.Abs(s.AvgHoldingMinutes-24*60) > 1e-9 {
+		t.Fatalf("unexpected BUY_1X stats: %+v", s)
+	}
+	if s := r.BySource["pr"]; s.Trades != 1 || s.Wins != 0 || math.Abs(s.PnL-(-200)) > 1e-9 {
+		t.Fatalf("unexpected pr stats: %+v", s)
+	}
+	if r.BySource["editorial"].Wins != 1 {
+		t.Fatalf("want the editorial trade to win: %+v", r.BySource)
+	}
+	if r.GateBlocks["corroboration"] != 2 || r.GateBlocks["halt"] != 1 || r.Decisions["HOLD"] != 1 {
+		t.Fatalf("unexpected gate attribution: %v %v", r.GateBlocks, r.Decisions)
+	}
+}
+
+func TestBuild_CostsReduceEquity(t *testing.T) {
+	entries := journal()
+	for i := range entries {
+		if entries[i].Fill != nil {
+			f := *entries[i].Fill
+			f.Commission, f.Fees = 1, 0.25
+			entries[i].Fill = &f
+		}
+	}
+	r := build(t, entries, portfolio.LotFIFO)
+	if math.Abs(r.Costs-3.75) > 1e-9 || math.Abs(r.PnL-(-103.75)) > 1e-9 {
+		t.Fatalf("want 3.75 of costs off a -100 P&L, got costs %v pnl %v", r.Costs, r.PnL)
+	}
+	if r.Trades.Wins != 1 {
+		t.Fatalf("round trips stay gross of costs: %+v", r.Trades)
+	}
+}
+
+func TestBuild_MatchesLotsByMethod(t *testing.T) {
+	entries := []replay.Entry{
+		order(day1, "o1", "AAPL", "BUY_1X"),
+		fill(day1.Add(time.Second), "o1", "AAPL", "BUY", 10, 100),
+		order(day1.Add(time.Minute), "o2", "AAPL", "BUY_5X"),
+		fill(day1.Add(time.Minute+time.Second), "o2", "AAPL", "BUY", 10, 120),
+		order(day1.Add(time.Hour), "o3", "AAPL", "REDUCE"),
+		fill(day1.Add(time.Hour+time.Second), "o3", "AAPL", "SELL", 10, 130),
+	}
+	tests := []struct {
+		method     string
+		wantIntent string
+		wantPnL    float64
+	}{
+		{portfolio.LotFIFO, "BUY_1X", 300},
+		{portfolio.LotLIFO, "BUY_5X", 100},
+		{portfolio.LotHighestCost, "BUY_5X", 100},
+	}
+	for _, tt := range tests {
+		r := build(t, entries, tt.method)
+		if rt := r.RoundTrips[0]; rt.Open || rt.Intent != tt.wantIntent || math.Abs(rt.PnL-tt.wantPnL) > 1e-9 {
+			t.Fatalf("%s: want the %s lot closed for %v, got %+v", tt.method, tt.wantIntent, tt.wantPnL, rt)
+		}
+	}
+	if _, err := Build(entries, 10000, "average"); err == nil {
+		t.Fatal("want an unknown lot method rejected")
+	}
+}
+
+func TestBuild_BorrowReducesEquity(t *testing.T) {
+	day2 := day1.Add(24 * time.Hour)
+	entries := []replay.Entry{
+		decision(day1, "NVDA", "SELL_SHORT", 50, nil),
+		order(day1, "o1", "NVDA", "SELL_SHORT"),
+		fill(day1.Add(time.Second), "o1", "NVDA", "SELL", 20, 50),
+		{TS: day2, Type: "borrow", Amount: 0.5},
+		decision(day2, "NVDA", "HOLD", 50, nil),
+	}
+	r := build(t, entries, portfolio.LotFIFO)
+	if math.Abs(r.Borrow-0.5) > 1e-9 || math.Abs(r.EndingEquity-9999.5) > 1e-9 {
+		t.Fatalf("want 0.5 of borrow off a flat short, got borrow %v equity %v", r.Borrow, r.EndingEquity)
+	}
+	if len(r.Daily) != 2 || math.Abs(r.Daily[1].PnL-(-0.5)) > 1e-9 {
+		t.Fatalf("want borrow booked on the day it was charged: %+v", r.Daily)
+	}
+}
+
+func TestSharpe(t *testing.T) {
+	if sharpe([]float64{0.01}) != 0 || sharpe([]float64{0.01, 0.01}) != 0 {
+		t.Fatal("want 0 when the ratio is undefined")
+	}
+	if got := sharpe([]float64{0.01, 0.03}); math.Abs(got-2/math.Sqrt2*math.Sqrt(tradingDays)) > 1e-9 {
+		t.Fatalf("unexpected sharpe %v", got)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, build(t, journal(), portfolio.LotFIFO)); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"2025-08-25", "BUY_5X", "editorial", "corroboration", "(open)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("html missing %q", want)
+		}
+	}
+}
+
+func TestBuild_ShortRoundTrip(t *testing.T) {
+	r := build(t, []replay.Entry{
+		decision(day1, "TSLA", "SELL_SHORT", 200, []string{"pr"}),
+		order(day1, "s1", "TSLA", "SELL_SHORT"),
+		fill(day1.Add(time.Second), "s1", "TSLA", "SELL", 5, 200),
+		decision(day1.Add(time.Hour), "TSLA", "COVER", 190, nil),
+		order(day1.Add(time.Hour), "s2", "TSLA", "COVER"),
+		fill(day1.Add(time.Hour+time.Second), "s2", "TSLA", "BUY", 5, 190),
+	}, portfolio.LotFIFO)
+	if math.Abs(r.PnL-50) > 1e-9 || r.Trades.Trades != 1 || r.Trades.Wins != 1 {
+		t.Fatalf("want a winning 50 short round trip, got pnl %v %+v", r.PnL, r.Trades)
+	}
+	if rt := r.RoundTrips[0]; !rt.Short || rt.Intent != "SELL_SHORT" {
+		t.Fatalf("unexpected round trip %+v", rt)
+	}
+}
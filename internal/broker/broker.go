@@ -0,0 +1,155 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/outbox"
+)
+
+// OrderStatus follows the proto Order.status lifecycle
+type OrderStatus string
+
+const (
+	StatusNew      OrderStatus = "NEW"      // accepted locally, not yet at the venue
+	StatusSent     OrderStatus = "SENT"     // working at the venue
+	StatusPartial  OrderStatus = "PARTIAL"  // some quantity filled, remainder working
+	StatusReplaced OrderStatus = "REPLACED" // quantity or limit changed, still working
+	StatusFilled   OrderStatus = "FILLED"   // fully filled
+	StatusCanceled OrderStatus = "CANCELED" // canceled before completing
+	StatusRejected OrderStatus = "REJECTED" // refused by the broker or venue
+)
+
+// Terminal reports whether no further transitions are possible
+func (s OrderStatus) Terminal() bool {
+	return s == StatusFilled || s == StatusCanceled || s == StatusRejected
+}
+
+var transitions = map[OrderStatus][]OrderStatus{
+	StatusNew:      {StatusSent, StatusCanceled, StatusRejected},
+	StatusSent:     {StatusPartial, StatusFilled, StatusCanceled, StatusRejected, StatusReplaced},
+	StatusPartial:  {StatusPartial, StatusFilled, StatusCanceled, StatusReplaced},
+	StatusReplaced: {StatusPartial, StatusFilled, StatusCanceled, StatusRejected, StatusReplaced},
+}
+
+// CanTransition reports whether an order may move from one status to another
+func CanTransition(from, to OrderStatus) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrUnknownOrder      = errors.New("unknown order")
+	ErrInvalidTransition = errors.New("invalid order status transition")
+	ErrInvalidOrder      = errors.New("invalid order")
+)
+
+// Order types
+const (
+	TypeMarket          = "MKT"              // fills at the touch, whatever it is
+	TypeLimit           = "LMT"              // fills at the limit or better, rests otherwise
+	TypeIOC             = "IOC"              // limit that cancels whatever does not fill immediately
+	TypeMarketableLimit = "MARKETABLE_LIMIT" // limit priced through the touch by an offset, rests otherwise
+)
+
+// ValidOrderType reports whether t names a supported order type
+func ValidOrderType(t string) bool {
+	switch t {
+	case TypeMarket, TypeLimit, TypeIOC, TypeMarketableLimit:
+		return true
+	}
+	return false
+}
+
+// IsLimitType reports whether orders of type t carry a limit price
+func IsLimitType(t string) bool {
+	return t == TypeLimit || t == TypeIOC || t == TypeMarketableLimit
+}
+
+// TimeInForce returns the time-in-force implied by an order type
+func TimeInForce(t string) string {
+	if t == TypeIOC {
+		return "IOC"
+	}
+	return "DAY"
+}
+
+// OrderRequest is a new order to submit
+type OrderRequest struct {
+	ClientOrderID string // idempotency key visible to the broker
+	Symbol        string
+	Side          string // BUY | SELL
+	Type          string // MKT (default) | LMT | IOC | MARKETABLE_LIMIT
+	Quantity      int
+	LimitPrice    float64 // required for limit types, unused for MKT
+	Intent        string  // decision intent that produced the order
+}
+
+// OrderState is the broker's view of an order
+type OrderState struct {
+	ID            string      `json:"id"`
+	ClientOrderID string      `json:"client_order_id"`
+	Symbol        string      `json:"symbol"`
+	Side          string      `json:"side"`
+	Type          string      `json:"type"`
+	Intent        string      `json:"intent,omitempty"`
+	Quantity      int         `json:"qty"`
+	LimitPrice    float64     `json:"limit_price,omitempty"`
+	FilledQty     int         `json:"filled_qty"`
+	AvgFillPrice  float64     `json:"avg_fill_price,omitempty"`
+	Status        OrderStatus `json:"status"`
+	Reason        string      `json:"reason,omitempty"` // why rejected or canceled
+	CreatedAt     time.Time   `json:"created_at_utc"`
+	UpdatedAt     time.Time   `json:"updated_at_utc"`
+}
+
+// Broker is the execution seam: paper, alpaca and ibkr all sit behind it.
+// Orders submitted with a ClientOrderID keep it as their ID, so callers can
+// journal an order before the broker sees it.
+type Broker interface {
+	Name() string
+	Submit(ctx context.Context, req OrderRequest) (OrderState, error)
+	Cancel(ctx context.Context, orderID string) (OrderState, error)
+	Replace(ctx context.Context, orderID string, quantity int, limitPrice float64) (OrderState, error)
+	Status(ctx context.Context, orderID string) (OrderState, error)
+	Fills() <-chan outbox.Fill  // every execution, in order
+	Updates() <-chan OrderState // every status change, in order
+	Close() error
+}
+
+// Validate checks an order request before submission
+func (r OrderRequest) Validate() error {
+	if r.Symbol == "" {
+		return fmt.Errorf("%w: missing symbol", ErrInvalidOrder)
+	}
+	if r.Side != "BUY" && r.Side != "SELL" {
+		return fmt.Errorf("%w: side %q", ErrInvalidOrder, r.Side)
+	}
+	if r.Quantity <= 0 {
+		return fmt.Errorf("%w: quantity %d", ErrInvalidOrder, r.Quantity)
+	}
+	if r.Type != "" && !ValidOrderType(r.Type) {
+		return fmt.Errorf("%w: order type %q", ErrInvalidOrder, r.Type)
+	}
+	if IsLimitType(r.Type) && r.LimitPrice <= 0 {
+		return fmt.Errorf("%w: %s order needs a limit price", ErrInvalidOrder, r.Type)
+	}
+	return nil
+}
+
+// SideForIntent maps a decision intent to an order side
+func SideForIntent(intent string) string {
+	switch intent {
+	case "BUY_1X", "BUY_5X", "COVER":
+		return "BUY"
+	case "REDUCE", "EXIT", "STOP", "SELL_SHORT":
+		return "SELL"
+	}
+	return ""
+}
@@ -0,0 +1,19 @@
+package broker
+
+import (
+	"fmt"
+
+	"github.com/Rajchodisetti/trading-app/internal/adapters"
+)
+
+// New creates the broker named by BROKER=paper|alpaca|ibkr
+func New(name string, paperCfg PaperConfig, quotes adapters.QuotesAdapter) (Broker, error) {
+	switch name {
+	case "", "paper":
+		return NewPaperBroker(paperCfg, quotes), nil
+	case "alpaca", "ibkr":
+		return nil, fmt.Errorf("broker %q is not implemented yet", name)
+	default:
+		return nil, fmt.Errorf("unknown broker %q", name)
+	}
+}
@@ -0,0 +1,71 @@
+package broker
+
+import "math"
+
+// FeeSchedule prices what an execution costs on top of its fill price.
+// Commission applies to both sides; the SEC fee and FINRA TAF apply to sells.
+// Shorts also pay borrow for every day they are held.
+type FeeSchedule struct {
+	CommissionPerShare    float64 // per share filled
+	CommissionBps         float64 // of filled notional
+	CommissionMinPerOrder float64 // an order pays at least this across its fills
+	SECFeePerMillion      float64 // USD per $1M of sell notional
+	TAFPerShare           float64 // FINRA trading activity fee per share sold
+	TAFMaxPerTrade        float64 // TAF cap per order; 0 = uncapped
+
+	BorrowBpsPerYear float64            // stock borrow on short market value, annualized
+	BorrowSymbolBps  map[string]float64 // per-symbol borrow rates for hard-to-borrow names
+}
+
+// Charges are the costs of one fill, or the sum over an order's fills
+type Charges struct {
+	Commission float64
+	SEC        float64
+	TAF        float64
+}
+
+// Regulatory returns the SEC fee plus the TAF
+func (c Charges) Regulatory() float64 { return c.SEC + c.TAF }
+
+// Add sums two sets of charges
+func (c Charges) Add(o Charges) Charges {
+	return Charges{Commission: c.Commission + o.Commission, SEC: c.SEC + o.SEC, TAF: c.TAF + o.TAF}
+}
+
+// Charge prices one fill of an order. paid is what earlier fills of the same
+// order were charged, so the per-order minimum and the TAF cap hold across
+// partial fills. Amounts are rounded to the cent; the SEC fee rounds up.
+func (s FeeSchedule) Charge(side string, qty int, price float64, paid Charges) Charges {
+	notional := float64(qty) * price
+	var c Charges
+	c.Commission = float64(qty)*s.CommissionPerShare + notional*s.CommissionBps/10000
+	if paid.Commission+c.Commission < s.CommissionMinPerOrder {
+		c.Commission = s.CommissionMinPerOrder - paid.Commission
+	}
+	c.Commission = roundCents(c.Commission)
+
+	if side != "SELL" {
+		return c
+	}
+	c.SEC = math.Ceil(notional*s.SECFeePerMillion/1e6*100-1e-9) / 100
+	c.TAF = float64(qty) * s.TAFPerShare
+	if s.TAFMaxPerTrade > 0 && paid.TAF+c.TAF > s.TAFMaxPerTrade {
+		c.TAF = math.Max(s.TAFMaxPerTrade-paid.TAF, 0)
+	}
+	c.TAF = roundCents(c.TAF)
+	return c
+}
+
+// BorrowFee prices days of stock borrow on a short worth marketValue (either
+// sign), at the symbol's annual rate over a 360-day year, rounded to the cent
+func (s FeeSchedule) BorrowFee(symbol string, marketValue float64, days int) float64 {
+	bps := s.BorrowBpsPerYear
+	if rate, ok := s.BorrowSymbolBps[symbol]; ok {
+		bps = rate
+	}
+	return roundCents(math.Abs(marketValue) * bps / 10000 * float64(days) / 360)
+}
+
+func roundCents(x float64) float64 {
+	return math.Round(x*100) / 100
+}
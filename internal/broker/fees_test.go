@@ -0,0 +1,67 @@
+package broker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeSchedule_Charge(t *testing.T) {
+	s := FeeSchedule{
+		CommissionPerShare:    0.005,
+		CommissionMinPerOrder: 1.00,
+		SECFeePerMillion:      27.80,
+		TAFPerShare:           0.000166,
+		TAFMaxPerTrade:        8.30,
+	}
+
+	// Small buys pay the minimum and no regulatory fees
+	c := s.Charge("BUY", 10, 100, Charges{})
+	assert.Equal(t, Charges{Commission: 1.00}, c)
+
+	// Sells pay SEC (rounded up to the cent) and TAF
+	c = s.Charge("SELL", 1000, 100, Charges{})
+	assert.Equal(t, 5.00, c.Commission)
+	assert.Equal(t, 2.78, c.SEC)
+	assert.Equal(t, 0.17, c.TAF)
+	assert.InDelta(t, 2.95, c.Regulatory(), 1e-9)
+
+	// The minimum and the TAF cap hold across an order's partial fills
+	paid := Charges{Commission: 0.60, TAF: 8.00}
+	c = s.Charge("SELL", 100000, 1, paid)
+	assert.Equal(t, 500.00, c.Commission)
+	assert.InDelta(t, 0.30, c.TAF, 1e-9)
+	c = s.Charge("BUY", 10, 100, paid)
+	assert.InDelta(t, 0.40, c.Commission, 1e-9)
+
+	assert.Equal(t, Charges{}, FeeSchedule{}.Charge("SELL", 100, 50, Charges{}))
+}
+
+func TestFeeSchedule_BorrowFee(t *testing.T) {
+	s := FeeSchedule{BorrowBpsPerYear: 36, BorrowSymbolBps: map[string]float64{"GME": 3600}}
+
+	// 36 bps a year on $100k is $1 a day over a 360-day year, either sign
+	assert.Equal(t, 1.00, s.BorrowFee("AAPL", -100000, 1))
+	assert.Equal(t, 3.00, s.BorrowFee("AAPL", 100000, 3))
+	// Hard-to-borrow names pay their own rate
+	assert.Equal(t, 100.00, s.BorrowFee("GME", -100000, 1))
+	assert.Equal(t, 0.0, FeeSchedule{}.BorrowFee("AAPL", -100000, 1))
+}
+
+func TestPaperBroker_ChargesFees(t *testing.T) {
+	pb, now := newTestBroker(t)
+	pb.fees = FeeSchedule{CommissionPerShare: 0.01, CommissionMinPerOrder: 1, SECFeePerMillion: 27.80}
+	ctx := context.Background()
+
+	_, err := pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "SELL", Quantity: 200, Intent: "EXIT"})
+	require.NoError(t, err)
+	*now = now.Add(100 * time.Millisecond)
+	pb.Process(ctx)
+
+	fill := <-pb.Fills()
+	assert.Equal(t, 2.00, fill.Commission)
+	assert.Greater(t, fill.Fees, 0.0)
+}
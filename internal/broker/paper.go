@@ -0,0 +1,463 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/adapters"
+	"github.com/Rajchodisetti/trading-app/internal/clock"
+	"github.com/Rajchodisetti/trading-app/internal/observ"
+	"github.com/Rajchodisetti/trading-app/internal/outbox"
+)
+
+// PaperConfig configures the paper broker's simulated venue
+type PaperConfig struct {
+	LatencyMsMin   int
+	LatencyMsMax   int
+	SlippageBpsMin int
+	SlippageBpsMax int
+	PollIntervalMs int // how often working orders are checked against quotes
+
+	// Partial fills: each slice takes ParticipationRate of the interval's
+	// estimated volume; orders of at most PartialMinQty shares fill at once
+	ParticipationRate float64
+	PartialMinQty     int
+	PartialIntervalMs int
+
+	Clock clock.Clock // order and fill timestamps; nil uses the wall clock
+	Seed  int64       // nonzero makes latency and slippage draws repeatable
+	Fees  FeeSchedule // commission and regulatory fees charged on each fill
+}
+
+// paperOrder is a working order plus when the venue will act on it
+type paperOrder struct {
+	state OrderState
+	dueAt time.Time
+	paid  Charges // commission and fees charged on the fills so far
+}
+
+// PaperBroker simulates a venue against the live QuotesAdapter. Orders are
+// accepted (NEW), sent (SENT), then executed after a sampled latency at the
+// current quote with simulated slippage. Limit orders only execute when the
+// far touch is at or through their limit; otherwise they rest, except IOCs,
+// which are canceled. With a participation rate configured, large orders fill
+// in slices (PARTIAL) as the symbol's volume allows.
+type PaperBroker struct {
+	mu       sync.Mutex
+	quotes   adapters.QuotesAdapter
+	fillSim  *outbox.FillSimulator
+	fees     FeeSchedule
+	orders   map[string]*paperOrder
+	byClient map[string]string // client order id -> order id
+	seq      int
+	now      func() time.Time
+
+	pollInterval time.Duration
+	fills        chan outbox.Fill
+	updates      chan OrderState
+	stop         chan struct{}
+	stopOnce     sync.Once
+	wg           sync.WaitGroup
+
+	// Publishers hold pubMu's read lock while sending; Close takes the write
+	// lock before closing the streams, so no send can race the close
+	pubMu  sync.RWMutex
+	closed bool
+}
+
+// NewPaperBroker creates a paper broker; call Start to begin executing orders
+func NewPaperBroker(cfg PaperConfig, quotes adapters.QuotesAdapter) *PaperBroker {
+	if cfg.LatencyMsMax < cfg.LatencyMsMin {
+		cfg.LatencyMsMax = cfg.LatencyMsMin
+	}
+	if cfg.SlippageBpsMax < cfg.SlippageBpsMin {
+		cfg.SlippageBpsMax = cfg.SlippageBpsMin
+	}
+	if cfg.PollIntervalMs <= 0 {
+		cfg.PollIntervalMs = 50
+	}
+	fillSim := outbox.NewFillSimulator(cfg.LatencyMsMin, cfg.LatencyMsMax, cfg.SlippageBpsMin, cfg.SlippageBpsMax)
+	fillSim.SetPartialFills(outbox.PartialFillConfig{
+		ParticipationRate: cfg.ParticipationRate,
+		MinOrderQty:       cfg.PartialMinQty,
+		IntervalMs:        cfg.PartialIntervalMs,
+	})
+	if cfg.Seed != 0 {
+		fillSim.SetSeed(cfg.Seed)
+	}
+	return &PaperBroker{
+		quotes:       quotes,
+		fillSim:      fillSim,
+		fees:         cfg.Fees,
+		orders:       make(map[string]*paperOrder),
+		byClient:     make(map[string]string),
+		now:          clock.Or(cfg.Clock).Now,
+		pollInterval: time.Duration(cfg.PollIntervalMs) * time.Millisecond,
+		fills:        make(chan outbox.Fill, 1024),
+		updates:      make(chan OrderState, 1024),
+		stop:         make(chan struct{}),
+	}
+}
+
+func (pb *PaperBroker) Name() string { return "paper" }
+
+// Start runs the background loop that executes due orders
+func (pb *PaperBroker) Start(ctx context.Context) {
+	pb.wg.Add(1)
+	go func() {
+		defer pb.wg.Done()
+		ticker := time.NewTicker(pb.pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-pb.stop:
+				return
+			case <-ticker.C:
+				pb.Process(ctx)
+			}
+		}
+	}()
+}
+
+// Submit accepts an order under its client order id, or a generated id when
+// it has none. Resubmitting a client order id returns the existing order.
+func (pb *PaperBroker) Submit(ctx context.Context, req OrderRequest) (OrderState, error) {
+	if err := req.Validate(); err != nil {
+		observ.IncCounter("broker_rejects_total", map[string]string{"broker": pb.Name(), "reason": "invalid"})
+		return OrderState{}, err
+	}
+	if req.Type == "" {
+		req.Type = TypeMarket
+	}
+
+	pb.mu.Lock()
+	if req.ClientOrderID != "" {
+		if id, ok := pb.byClient[req.ClientOrderID]; ok {
+			existing := pb.orders[id].state
+			pb.mu.Unlock()
+			return existing, nil
+		}
+	}
+
+	now := pb.now().UTC()
+	pb.seq++
+	id := req.ClientOrderID
+	if id == "" {
+		id = fmt.Sprintf("paper-%s-%d-%d", req.Symbol, now.UnixNano(), pb.seq)
+	}
+	state := OrderState{
+		ID:            id,
+		ClientOrderID: req.ClientOrderID,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Intent:        req.Intent,
+		Quantity:      req.Quantity,
+		LimitPrice:    req.LimitPrice,
+		Status:        StatusNew,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	po := &paperOrder{state: state, dueAt: now.Add(pb.fillSim.SampleLatency())}
+	pb.orders[state.ID] = po
+	if req.ClientOrderID != "" {
+		pb.byClient[req.ClientOrderID] = state.ID
+	}
+	updates := []OrderState{po.state}
+
+	// The simulated venue acknowledges immediately
+	pb.transitionLocked(po, StatusSent, "", now)
+	updates = append(updates, po.state)
+	sent := po.state
+	pb.mu.Unlock()
+
+	observ.IncCounter("orders_sent_total", map[string]string{"broker": pb.Name(), "symbol": req.Symbol, "side": req.Side})
+	pb.publish(updates, nil)
+	return sent, nil
+}
+
+// Cancel cancels a working order
+func (pb *PaperBroker) Cancel(ctx context.Context, orderID string) (OrderState, error) {
+	pb.mu.Lock()
+	po, ok := pb.orders[orderID]
+	if !ok {
+		pb.mu.Unlock()
+		return OrderState{}, fmt.Errorf("%w: %s", ErrUnknownOrder, orderID)
+	}
+	if err := pb.transitionLocked(po, StatusCanceled, "canceled by client", pb.now().UTC()); err != nil {
+		state := po.state
+		pb.mu.Unlock()
+		return state, err
+	}
+	state := po.state
+	pb.mu.Unlock()
+
+	pb.publish([]OrderState{state}, nil)
+	return state, nil
+}
+
+// Replace changes quantity and limit price of a working order. Quantity
+// cannot drop below what has already filled.
+func (pb *PaperBroker) Replace(ctx context.Context, orderID string, quantity int, limitPrice float64) (OrderState, error) {
+	pb.mu.Lock()
+	po, ok := pb.orders[orderID]
+	if !ok {
+		pb.mu.Unlock()
+		return OrderState{}, fmt.Errorf("%w: %s", ErrUnknownOrder, orderID)
+	}
+	if po.state.Status.Terminal() {
+		state := po.state
+		pb.mu.Unlock()
+		return state, fmt.Errorf("%w: replace %s order", ErrInvalidTransition, state.Status)
+	}
+	if quantity <= po.state.FilledQty {
+		state := po.state
+		pb.mu.Unlock()
+		return state, fmt.Errorf("%w: quantity %d not above filled %d", ErrInvalidOrder, quantity, state.FilledQty)
+	}
+	if err := pb.transitionLocked(po, StatusReplaced, "", pb.now().UTC()); err != nil {
+		state := po.state
+		pb.mu.Unlock()
+		return state, err
+	}
+	po.state.Quantity = quantity
+	po.state.LimitPrice = limitPrice
+	state := po.state
+	pb.mu.Unlock()
+
+	pb.publish([]OrderState{state}, nil)
+	return state, nil
+}
+
+// Status returns the current state of an order
+func (pb *PaperBroker) Status(ctx context.Context, orderID string) (OrderState, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	po, ok := pb.orders[orderID]
+	if !ok {
+		return OrderState{}, fmt.Errorf("%w: %s", ErrUnknownOrder, orderID)
+	}
+	return po.state, nil
+}
+
+func (pb *PaperBroker) Fills() <-chan outbox.Fill  { return pb.fills }
+func (pb *PaperBroker) Updates() <-chan OrderState { return pb.updates }
+
+// Close stops the execution loop and closes the streams. Updates still
+// waiting on a full stream are dropped.
+func (pb *PaperBroker) Close() error {
+	pb.stopOnce.Do(func() {
+		close(pb.stop)
+		pb.wg.Wait()
+
+		pb.pubMu.Lock()
+		pb.closed = true
+		close(pb.fills)
+		close(pb.updates)
+		pb.pubMu.Unlock()
+	})
+	return nil
+}
+
+// Process executes every working order whose latency has elapsed. It is
+// called by the background loop and may be called directly to drive the
+// broker deterministically.
+func (pb *PaperBroker) Process(ctx context.Context) {
+	now := pb.now().UTC()
+
+	pb.mu.Lock()
+	var due []*paperOrder
+	for _, po := range pb.orders {
+		if !po.state.Status.Terminal() && !now.Before(po.dueAt) {
+			due = append(due, po)
+		}
+	}
+	pb.mu.Unlock()
+
+	// Execute in submission order
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].state.CreatedAt.Equal(due[j].state.CreatedAt) {
+			return due[i].state.CreatedAt.Before(due[j].state.CreatedAt)
+		}
+		return due[i].state.ID < due[j].state.ID
+	})
+
+	// Orders on the same symbol share one slice of volume, first come first served
+	budget := make(map[string]int)
+	for _, po := range due {
+		pb.execute(ctx, po, now, budget)
+	}
+}
+
+// execute fills an order at the current quote, rests or expires it, or rejects it
+func (pb *PaperBroker) execute(ctx context.Context, po *paperOrder, now time.Time, budget map[string]int) {
+	quote, err := pb.quotes.GetQuote(ctx, po.state.Symbol)
+	reject := ""
+	switch {
+	case err != nil:
+		reject = "no quote: " + err.Error()
+	case quote.Halted:
+		reject = "symbol halted"
+	}
+
+	pb.mu.Lock()
+	if po.state.Status.Terminal() {
+		// Canceled while we were fetching the quote
+		pb.mu.Unlock()
+		return
+	}
+	if reject != "" {
+		pb.transitionLocked(po, StatusRejected, reject, now)
+		state := po.state
+		pb.mu.Unlock()
+		observ.IncCounter("broker_rejects_total", map[string]string{"broker": pb.Name(), "reason": "venue"})
+		pb.publish([]OrderState{state}, nil)
+		return
+	}
+
+	price := referencePrice(quote, po.state.Side)
+	if IsLimitType(po.state.Type) && !marketable(po.state.Side, price, po.state.LimitPrice) {
+		if po.state.Type != TypeIOC {
+			// Rest until the market reaches the limit
+			pb.mu.Unlock()
+			return
+		}
+		pb.transitionLocked(po, StatusCanceled, "ioc not marketable", now)
+		state := po.state
+		pb.mu.Unlock()
+		observ.IncCounter("broker_ioc_expired_total", map[string]string{"broker": pb.Name(), "symbol": state.Symbol})
+		pb.publish([]OrderState{state}, nil)
+		return
+	}
+
+	capacity, ok := budget[po.state.Symbol]
+	if !ok {
+		capacity = pb.fillSim.Capacity(quote.Volume)
+	}
+	remaining := po.state.Quantity - po.state.FilledQty
+	qty := pb.fillSim.SliceQuantity(po.state.Quantity, remaining, capacity)
+	if capacity >= 0 {
+		budget[po.state.Symbol] = max(capacity-qty, 0)
+	}
+
+	var updates []OrderState
+	var fill *outbox.Fill
+	if qty > 0 {
+		f := pb.fillSim.FillAt(outbox.Order{
+			ID:         po.state.ID,
+			Symbol:     po.state.Symbol,
+			Intent:     po.state.Intent,
+			Side:       po.state.Side,
+			Quantity:   qty,
+			OrderType:  po.state.Type,
+			LimitPrice: po.state.LimitPrice,
+		}, price, now)
+		f.LatencyMs = int(now.Sub(po.state.CreatedAt) / time.Millisecond)
+		charges := pb.fees.Charge(po.state.Side, qty, f.Price, po.paid)
+		po.paid = po.paid.Add(charges)
+		f.Commission = charges.Commission
+		f.Fees = charges.Regulatory()
+		fill = &f
+
+		pb.applyFillLocked(po, f)
+		if po.state.FilledQty >= po.state.Quantity {
+			pb.transitionLocked(po, StatusFilled, "", now)
+		} else {
+			pb.transitionLocked(po, StatusPartial, "", now)
+		}
+		updates = append(updates, po.state)
+	}
+
+	if !po.state.Status.Terminal() {
+		if po.state.Type == TypeIOC {
+			pb.transitionLocked(po, StatusCanceled, "ioc remainder expired", now)
+			updates = append(updates, po.state)
+			observ.IncCounter("broker_ioc_expired_total", map[string]string{"broker": pb.Name(), "symbol": po.state.Symbol})
+		} else {
+			// Queue for the next slice of volume
+			po.dueAt = now.Add(pb.fillSim.SliceInterval())
+		}
+	}
+	pb.mu.Unlock()
+
+	pb.publish(updates, fill)
+}
+
+// applyFillLocked folds a fill into the order's filled quantity and average price
+func (pb *PaperBroker) applyFillLocked(po *paperOrder, fill outbox.Fill) {
+	qty := int(fill.Quantity)
+	totalCost := po.state.AvgFillPrice*float64(po.state.FilledQty) + fill.Price*float64(qty)
+	po.state.FilledQty += qty
+	if po.state.FilledQty > 0 {
+		po.state.AvgFillPrice = totalCost / float64(po.state.FilledQty)
+	}
+}
+
+// transitionLocked moves an order to a new status if the lifecycle allows it
+func (pb *PaperBroker) transitionLocked(po *paperOrder, to OrderStatus, reason string, now time.Time) error {
+	if !CanTransition(po.state.Status, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, po.state.Status, to)
+	}
+	po.state.Status = to
+	po.state.Reason = reason
+	po.state.UpdatedAt = now
+	observ.IncCounter("broker_order_transitions_total", map[string]string{"broker": pb.Name(), "status": string(to)})
+	return nil
+}
+
+// publish sends status updates and an optional fill to the streams, outside
+// the order lock. A full stream blocks the sender until the consumer catches
+// up or the broker is closed; after Close nothing is sent.
+func (pb *PaperBroker) publish(updates []OrderState, fill *outbox.Fill) {
+	pb.pubMu.RLock()
+	defer pb.pubMu.RUnlock()
+	if pb.closed {
+		observ.IncCounter("broker_updates_dropped_total", map[string]string{"broker": pb.Name()})
+		return
+	}
+
+	if fill != nil {
+		select {
+		case pb.fills <- *fill:
+		case <-pb.stop:
+			observ.IncCounter("broker_updates_dropped_total", map[string]string{"broker": pb.Name()})
+			return
+		}
+	}
+	for _, u := range updates {
+		select {
+		case pb.updates <- u:
+		case <-pb.stop:
+			observ.IncCounter("broker_updates_dropped_total", map[string]string{"broker": pb.Name()})
+			return
+		}
+	}
+}
+
+// marketable reports whether a limit order can execute against the touch
+func marketable(side string, touch, limit float64) bool {
+	if touch <= 0 {
+		return false
+	}
+	if side == "BUY" {
+		return touch <= limit
+	}
+	return touch >= limit
+}
+
+// referencePrice is the side of the book a market order would take
+func referencePrice(q *adapters.Quote, side string) float64 {
+	if side == "BUY" && q.Ask > 0 {
+		return q.Ask
+	}
+	if side == "SELL" && q.Bid > 0 {
+		return q.Bid
+	}
+	return q.Last
+}
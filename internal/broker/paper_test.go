@@ -0,0 +1,263 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Rajchodisetti/trading-app/internal/adapters"
+)
+
+func newTestBroker(t *testing.T) (*PaperBroker, *time.Time) {
+	quotes := adapters.NewMockQuotesAdapter()
+	quotes.SetLatency(0)
+	pb := NewPaperBroker(PaperConfig{LatencyMsMin: 100, LatencyMsMax: 100, SlippageBpsMin: 0, SlippageBpsMax: 0}, quotes)
+	now := time.Date(2025, 8, 25, 15, 0, 0, 0, time.UTC)
+	pb.now = func() time.Time { return now }
+	t.Cleanup(func() { pb.Close() })
+	return pb, &now
+}
+
+func drainUpdates(pb *PaperBroker) []OrderStatus {
+	var out []OrderStatus
+	for {
+		select {
+		case u := <-pb.Updates():
+			out = append(out, u.Status)
+		default:
+			return out
+		}
+	}
+}
+
+func TestPaperBroker_Lifecycle(t *testing.T) {
+	pb, now := newTestBroker(t)
+	ctx := context.Background()
+
+	state, err := pb.Submit(ctx, OrderRequest{ClientOrderID: "k1", Symbol: "AAPL", Side: "BUY", Quantity: 10, Intent: "BUY_1X"})
+	require.NoError(t, err)
+	assert.Equal(t, StatusSent, state.Status)
+	assert.Equal(t, "k1", state.ID, "client order id doubles as the order id")
+
+	// Resubmitting the same client order id is idempotent
+	again, err := pb.Submit(ctx, OrderRequest{ClientOrderID: "k1", Symbol: "AAPL", Side: "BUY", Quantity: 10})
+	require.NoError(t, err)
+	assert.Equal(t, state.ID, again.ID)
+
+	// Not due yet
+	pb.Process(ctx)
+	state, _ = pb.Status(ctx, state.ID)
+	assert.Equal(t, StatusSent, state.Status)
+
+	*now = now.Add(100 * time.Millisecond)
+	pb.Process(ctx)
+	state, _ = pb.Status(ctx, state.ID)
+	assert.Equal(t, StatusFilled, state.Status)
+	assert.Equal(t, 10, state.FilledQty)
+	assert.InDelta(t, 210.10, state.AvgFillPrice, 1e-9) // buys take the ask
+
+	fill := <-pb.Fills()
+	assert.Equal(t, 10.0, fill.Quantity)
+	assert.Equal(t, "BUY", fill.Side)
+	assert.Equal(t, []OrderStatus{StatusNew, StatusSent, StatusFilled}, drainUpdates(pb))
+
+	// Terminal orders cannot be canceled or replaced
+	_, err = pb.Cancel(ctx, state.ID)
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+	_, err = pb.Replace(ctx, state.ID, 20, 0)
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+}
+
+func TestPaperBroker_RejectsHaltedAndCancels(t *testing.T) {
+	pb, now := newTestBroker(t)
+	ctx := context.Background()
+
+	halted, err := pb.Submit(ctx, OrderRequest{Symbol: "NVDA", Side: "BUY", Quantity: 1})
+	require.NoError(t, err)
+	working, err := pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "SELL", Quantity: 5})
+	require.NoError(t, err)
+
+	replaced, err := pb.Replace(ctx, working.ID, 8, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 8, replaced.Quantity)
+	assert.Equal(t, StatusReplaced, replaced.Status)
+
+	canceled, err := pb.Cancel(ctx, working.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCanceled, canceled.Status)
+
+	*now = now.Add(time.Second)
+	pb.Process(ctx)
+
+	state, _ := pb.Status(ctx, halted.ID)
+	assert.Equal(t, StatusRejected, state.Status)
+	assert.Equal(t, "symbol halted", state.Reason)
+	state, _ = pb.Status(ctx, working.ID)
+	assert.Equal(t, StatusCanceled, state.Status)
+
+	_, err = pb.Status(ctx, "nope")
+	assert.True(t, errors.Is(err, ErrUnknownOrder))
+	_, err = pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "HOLD", Quantity: 1})
+	assert.True(t, errors.Is(err, ErrInvalidOrder))
+}
+
+func TestPaperBroker_ReplacePublishesUpdate(t *testing.T) {
+	pb, now := newTestBroker(t)
+	ctx := context.Background()
+
+	state, err := pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "BUY", Type: TypeLimit, Quantity: 5, LimitPrice: 100})
+	require.NoError(t, err)
+	_, err = pb.Replace(ctx, state.ID, 7, 300)
+	require.NoError(t, err)
+	assert.Equal(t, []OrderStatus{StatusNew, StatusSent, StatusReplaced}, drainUpdates(pb))
+
+	// The replaced limit is marketable and fills
+	*now = now.Add(time.Second)
+	pb.Process(ctx)
+	state, _ = pb.Status(ctx, state.ID)
+	assert.Equal(t, StatusFilled, state.Status)
+	assert.Equal(t, 7, state.FilledQty)
+}
+
+func TestPaperBroker_CloseUnblocksPublishers(t *testing.T) {
+	quotes := adapters.NewMockQuotesAdapter()
+	quotes.SetLatency(0)
+	pb := NewPaperBroker(PaperConfig{}, quotes)
+	ctx := context.Background()
+
+	// Nobody reads the streams, so submitting fills them and then blocks
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 600; i++ {
+			pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "BUY", Quantity: 1})
+		}
+	}()
+	require.Eventually(t, func() bool { return len(pb.Updates()) == cap(pb.Updates()) }, time.Second, time.Millisecond)
+
+	require.NoError(t, pb.Close())
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publisher still blocked after Close")
+	}
+
+	// Orders placed after Close do not send on the closed streams
+	state, err := pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "BUY", Quantity: 1})
+	require.NoError(t, err)
+	_, err = pb.Cancel(ctx, state.ID)
+	require.NoError(t, err)
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, CanTransition(StatusNew, StatusSent))
+	assert.True(t, CanTransition(StatusSent, StatusPartial))
+	assert.True(t, CanTransition(StatusPartial, StatusFilled))
+	assert.True(t, CanTransition(StatusPartial, StatusReplaced))
+	assert.True(t, CanTransition(StatusReplaced, StatusFilled))
+	assert.False(t, CanTransition(StatusFilled, StatusReplaced))
+	assert.False(t, CanTransition(StatusFilled, StatusCanceled))
+	assert.False(t, CanTransition(StatusNew, StatusFilled))
+}
+
+func TestPaperBroker_LimitOrders(t *testing.T) {
+	pb, now := newTestBroker(t)
+	ctx := context.Background()
+
+	// AAPL mock quote is 210.00 x 210.10
+	resting, err := pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "BUY", Type: TypeLimit, LimitPrice: 209.50, Quantity: 3})
+	require.NoError(t, err)
+	ioc, err := pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "BUY", Type: TypeIOC, LimitPrice: 209.50, Quantity: 3})
+	require.NoError(t, err)
+	through, err := pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "SELL", Type: TypeMarketableLimit, LimitPrice: 209.90, Quantity: 3})
+	require.NoError(t, err)
+	_, err = pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "BUY", Type: TypeLimit, Quantity: 3})
+	assert.True(t, errors.Is(err, ErrInvalidOrder), "limit orders need a price")
+
+	*now = now.Add(time.Second)
+	pb.Process(ctx)
+
+	state, _ := pb.Status(ctx, resting.ID)
+	assert.Equal(t, StatusSent, state.Status)
+	state, _ = pb.Status(ctx, ioc.ID)
+	assert.Equal(t, StatusCanceled, state.Status)
+	assert.Equal(t, "ioc not marketable", state.Reason)
+	state, _ = pb.Status(ctx, through.ID)
+	assert.Equal(t, StatusFilled, state.Status)
+	assert.InDelta(t, 210.00, state.AvgFillPrice, 1e-9)
+
+	// Raising the limit through the ask fills the resting order, capped at the ask
+	_, err = pb.Replace(ctx, resting.ID, 3, 210.50)
+	require.NoError(t, err)
+	pb.Process(ctx)
+	state, _ = pb.Status(ctx, resting.ID)
+	assert.Equal(t, StatusFilled, state.Status)
+	assert.InDelta(t, 210.10, state.AvgFillPrice, 1e-9)
+}
+
+func TestPaperBroker_PartialFills(t *testing.T) {
+	quotes := adapters.NewMockQuotesAdapter()
+	quotes.SetLatency(0)
+	// AAPL trades 12.5M/day: 10% of one second's volume is 53 shares
+	pb := NewPaperBroker(PaperConfig{ParticipationRate: 0.10, PartialMinQty: 100, PartialIntervalMs: 1000}, quotes)
+	now := time.Date(2025, 8, 25, 15, 0, 0, 0, time.UTC)
+	pb.now = func() time.Time { return now }
+	defer pb.Close()
+	ctx := context.Background()
+
+	first, err := pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "BUY", Quantity: 120})
+	require.NoError(t, err)
+	second, err := pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "BUY", Quantity: 120})
+	require.NoError(t, err)
+	small, err := pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "BUY", Quantity: 100})
+	require.NoError(t, err)
+
+	pb.Process(ctx)
+	state, _ := pb.Status(ctx, first.ID)
+	assert.Equal(t, StatusPartial, state.Status)
+	assert.Equal(t, 53, state.FilledQty)
+	state, _ = pb.Status(ctx, second.ID)
+	assert.Equal(t, StatusSent, state.Status, "queued behind the first order")
+	state, _ = pb.Status(ctx, small.ID)
+	assert.Equal(t, StatusFilled, state.Status, "small orders fill in one shot")
+
+	// Next slice is not due until the interval elapses
+	pb.Process(ctx)
+	state, _ = pb.Status(ctx, first.ID)
+	assert.Equal(t, 53, state.FilledQty)
+
+	for i := 0; i < 2; i++ {
+		now = now.Add(time.Second)
+		pb.Process(ctx)
+	}
+	state, _ = pb.Status(ctx, first.ID)
+	assert.Equal(t, StatusFilled, state.Status)
+	assert.Equal(t, 120, state.FilledQty)
+	state, _ = pb.Status(ctx, second.ID)
+	assert.Equal(t, StatusPartial, state.Status)
+
+	var filled float64
+	for len(pb.Fills()) > 0 {
+		f := <-pb.Fills()
+		if f.OrderID == first.ID {
+			filled += f.Quantity
+		}
+	}
+	assert.Equal(t, 120.0, filled, "one fill record per slice")
+
+	// An IOC takes what the slice allows and cancels the rest
+	_, err = pb.Cancel(ctx, second.ID)
+	require.NoError(t, err)
+	ioc, err := pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "SELL", Type: TypeIOC, LimitPrice: 209.00, Quantity: 500})
+	require.NoError(t, err)
+	now = now.Add(time.Second)
+	pb.Process(ctx)
+	state, _ = pb.Status(ctx, ioc.ID)
+	assert.Equal(t, StatusCanceled, state.Status)
+	assert.Equal(t, "ioc remainder expired", state.Reason)
+	assert.Equal(t, 53, state.FilledQty)
+}
@@ -0,0 +1,118 @@
+package broker
+
+import "strings"
+
+// Market is the book an order is priced against
+type Market struct {
+	Bid        float64
+	Ask        float64
+	Last       float64
+	AfterHours bool // premarket or postmarket session
+}
+
+// Mid returns the midpoint, falling back to last when either side is missing
+func (m Market) Mid() float64 {
+	if m.Bid > 0 && m.Ask > 0 {
+		return (m.Bid + m.Ask) / 2
+	}
+	return m.Last
+}
+
+// SpreadBps returns the quoted spread in basis points of the mid, or 0 when unknown
+func (m Market) SpreadBps() float64 {
+	if m.Bid <= 0 || m.Ask <= 0 {
+		return 0
+	}
+	return (m.Ask - m.Bid) / m.Mid() * 10000
+}
+
+// touch returns the side of the book an order of the given side would take (far)
+// or join (near), falling back to last
+func (m Market) touch(side string, far bool) float64 {
+	takeAsk := (side == "BUY") == far
+	if takeAsk && m.Ask > 0 {
+		return m.Ask
+	}
+	if !takeAsk && m.Bid > 0 {
+		return m.Bid
+	}
+	return m.Last
+}
+
+// PriceProtection caps what news-driven entries pay in thin markets. Protected
+// entries are sent as IOC limits a fixed distance from the mid, so a wide or
+// gapping book cancels the order instead of filling it at the far touch.
+type PriceProtection struct {
+	AfterHours     bool    // protect every entry outside regular hours
+	SpreadBps      float64 // protect entries when the spread is at least this wide; 0 disables
+	MaxSlippageBps float64 // protected entries pay at most this far from the mid
+}
+
+// PricingConfig chooses the order type and limit price for decision orders
+type PricingConfig struct {
+	OrderType      string  // MKT | LMT | IOC | MARKETABLE_LIMIT
+	LimitOffsetBps float64 // how far IOC and marketable-limit prices reach through the far touch
+	Protection     PriceProtection
+}
+
+// Price sets the order type and limit price of req against the market.
+// It reports whether price protection replaced the configured type.
+func (c PricingConfig) Price(req OrderRequest, m Market) (OrderRequest, bool) {
+	req.Type = c.OrderType
+	if req.Type == "" {
+		req.Type = TypeMarket
+	}
+
+	switch req.Type {
+	case TypeLimit:
+		req.LimitPrice = roundPrice(m.touch(req.Side, false))
+	case TypeIOC, TypeMarketableLimit:
+		req.LimitPrice = roundPrice(offsetPrice(m.touch(req.Side, true), req.Side, c.LimitOffsetBps))
+	default:
+		req.LimitPrice = 0
+	}
+
+	// Passive limits already join the near touch and need no protection
+	if req.Type == TypeLimit || !c.protects(req, m) {
+		return req, false
+	}
+
+	// Buys pay at most the cap and short sales take at least it
+	capPrice := roundPrice(offsetPrice(m.Mid(), req.Side, c.Protection.MaxSlippageBps))
+	throughCap := capPrice < req.LimitPrice
+	if req.Side != "BUY" {
+		throughCap = capPrice > req.LimitPrice
+	}
+	if req.Type == TypeMarket || throughCap {
+		req.LimitPrice = capPrice
+	}
+	req.Type = TypeIOC
+	return req, true
+}
+
+// protects reports whether an order needs price protection; only entries are
+// protected so that exits are never held back by the book
+func (c PricingConfig) protects(req OrderRequest, m Market) bool {
+	entry := (req.Side == "BUY" && strings.HasPrefix(req.Intent, "BUY_")) || (req.Side == "SELL" && req.Intent == "SELL_SHORT")
+	if !entry {
+		return false
+	}
+	if c.Protection.AfterHours && m.AfterHours {
+		return true
+	}
+	return c.Protection.SpreadBps > 0 && m.SpreadBps() >= c.Protection.SpreadBps
+}
+
+// offsetPrice moves a price away from the market by bps in the direction that
+// makes the order more aggressive
+func offsetPrice(price float64, side string, bps float64) float64 {
+	if side == "BUY" {
+		return price * (1 + bps/10000)
+	}
+	return price * (1 - bps/10000)
+}
+
+// roundPrice rounds to the cent
+func roundPrice(p float64) float64 {
+	return float64(int64(p*100+0.5)) / 100
+}
@@ -0,0 +1,64 @@
+package broker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPricingConfig_Price(t *testing.T) {
+	m := Market{Bid: 100.00, Ask: 100.10, Last: 100.05}
+	buy := OrderRequest{Symbol: "AAPL", Side: "BUY", Quantity: 10, Intent: "BUY_1X"}
+	sell := OrderRequest{Symbol: "AAPL", Side: "SELL", Quantity: 10, Intent: "EXIT"}
+
+	req, protected := PricingConfig{}.Price(buy, m)
+	assert.False(t, protected)
+	assert.Equal(t, TypeMarket, req.Type)
+	assert.Zero(t, req.LimitPrice)
+
+	req, _ = PricingConfig{OrderType: TypeLimit}.Price(buy, m)
+	assert.Equal(t, 100.00, req.LimitPrice) // joins the bid
+
+	cfg := PricingConfig{OrderType: TypeMarketableLimit, LimitOffsetBps: 10}
+	req, _ = cfg.Price(buy, m)
+	assert.Equal(t, TypeMarketableLimit, req.Type)
+	assert.Equal(t, 100.20, req.LimitPrice) // ask + 10bps
+	req, _ = cfg.Price(sell, m)
+	assert.Equal(t, 99.90, req.LimitPrice) // bid - 10bps
+}
+
+func TestPricingConfig_ProtectsAfterHoursWideSpreadEntries(t *testing.T) {
+	// ticks_after_hours_wide_spread.json: premarket AAPL 210.00 x 212.60
+	m := Market{Bid: 210.00, Ask: 212.60, Last: 211.30, AfterHours: true}
+	cfg := PricingConfig{
+		OrderType:      TypeMarketableLimit,
+		LimitOffsetBps: 10,
+		Protection:     PriceProtection{AfterHours: true, SpreadBps: 25, MaxSlippageBps: 15},
+	}
+
+	req, protected := cfg.Price(OrderRequest{Symbol: "AAPL", Side: "BUY", Quantity: 5, Intent: "BUY_1X"}, m)
+	assert.True(t, protected)
+	assert.Equal(t, TypeIOC, req.Type)
+	assert.Equal(t, 211.62, req.LimitPrice) // mid + 15bps, well inside the 212.60 ask
+
+	// Short sales are floored at mid - 15bps, well inside the 210.00 bid
+	short := OrderRequest{Symbol: "AAPL", Side: "SELL", Quantity: 5, Intent: "SELL_SHORT"}
+	req, protected = cfg.Price(short, m)
+	assert.True(t, protected)
+	assert.Equal(t, TypeIOC, req.Type)
+	assert.Equal(t, 210.98, req.LimitPrice) // not the 209.79 marketable limit
+	req, protected = PricingConfig{Protection: cfg.Protection}.Price(short, m)
+	assert.True(t, protected)
+	assert.Equal(t, TypeIOC, req.Type)
+	assert.Equal(t, 210.98, req.LimitPrice)
+
+	// Spread alone triggers protection during regular hours
+	m.AfterHours = false
+	_, protected = cfg.Price(OrderRequest{Symbol: "AAPL", Side: "BUY", Quantity: 5, Intent: "BUY_1X"}, m)
+	assert.True(t, protected)
+
+	// Exits are never protected
+	req, protected = cfg.Price(OrderRequest{Symbol: "AAPL", Side: "SELL", Quantity: 5, Intent: "EXIT"}, m)
+	assert.False(t, protected)
+	assert.Equal(t, TypeMarketableLimit, req.Type)
+}
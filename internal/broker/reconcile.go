@@ -0,0 +1,274 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/observ"
+	"github.com/Rajchodisetti/trading-app/internal/outbox"
+)
+
+// StatusExpired marks an outbox order that recovery could not resolve at the broker
+const StatusExpired = "EXPIRED"
+
+// ReconciledOrder describes how recovery resolved one open outbox order
+type ReconciledOrder struct {
+	OrderID       string `json:"order_id"`
+	Symbol        string `json:"symbol"`
+	Side          string `json:"side"`
+	PriorStatus   string `json:"prior_status"`
+	Status        string `json:"status"`                   // status written back to the outbox; a resubmitted order's new status
+	Source        string `json:"source"`                   // "outbox", "broker", "resubmitted" or "expired"
+	RecoveredQty  int    `json:"recovered_qty"`            // filled at the broker but missing from the outbox
+	ResubmittedAs string `json:"resubmitted_as,omitempty"` // order carrying the unfilled remainder
+}
+
+// ReconcileReport summarizes a startup recovery pass
+type ReconcileReport struct {
+	Open      int               `json:"open"`
+	Orders    []ReconciledOrder `json:"orders"`
+	Recovered []outbox.Fill     `json:"-"` // compensating fills written to the outbox
+	Errors    []string          `json:"errors,omitempty"`
+}
+
+// RecoveryPolicy decides which orders the broker lost may be resubmitted.
+// DAY orders whose session has ended are always expired.
+type RecoveryPolicy struct {
+	// MaxAge expires orders first journaled longer ago; zero keeps them
+	MaxAge time.Duration
+	// Guard rechecks the remainder of an order just before it is resubmitted.
+	// A non-empty reason expires the order instead. Nil sends without a recheck.
+	Guard func(ctx context.Context, order outbox.Order, placedAt time.Time) (reason string, err error)
+}
+
+// Counts tallies reconciled orders by resulting status
+func (r ReconcileReport) Counts() map[string]int {
+	counts := make(map[string]int)
+	for _, o := range r.Orders {
+		counts[o.Status]++
+	}
+	return counts
+}
+
+// Reconcile replays the outbox and resolves every order left without a
+// terminal status, typically because the process died before the broker's
+// fill or final status was recorded. Orders whose fills are all in the
+// outbox are marked FILLED. The rest are looked up at the broker;
+// fills the broker reports beyond what the outbox holds are written as
+// compensating fills and returned so callers can apply them to the portfolio.
+// Orders the broker does not know, such as those a paper broker lost with
+// the previous process, are resubmitted for their unfilled remainder as a
+// new order and the original is written back as EXPIRED. Orders that cannot
+// be resubmitted (IOC, legacy records without a quantity, or all orders when
+// b is nil) are only expired, as are orders policy rules out: DAY orders from
+// an ended session, orders older than policy.MaxAge and orders policy.Guard
+// rejects.
+//
+// Resubmitted orders are recorded as SENT once the broker accepts them, so
+// callers must not consume b's updates until Reconcile returns.
+func Reconcile(ctx context.Context, ob *outbox.Outbox, b Broker, now time.Time, policy RecoveryPolicy) (ReconcileReport, error) {
+	var report ReconcileReport
+	open, err := ob.OpenOrders()
+	if err != nil {
+		return report, fmt.Errorf("replay outbox: %w", err)
+	}
+	report.Open = len(open)
+
+	for _, h := range open {
+		rec := ReconciledOrder{
+			OrderID:     h.Order.ID,
+			Symbol:      h.Order.Symbol,
+			Side:        h.Order.Side,
+			PriorStatus: h.Order.Status,
+		}
+		order := h.Order
+		order.Timestamp = now
+
+		var state OrderState
+		var leg outbox.Order // resubmitted remainder
+		found := false
+		// The outbox may already hold every fill; only the final status went missing
+		filledInOutbox := h.FilledQty > 0 && h.FilledQty >= h.Order.Quantity
+		if b != nil && !filledInOutbox {
+			state, err = b.Status(ctx, h.Order.ID)
+			switch {
+			case err == nil:
+				found = true
+			case !errors.Is(err, ErrUnknownOrder):
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", h.Order.ID, err))
+				continue
+			}
+		}
+
+		switch {
+		case filledInOutbox:
+			rec.Source = "outbox"
+			order.Status = string(StatusFilled)
+			order.FilledQty = h.FilledQty
+		case found:
+			rec.Source = "broker"
+			if missing := state.FilledQty - h.FilledQty; missing > 0 {
+				fill := outbox.Fill{
+					OrderID:   state.ID,
+					Symbol:    state.Symbol,
+					Quantity:  float64(missing),
+					Price:     missingFillPrice(state, h),
+					Side:      state.Side,
+					Timestamp: state.UpdatedAt,
+				}
+				if err := ob.WriteFill(fill); err != nil {
+					report.Errors = append(report.Errors, fmt.Sprintf("%s: write fill: %v", h.Order.ID, err))
+					continue
+				}
+				report.Recovered = append(report.Recovered, fill)
+				rec.RecoveredQty = missing
+			}
+			order.Status = string(state.Status)
+			order.FilledQty = state.FilledQty
+			order.Reason = state.Reason
+		case b != nil && resubmittable(h):
+			reason, err := policy.expiry(ctx, h, now)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", h.Order.ID, err))
+				continue
+			}
+			if reason != "" {
+				rec.Source = "expired"
+				order.Status = StatusExpired
+				order.FilledQty = h.FilledQty
+				order.Reason = reason
+				break
+			}
+			leg, err = resubmit(ctx, ob, b, h, now)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: resubmit: %v", h.Order.ID, err))
+				continue
+			}
+			rec.Source = "resubmitted"
+			rec.ResubmittedAs = leg.ID
+			order.Status = StatusExpired
+			order.FilledQty = h.FilledQty
+			order.Reason = "resubmitted as " + leg.ID + " at startup recovery"
+		default:
+			rec.Source = "expired"
+			order.Status = StatusExpired
+			order.FilledQty = h.FilledQty
+			order.Reason = "unresolved at startup recovery"
+		}
+		rec.Status = order.Status
+
+		// Compensating record so the next replay sees the resolved state
+		if err := ob.WriteOrder(order); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: write order: %v", h.Order.ID, err))
+			continue
+		}
+		if leg.ID != "" {
+			rec.Status = leg.Status
+		}
+		report.Orders = append(report.Orders, rec)
+		observ.IncCounter("outbox_reconciled_total", map[string]string{"status": rec.Status, "source": rec.Source})
+	}
+	return report, nil
+}
+
+// resubmittable reports whether an order the broker lost can be placed
+// again: it needs a size and a side, and IOC orders never rest
+func resubmittable(h outbox.OrderHistory) bool {
+	side := h.Order.Side
+	if side == "" {
+		side = SideForIntent(h.Order.Intent)
+	}
+	return side != "" && h.Order.Quantity > h.FilledQty && h.Order.OrderType != TypeIOC
+}
+
+// expiry says why a lost order must not be resubmitted, or "" if it may be
+func (p RecoveryPolicy) expiry(ctx context.Context, h outbox.OrderHistory, now time.Time) (string, error) {
+	if !now.Before(sessionEnd(h.FirstSeen)) {
+		return "session ended before startup recovery", nil
+	}
+	if p.MaxAge > 0 && now.Sub(h.FirstSeen) > p.MaxAge {
+		return fmt.Sprintf("older than %s at startup recovery", p.MaxAge), nil
+	}
+	if p.Guard == nil {
+		return "", nil
+	}
+	remainder := h.Order
+	remainder.Quantity = h.Order.Quantity - h.FilledQty
+	reason, err := p.Guard(ctx, remainder, h.FirstSeen)
+	if err != nil {
+		return "", fmt.Errorf("recheck: %w", err)
+	}
+	if reason != "" {
+		return "rejected at startup recovery: " + reason, nil
+	}
+	return "", nil
+}
+
+// sessionEnd is when a DAY order placed at t stops working: the end of
+// extended hours, 20:00 ET, on the day it was placed
+func sessionEnd(t time.Time) time.Time {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		loc = time.FixedZone("EST", -5*3600)
+	}
+	et := t.In(loc)
+	end := time.Date(et.Year(), et.Month(), et.Day(), 20, 0, 0, 0, loc)
+	if !et.Before(end) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// resubmit places the unfilled remainder of a lost order as a new order. Like
+// submitOrder in cmd/decision, it journals the order as NEW before the broker
+// sees it and records the result after.
+func resubmit(ctx context.Context, ob *outbox.Outbox, b Broker, h outbox.OrderHistory, now time.Time) (outbox.Order, error) {
+	leg := h.Order
+	leg.ID = h.Order.ID + "-r"
+	leg.Quantity = h.Order.Quantity - h.FilledQty
+	leg.FilledQty = 0
+	leg.NotionalUSD = 0
+	leg.Reason = "resubmits " + h.Order.ID
+	leg.Guard = nil
+	leg.Timestamp = now
+	leg.Status = string(StatusNew)
+	if leg.Side == "" {
+		leg.Side = SideForIntent(leg.Intent)
+	}
+	if err := ob.WriteOrder(leg); err != nil {
+		return leg, fmt.Errorf("write order: %w", err)
+	}
+
+	state, err := b.Submit(ctx, OrderRequest{
+		ClientOrderID: leg.ID,
+		Symbol:        leg.Symbol,
+		Side:          leg.Side,
+		Type:          leg.OrderType,
+		Quantity:      leg.Quantity,
+		LimitPrice:    leg.LimitPrice,
+		Intent:        leg.Intent,
+	})
+	if err != nil {
+		leg.Status = string(StatusRejected)
+		leg.Reason = err.Error()
+		if werr := ob.WriteOrder(leg); werr != nil {
+			return leg, fmt.Errorf("write order: %w", werr)
+		}
+		return leg, err
+	}
+	leg.Status = string(state.Status)
+	leg.Timestamp = state.UpdatedAt
+	return leg, ob.WriteOrder(leg)
+}
+
+// missingFillPrice is the average price of the broker fills the outbox lacks
+func missingFillPrice(state OrderState, h outbox.OrderHistory) float64 {
+	missing := state.FilledQty - h.FilledQty
+	cost := state.AvgFillPrice*float64(state.FilledQty) - h.FilledCost
+	if missing <= 0 || cost <= 0 {
+		return state.AvgFillPrice
+	}
+	return cost / float64(missing)
+}
@@ -0,0 +1,177 @@
+package broker
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Rajchodisetti/trading-app/internal/clock"
+	"github.com/Rajchodisetti/trading-app/internal/outbox"
+)
+
+func TestReconcile_ResolvesOpenOrders(t *testing.T) {
+	ob, err := outbox.New(filepath.Join(t.TempDir(), "outbox.jsonl"), 90)
+	require.NoError(t, err)
+	pb, now := newTestBroker(t)
+	ctx := context.Background()
+
+	// Sent and filled at the broker, but the process died before the fill was recorded
+	sent, err := pb.Submit(ctx, OrderRequest{Symbol: "AAPL", Side: "BUY", Quantity: 10, Intent: "BUY_1X"})
+	require.NoError(t, err)
+	require.NoError(t, ob.WriteOrder(outbox.Order{ID: sent.ID, Symbol: "AAPL", Side: "BUY", Quantity: 10, Status: string(StatusSent)}))
+	*now = now.Add(time.Second)
+	pb.Process(ctx)
+
+	// Unknown to the broker and too old to resubmit: legacy records carry no size
+	require.NoError(t, ob.WriteOrder(outbox.Order{ID: "stale", Symbol: "BIOX", Intent: "BUY_1X", Status: "pending"}))
+	// Already terminal: left alone
+	require.NoError(t, ob.WriteOrder(outbox.Order{ID: "done", Symbol: "BIOX", Side: "BUY", Quantity: 5, Status: string(StatusFilled)}))
+
+	report, err := Reconcile(ctx, ob, pb, *now, RecoveryPolicy{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Open)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, map[string]int{"FILLED": 1, "EXPIRED": 1}, report.Counts())
+	require.Len(t, report.Recovered, 1)
+	assert.Equal(t, 10.0, report.Recovered[0].Quantity)
+	assert.InDelta(t, 210.10, report.Recovered[0].Price, 1e-9)
+
+	// The compensating entries close everything out
+	open, err := ob.OpenOrders()
+	require.NoError(t, err)
+	assert.Empty(t, open)
+
+	// Nothing left to do on the next startup
+	report, err = Reconcile(ctx, ob, nil, *now, RecoveryPolicy{})
+	require.NoError(t, err)
+	assert.Zero(t, report.Open)
+}
+
+func TestReconcile_FilledInOutbox(t *testing.T) {
+	ob, err := outbox.New(filepath.Join(t.TempDir(), "outbox.jsonl"), 90)
+	require.NoError(t, err)
+
+	// Legacy order: fill recorded, final status never written
+	require.NoError(t, ob.WriteOrder(outbox.Order{ID: "legacy", Symbol: "AAPL", Intent: "BUY_1X", Status: "pending"}))
+	require.NoError(t, ob.WriteFill(outbox.Fill{OrderID: "legacy", Symbol: "AAPL", Quantity: 1, Price: 210, Side: "BUY"}))
+
+	report, err := Reconcile(context.Background(), ob, nil, time.Now(), RecoveryPolicy{})
+	require.NoError(t, err)
+	require.Len(t, report.Orders, 1)
+	assert.Equal(t, "outbox", report.Orders[0].Source)
+	assert.Equal(t, "FILLED", report.Orders[0].Status)
+	assert.Empty(t, report.Recovered, "fills already in the outbox are not replayed")
+}
+
+func TestReconcile_ResubmitsOpenStopAcrossRestart(t *testing.T) {
+	before, now := newTestBroker(t)
+	ob, err := outbox.NewWithOptions(filepath.Join(t.TempDir(), "outbox.jsonl"), 90, outbox.Options{Clock: clock.NewSim(*now)})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// A resting stop exit, 4 of its 10 shares filled before the process died
+	stop := outbox.Order{ID: "stop_AAPL_1", Symbol: "AAPL", Intent: "STOP", Side: "SELL", Quantity: 10,
+		OrderType: TypeLimit, LimitPrice: 215, TimeInForce: "DAY", Status: string(StatusNew)}
+	require.NoError(t, ob.WriteOrder(stop))
+	_, err = before.Submit(ctx, OrderRequest{ClientOrderID: stop.ID, Symbol: "AAPL", Side: "SELL", Type: TypeLimit, Quantity: 10, LimitPrice: 215, Intent: "STOP"})
+	require.NoError(t, err)
+	stop.Status = string(StatusPartial)
+	require.NoError(t, ob.WriteOrder(stop))
+	require.NoError(t, ob.WriteFill(outbox.Fill{OrderID: stop.ID, Symbol: "AAPL", Quantity: 4, Price: 215, Side: "SELL"}))
+
+	// The restarted paper broker starts empty
+	after, _ := newTestBroker(t)
+	report, err := Reconcile(ctx, ob, after, *now, RecoveryPolicy{})
+	require.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	require.Len(t, report.Orders, 1)
+	rec := report.Orders[0]
+	assert.Equal(t, "resubmitted", rec.Source)
+	assert.Equal(t, string(StatusSent), rec.Status)
+	assert.Equal(t, "stop_AAPL_1-r", rec.ResubmittedAs)
+
+	// The unfilled remainder is working again at the same limit
+	state, err := after.Status(ctx, rec.ResubmittedAs)
+	require.NoError(t, err)
+	assert.Equal(t, StatusSent, state.Status)
+	assert.Equal(t, 6, state.Quantity)
+	assert.Equal(t, "SELL", state.Side)
+	assert.Equal(t, TypeLimit, state.Type)
+	assert.Equal(t, 215.0, state.LimitPrice)
+	assert.Equal(t, "STOP", state.Intent)
+
+	// The outbox carries it on under the new order; the original is closed
+	open, err := ob.OpenOrders()
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, rec.ResubmittedAs, open[0].Order.ID)
+	assert.Equal(t, string(StatusSent), open[0].Order.Status)
+	assert.Equal(t, 6, open[0].Order.Quantity)
+
+	// Another restart against the same broker finds it working and submits nothing
+	report, err = Reconcile(ctx, ob, after, *now, RecoveryPolicy{})
+	require.NoError(t, err)
+	require.Len(t, report.Orders, 1)
+	assert.Equal(t, "broker", report.Orders[0].Source)
+	assert.Equal(t, string(StatusSent), report.Orders[0].Status)
+	assert.Empty(t, report.Orders[0].ResubmittedAs)
+}
+
+func TestReconcile_ExpiresLostOrdersPolicyRulesOut(t *testing.T) {
+	_, now := newTestBroker(t)
+	clk := clock.NewSim(now.Add(-24 * time.Hour))
+	ob, err := outbox.NewWithOptions(filepath.Join(t.TempDir(), "outbox.jsonl"), 90, outbox.Options{Clock: clk})
+	require.NoError(t, err)
+	lost := func(id, symbol string) {
+		require.NoError(t, ob.WriteOrder(outbox.Order{ID: id, Symbol: symbol, Intent: "BUY_1X", Side: "BUY", Quantity: 10,
+			OrderType: TypeLimit, LimitPrice: 210, TimeInForce: "DAY", Status: string(StatusSent)}))
+	}
+
+	// Yesterday's DAY order, one placed a minute ago, one the guard
+	// rejects, and one placed just before the restart
+	lost("yesterday", "AAPL")
+	clk.Set(now.Add(-time.Minute))
+	lost("aged", "AAPL")
+	clk.Set(now.Add(-time.Second))
+	lost("drifted", "NVDA")
+	lost("fresh", "AAPL")
+
+	var rechecked []string
+	after, _ := newTestBroker(t)
+	report, err := Reconcile(context.Background(), ob, after, *now, RecoveryPolicy{
+		MaxAge: 10 * time.Second,
+		Guard: func(_ context.Context, order outbox.Order, placedAt time.Time) (string, error) {
+			rechecked = append(rechecked, order.ID)
+			assert.Equal(t, now.Add(-time.Second), placedAt)
+			if order.Symbol == "NVDA" {
+				return "price_drift_3.00_pct_exceeds_2.00", nil
+			}
+			return "", nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, []string{"drifted", "fresh"}, rechecked)
+
+	reasons := map[string]string{}
+	open, err := ob.Replay()
+	require.NoError(t, err)
+	for id, h := range open {
+		reasons[id] = h.Order.Reason
+	}
+	assert.Equal(t, "session ended before startup recovery", reasons["yesterday"])
+	assert.Equal(t, "older than 10s at startup recovery", reasons["aged"])
+	assert.Equal(t, "rejected at startup recovery: price_drift_3.00_pct_exceeds_2.00", reasons["drifted"])
+	assert.Equal(t, "resubmitted as fresh-r at startup recovery", reasons["fresh"])
+
+	_, err = after.Status(context.Background(), "fresh-r")
+	assert.NoError(t, err)
+	for _, id := range []string{"yesterday-r", "aged-r", "drifted-r"} {
+		_, err := after.Status(context.Background(), id)
+		assert.ErrorIs(t, err, ErrUnknownOrder, id)
+	}
+}
@@ -0,0 +1,63 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the time source for anything that compares against "now".
+// Components default to Real; replays and tests inject a Sim.
+type Clock interface {
+	Now() time.Time
+}
+
+// Real reads the wall clock
+type Real struct{}
+
+func (Real) Now() time.Time { return time.Now() }
+
+// Sim is a clock that only moves when told to, so hours of market time can
+// be replayed in milliseconds with the same results every run
+type Sim struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewSim creates a simulated clock stopped at start
+func NewSim(start time.Time) *Sim {
+	return &Sim{now: start}
+}
+
+func (s *Sim) Now() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.now
+}
+
+// Set moves the clock to t. Moving backwards is ignored so event time
+// never runs in reverse when inputs arrive out of order.
+func (s *Sim) Set(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.After(s.now) {
+		s.now = t
+	}
+}
+
+// Advance moves the clock forward by d
+func (s *Sim) Advance(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+}
+
+// Or returns c, or Real when c is nil
+func Or(c Clock) Clock {
+	if c == nil {
+		return Real{}
+	}
+	return c
+}
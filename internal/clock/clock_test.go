@@ -0,0 +1,29 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSim(t *testing.T) {
+	start := time.Date(2025, 3, 3, 14, 30, 0, 0, time.UTC)
+	c := NewSim(start)
+	if !c.Now().Equal(start) {
+		t.Fatalf("want %v, got %v", start, c.Now())
+	}
+
+	c.Advance(90 * time.Minute)
+	if want := start.Add(90 * time.Minute); !c.Now().Equal(want) {
+		t.Fatalf("want %v, got %v", want, c.Now())
+	}
+
+	// Event time never runs backwards
+	c.Set(start)
+	if !c.Now().Equal(start.Add(90 * time.Minute)) {
+		t.Fatalf("clock moved backwards to %v", c.Now())
+	}
+	c.Set(start.Add(2 * time.Hour))
+	if !c.Now().Equal(start.Add(2 * time.Hour)) {
+		t.Fatalf("set forward ignored: %v", c.Now())
+	}
+}
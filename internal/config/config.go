@@ -9,6 +9,8 @@ import (
 type Thresholds struct {
 	Positive float64 `yaml:"positive"`
 	VeryPos  float64 `yaml:"very_positive"`
+	Negative float64 `yaml:"negative"`      // e.g. -0.35 → REDUCE an open position; 0 disables
+	VeryNeg  float64 `yaml:"very_negative"` // e.g. -0.65 → EXIT an open position; 0 disables
 }
 
 type Session struct {
@@ -40,6 +42,27 @@ type Paper struct {
 	SlippageBpsMin     int    `yaml:"slippage_bps_min"`
 	SlippageBpsMax     int    `yaml:"slippage_bps_max"`
 	DedupeWindowSecs   int    `yaml:"dedupe_window_seconds"`
+	ParticipationRate  float64 `yaml:"participation_rate"`    // share of interval volume per fill slice; 0 = fill in one shot
+	PartialMinQty      int     `yaml:"partial_fill_min_qty"`  // orders up to this size always fill at once
+	PartialIntervalMs  int     `yaml:"partial_fill_interval_ms"`
+	OutboxSegmentMB    int     `yaml:"outbox_segment_mb"`    // rotate the outbox at this size; 0 = no size limit
+	OutboxRotateDaily  bool    `yaml:"outbox_rotate_daily"`  // rotate the outbox on each new UTC day
+	OutboxCompress     bool    `yaml:"outbox_compress"`      // gzip rotated outbox segments
+	Fees               Fees    `yaml:"fees"`
+}
+
+// Fees is the commission, regulatory and borrow fee schedule charged on paper trades.
+// Fields mirror broker.FeeSchedule so one converts directly to the other.
+type Fees struct {
+	CommissionPerShare    float64 `yaml:"commission_per_share"`
+	CommissionBps         float64 `yaml:"commission_bps"`
+	CommissionMinPerOrder float64 `yaml:"commission_min_per_order"`
+	SECFeePerMillion      float64 `yaml:"sec_fee_per_million"` // USD per $1M sold
+	TAFPerShare           float64 `yaml:"taf_per_share"`       // FINRA TAF per share sold
+	TAFMaxPerTrade        float64 `yaml:"taf_max_per_trade"`
+
+	BorrowBpsPerYear float64            `yaml:"borrow_bps_per_year"` // borrow on short market value
+	BorrowSymbolBps  map[string]float64 `yaml:"borrow_symbol_bps"`   // hard-to-borrow overrides
 }
 
 type Wire struct {
@@ -102,6 +125,17 @@ type Portfolio struct {
 	MaxDailyExposureIncreasePct float64 `yaml:"max_daily_exposure_increase_pct"`
 	ResetDailyLimitsAtHour      int     `yaml:"reset_daily_limits_at_hour"`
 	PositionDecayDays           int     `yaml:"position_decay_days"`
+	LotMethod                   string  `yaml:"lot_method"` // fifo | lifo | highest_cost
+	MaxClosedLots               int     `yaml:"max_closed_lots"` // newest closed lots kept in the snapshot
+	MaxGrossExposurePct         float64 `yaml:"max_gross_exposure_pct"` // longs plus shorts, % of capital; 0 = no limit
+	MaxNetExposurePct           float64 `yaml:"max_net_exposure_pct"`   // longs minus shorts, either way; 0 = no limit
+}
+
+// Short enables short selling on negative news
+type Short struct {
+	Enabled     bool    `yaml:"enabled"`
+	EntryScore  float64 `yaml:"entry_score"`   // sell short at or below this fused score
+	ETBListPath string  `yaml:"etb_list_path"` // easy-to-borrow symbols, one per line
 }
 
 type StopLoss struct {
@@ -110,6 +144,7 @@ type StopLoss struct {
 	EmergencyStopLossPct  float64 `yaml:"emergency_stop_loss_pct"`
 	AllowAfterHours       bool    `yaml:"allow_after_hours"`
 	CooldownHours         int     `yaml:"cooldown_hours"`
+	EntryReference        string  `yaml:"entry_reference"` // vwap | latest_lot
 }
 
 type SectorLimits struct {
@@ -133,6 +168,159 @@ type RiskControls struct {
 	Drawdown      Drawdown      `yaml:"drawdown"`
 }
 
+// StrategyConfig enables and tunes one registered advice strategy
+type StrategyConfig struct {
+	Name    string              `yaml:"name"`
+	Enabled bool                `yaml:"enabled"`
+	Params  map[string]float64  `yaml:"params"` // numeric knobs, strategy-specific
+	Words   map[string][]string `yaml:"words"`  // named word lists, e.g. lexicon positive/negative
+}
+
+// Fusion selects how strategy advice is combined
+type Fusion struct {
+	Method  string             `yaml:"method"`  // weighted_sum | confidence_mean | max_abs
+	Weights map[string]float64 `yaml:"weights"` // strategy name -> weight (default 1.0)
+}
+
+// Decay controls advice expiry and age-based score decay
+type Decay struct {
+	HalfLifeSeconds   int `yaml:"half_life_seconds"`   // 0 disables decay
+	DefaultTTLSeconds int `yaml:"default_ttl_seconds"` // 0 means advice never expires
+}
+
+// Sizing converts decision notionals into share quantities
+type Sizing struct {
+	LotSize        int     `yaml:"lot_size"`
+	MinNotionalUSD float64 `yaml:"min_notional_usd"`
+}
+
+// Execution chooses order types and price protection for decision orders
+type Execution struct {
+	OrderType      string          `yaml:"order_type"`       // MKT | LMT | IOC | MARKETABLE_LIMIT
+	LimitOffsetBps float64         `yaml:"limit_offset_bps"` // IOC/marketable-limit reach through the far touch
+	Protection     PriceProtection `yaml:"protection"`
+}
+
+// PriceProtection sends thin-market entries as IOC limits near the mid
+type PriceProtection struct {
+	AfterHours     bool    `yaml:"after_hours"`      // protect every premarket/postmarket entry
+	SpreadBps      float64 `yaml:"spread_bps"`       // protect entries at or above this spread; 0 disables
+	MaxSlippageBps float64 `yaml:"max_slippage_bps"` // protected limit = mid + this
+}
+
+// Durability controls fsync for the outbox and portfolio snapshots
+type Durability struct {
+	Fsync           string `yaml:"fsync"`             // always | batched | none
+	BatchRecords    int    `yaml:"batch_records"`     // batched: sync after this many writes
+	BatchIntervalMs int    `yaml:"batch_interval_ms"` // batched: sync at least this often
+}
+
+// CapsCooldownFile is the separate caps/cooldown file (config/caps_cooldown.yaml)
+type CapsCooldownFile struct {
+	CapsCooldown   CapsCooldown   `yaml:"caps_cooldown"`
+	RiskMitigation RiskMitigation `yaml:"risk_mitigation"`
+	Integration    Integration    `yaml:"integration"`
+}
+
+// Integration places the caps and cooldown gates: their priorities in the
+// decision gate pipeline, and which risk manager gates block or soft-convert
+type Integration struct {
+	GatePriorityCaps     int      `yaml:"gate_priority_caps"`
+	GatePriorityCooldown int      `yaml:"gate_priority_cooldown"`
+	SoftGatesEnabled     bool     `yaml:"soft_gates_enabled"`    // false makes every risk manager gate hard
+	HardBlockGates       []string `yaml:"hard_block_gates"`      // never converted to HOLD
+	SoftConversionGates  []string `yaml:"soft_conversion_gates"` // convert BUY to HOLD instead of blocking
+}
+
+// CapsCooldown holds per-symbol position caps
+type CapsCooldown struct {
+	Enforce              bool               `yaml:"enforce"`
+	DefaultSymbolCapUSD  float64            `yaml:"default_symbol_cap_usd"`
+	MaxSingleSymbolPct   float64            `yaml:"max_single_symbol_pct"`
+	PortfolioCapsEnabled bool               `yaml:"portfolio_caps_enabled"`
+	DailyTradeLimit      int                `yaml:"daily_trade_limit"`
+	RTHOpenHour          int                `yaml:"rth_open_hour"`
+	RTHOpenMinute        int                `yaml:"rth_open_minute"`
+	SymbolSpecificCaps   map[string]float64 `yaml:"symbol_specific_caps"`
+	PersistPath          string             `yaml:"persist_path"`
+	Cooldown             Cooldown           `yaml:"cooldown"`
+}
+
+// Cooldown spaces out trades per symbol and globally
+type Cooldown struct {
+	Enforce               bool           `yaml:"enforce"`
+	DefaultCooldownSec    int            `yaml:"default_cooldown_sec"`
+	GlobalCooldownSec     int            `yaml:"global_cooldown_sec"`
+	SameSideCooldownSec   int            `yaml:"same_side_cooldown_sec"`
+	IntentCooldowns       map[string]int `yaml:"intent_cooldowns"`
+	SymbolCooldowns       map[string]int `yaml:"symbol_cooldowns"`
+	OppositeTradesAllowed bool           `yaml:"opposite_trades_allowed"`
+	VolatilityAdjustments bool           `yaml:"volatility_adjustments"`
+	PersistPath           string         `yaml:"persist_path"`
+}
+
+// RiskManager runs NAV tracking, the graduated circuit breaker and the
+// caps/cooldown soft gates over every actionable decision
+type RiskManager struct {
+	Enabled                   bool   `yaml:"enabled"`
+	EventLogPath              string `yaml:"event_log_path"`
+	NAVStatePath              string `yaml:"nav_state_path"`
+	UpdateIntervalSeconds     int    `yaml:"update_interval_seconds"`
+	QuoteStalenessThresholdMs int    `yaml:"quote_staleness_threshold_ms"`
+}
+
+// MarkToMarket prices open positions from quotes on a schedule
+type MarkToMarket struct {
+	Enabled         bool    `yaml:"enabled"`
+	IntervalSeconds int     `yaml:"interval_seconds"`
+	PriceSource     string  `yaml:"price_source"`   // mid | last
+	StaleAfterMs    int     `yaml:"stale_after_ms"` // older quotes keep the previous mark
+	MaxSpreadBps    float64 `yaml:"max_spread_bps"` // wider books mark at last instead of mid; 0 disables
+}
+
+// CorporateActions are applied to held positions once they take effect
+type CorporateActions struct {
+	Enabled         bool              `yaml:"enabled"`
+	IntervalSeconds int               `yaml:"interval_seconds"` // how often due actions are checked
+	Actions         []CorporateAction `yaml:"actions"`
+}
+
+// CorporateAction is one split, rename, delisting or acquisition
+type CorporateAction struct {
+	Symbol        string      `yaml:"symbol"`
+	NewSymbol     string      `yaml:"new_symbol"`     // rename
+	ActionType    string      `yaml:"action_type"`    // split | reverse_split | rename | delisting | acquisition
+	EffectiveDate string      `yaml:"effective_date"` // RFC3339
+	Ratio         *SplitRatio `yaml:"ratio"`          // split | reverse_split
+}
+
+// SplitRatio turns From old shares into To new ones
+type SplitRatio struct {
+	From int `yaml:"from"`
+	To   int `yaml:"to"`
+}
+
+// RiskMitigation holds fail-safe limits, including the pre-send outbox guard
+type RiskMitigation struct {
+	EmergencySymbolCapUSD   float64 `yaml:"emergency_symbol_cap_usd"`
+	EmergencyCooldownSec    int     `yaml:"emergency_cooldown_sec"`
+	CapGracePeriodSec       int     `yaml:"cap_grace_period_sec"`
+	MaxPriceDriftPct        float64 `yaml:"max_price_drift_pct"`        // cancel orders whose mid moved further since the decision
+	MaxDecisionStalenessSec float64 `yaml:"max_decision_staleness_sec"` // cancel orders sent later than this after the decision
+}
+
+// Gates reorders and reclassifies the decision gate pipeline by gate name
+type Gates struct {
+	Priorities map[string]int `yaml:"priorities"` // lower runs first; unset gates keep their default
+	Hard       []string       `yaml:"hard"`       // REJECT the decision
+	Soft       []string       `yaml:"soft"`       // turn the decision into a HOLD
+}
+
+// Adapters selects implementations for external seams
+type Adapters struct {
+	Broker string `yaml:"BROKER"` // paper | alpaca | ibkr
+}
+
 type Monitoring struct {
 	DashboardRecentTrades      int `yaml:"dashboard_recent_trades"`
 	HealthCheckIntervalMinutes int `yaml:"health_check_interval_minutes"`
@@ -177,6 +365,7 @@ type Root struct {
 	TradingMode       string            `yaml:"trading_mode"` // paper | live | dry-run
 	GlobalPause       bool              `yaml:"global_pause"`
 	Thresholds        Thresholds        `yaml:"thresholds"`
+	Short             Short             `yaml:"short"`
 	Session           Session           `yaml:"session"`
 	Liquidity         Liquidity         `yaml:"liquidity"`
 	Corroboration     Corroboration     `yaml:"corroboration"`
@@ -190,6 +379,17 @@ type Root struct {
 	Portfolio         Portfolio         `yaml:"portfolio"`
 	RiskControls      RiskControls      `yaml:"risk_controls"`
 	Monitoring        Monitoring        `yaml:"monitoring"`
+	Strategies        []StrategyConfig  `yaml:"strategies"`
+	Fusion            Fusion            `yaml:"fusion"`
+	Decay             Decay             `yaml:"decay"`
+	Sizing            Sizing            `yaml:"sizing"`
+	Execution         Execution         `yaml:"execution"`
+	Durability        Durability        `yaml:"durability"`
+	RiskManager       RiskManager       `yaml:"risk_manager"`
+	MarkToMarket      MarkToMarket      `yaml:"mark_to_market"`
+	CorporateActions  CorporateActions  `yaml:"corporate_actions"`
+	Gates             Gates             `yaml:"gates"`
+	Adapters          Adapters          `yaml:"adapters"`
 	BaseUSD           float64           `yaml:"base_usd"`
 }
 
@@ -268,6 +468,77 @@ func Load(path string) (Root, error) {
 		c.RuntimeOverrides.ExpiryHoursDefault = 24
 	}
 	
+	// Default strategies reproduce the legacy fixed source scores
+	if len(c.Strategies) == 0 {
+		c.Strategies = []StrategyConfig{
+			{Name: "source_prior", Enabled: true},
+			{Name: "trend_lite", Enabled: true},
+		}
+	}
+	
+	if c.Adapters.Broker == "" {
+		c.Adapters.Broker = "paper"
+	}
+	
+	if c.Short.EntryScore == 0 {
+		c.Short.EntryScore = -0.65
+	}
+	if c.Short.ETBListPath == "" {
+		c.Short.ETBListPath = "config/etb_list.txt"
+	}
+	if c.Portfolio.LotMethod == "" {
+		c.Portfolio.LotMethod = "fifo"
+	}
+	if c.Portfolio.MaxClosedLots == 0 {
+		c.Portfolio.MaxClosedLots = 1000
+	}
+	if c.RiskControls.StopLoss.EntryReference == "" {
+		c.RiskControls.StopLoss.EntryReference = "vwap"
+	}
+	
+	if c.Sizing.LotSize == 0 {
+		c.Sizing.LotSize = 1
+	}
+	
+	if c.Execution.OrderType == "" {
+		c.Execution.OrderType = "MKT"
+	}
+	
+	if c.Durability.Fsync == "" {
+		c.Durability.Fsync = "always"
+	}
+	
+	if c.RiskManager.EventLogPath == "" {
+		c.RiskManager.EventLogPath = "data/risk_events.jsonl"
+	}
+	if c.RiskManager.NAVStatePath == "" {
+		c.RiskManager.NAVStatePath = "data/nav_state.json"
+	}
+	if c.RiskManager.UpdateIntervalSeconds == 0 {
+		c.RiskManager.UpdateIntervalSeconds = 1
+	}
+	if c.RiskManager.QuoteStalenessThresholdMs == 0 {
+		c.RiskManager.QuoteStalenessThresholdMs = 2000
+	}
+	
+	if c.MarkToMarket.IntervalSeconds == 0 {
+		c.MarkToMarket.IntervalSeconds = 5
+	}
+	if c.MarkToMarket.PriceSource == "" {
+		c.MarkToMarket.PriceSource = "mid"
+	}
+	if c.MarkToMarket.StaleAfterMs == 0 {
+		c.MarkToMarket.StaleAfterMs = 2000
+	}
+	
+	if c.CorporateActions.IntervalSeconds == 0 {
+		c.CorporateActions.IntervalSeconds = 60
+	}
+	
+	if c.Fusion.Method == "" {
+		c.Fusion.Method = "weighted_sum"
+	}
+	
 	// Set security defaults
 	if c.Security.SlackSigningSecretEnv == "" {
 		c.Security.SlackSigningSecretEnv = "SLACK_SIGNING_SECRET"
@@ -275,3 +546,38 @@ func Load(path string) (Root, error) {
 	
 	return c, nil
 }
+
+// LoadCapsCooldown reads the caps/cooldown file; a missing file yields the defaults
+func LoadCapsCooldown(path string) (CapsCooldownFile, error) {
+	c := CapsCooldownFile{Integration: Integration{
+		SoftGatesEnabled:    true,
+		SoftConversionGates: []string{"caps", "cooldown"},
+	}}
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return c, err
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(b, &c); err != nil {
+			return c, err
+		}
+	}
+	
+	if c.CapsCooldown.DefaultSymbolCapUSD == 0 {
+		c.CapsCooldown.DefaultSymbolCapUSD = 50000
+	}
+	if c.CapsCooldown.MaxSingleSymbolPct == 0 {
+		c.CapsCooldown.MaxSingleSymbolPct = 20
+	}
+	if c.CapsCooldown.DailyTradeLimit == 0 {
+		c.CapsCooldown.DailyTradeLimit = 5
+	}
+	if c.RiskMitigation.MaxPriceDriftPct == 0 {
+		c.RiskMitigation.MaxPriceDriftPct = 2.0
+	}
+	if c.RiskMitigation.MaxDecisionStalenessSec == 0 {
+		c.RiskMitigation.MaxDecisionStalenessSec = 10
+	}
+	
+	return c, nil
+}
@@ -0,0 +1,86 @@
+package decision
+
+import (
+	"math"
+	"time"
+)
+
+// DecayConfig controls how advice ages
+type DecayConfig struct {
+	HalfLifeSeconds   int // exponential decay half-life on PublishedAt; 0 disables decay
+	DefaultTTLSeconds int // TTL for advice that doesn't set its own; 0 means no expiry
+}
+
+// AdviceNote identifies an advice item that was retracted, dropped or decayed
+type AdviceNote struct {
+	Strategy    string  `json:"strategy,omitempty"`
+	Provider    string  `json:"provider,omitempty"`
+	SourceID    string  `json:"source_id,omitempty"`
+	AgeSeconds  float64 `json:"age_s"`
+	TTLSeconds  int     `json:"ttl_s,omitempty"`
+	Factor      float64 `json:"factor,omitempty"` // decay multiplier applied to score
+	RetractedBy string  `json:"retracted_by,omitempty"`
+}
+
+func newAdviceNote(a Advice, age time.Duration) AdviceNote {
+	return AdviceNote{
+		Strategy:   a.Strategy,
+		Provider:   a.Provider,
+		SourceID:   a.SourceID,
+		AgeSeconds: math.Round(age.Seconds()*1000) / 1000,
+	}
+}
+
+// dropRetracted removes advice whose source story was superseded by a correction
+func dropRetracted(symbol string, advs []Advice, now time.Time, metrics Metrics) (kept []Advice, retracted []AdviceNote) {
+	kept = make([]Advice, 0, len(advs))
+	for _, a := range advs {
+		if a.RetractedBy == "" {
+			kept = append(kept, a)
+			continue
+		}
+		age := now.Sub(a.PublishedAt)
+		if age < 0 || a.PublishedAt.IsZero() {
+			age = 0
+		}
+		note := newAdviceNote(a, age)
+		note.RetractedBy = a.RetractedBy
+		retracted = append(retracted, note)
+		metrics.IncCounter("advice_retracted_total", map[string]string{"symbol": symbol, "strategy": a.Strategy})
+	}
+	return kept, retracted
+}
+
+// ageAdvice drops advice past its TTL and scales the score of the rest by
+// 0.5^(age/half-life). Advice published in the future is treated as fresh.
+func ageAdvice(symbol string, advs []Advice, cfg DecayConfig, now time.Time, metrics Metrics) (kept []Advice, dropped, decayed []AdviceNote) {
+	kept = make([]Advice, 0, len(advs))
+	for _, a := range advs {
+		age := now.Sub(a.PublishedAt)
+		if age < 0 || a.PublishedAt.IsZero() {
+			age = 0
+		}
+
+		ttl := a.TTLSeconds
+		if ttl <= 0 {
+			ttl = cfg.DefaultTTLSeconds
+		}
+		if !notExpired(a.PublishedAt, ttl, now) {
+			note := newAdviceNote(a, age)
+			note.TTLSeconds = ttl
+			dropped = append(dropped, note)
+			metrics.IncCounter("advice_expired_total", map[string]string{"symbol": symbol, "strategy": a.Strategy})
+			continue
+		}
+
+		if cfg.HalfLifeSeconds > 0 && age > 0 {
+			factor := math.Pow(0.5, age.Seconds()/float64(cfg.HalfLifeSeconds))
+			a.Score *= factor
+			note := newAdviceNote(a, age)
+			note.Factor = math.Round(factor*10000) / 10000
+			decayed = append(decayed, note)
+		}
+		kept = append(kept, a)
+	}
+	return kept, dropped, decayed
+}
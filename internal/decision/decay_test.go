@@ -0,0 +1,49 @@
+package decision
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgeAdvice_DropsExpiredAndDecays(t *testing.T) {
+	now := time.Date(2025, 8, 25, 15, 0, 0, 0, time.UTC)
+	advs := []Advice{
+		{Score: 0.8, Strategy: "source_prior", SourceID: "old", PublishedAt: now.Add(-2 * time.Hour), TTLSeconds: 3600},
+		{Score: 0.8, Strategy: "source_prior", SourceID: "half", PublishedAt: now.Add(-30 * time.Minute)},
+		{Score: 0.6, Strategy: "trend_lite", PublishedAt: now},
+	}
+
+	kept, dropped, decayed := ageAdvice("BIOX", advs, DecayConfig{HalfLifeSeconds: 1800}, now, observMetrics{})
+	if len(kept) != 2 || len(dropped) != 1 || dropped[0].SourceID != "old" {
+		t.Fatalf("want old dropped; kept=%v dropped=%v", kept, dropped)
+	}
+	if !approx(kept[0].Score, 0.4) || kept[1].Score != 0.6 {
+		t.Fatalf("unexpected decayed scores: %v", kept)
+	}
+	if len(decayed) != 1 || decayed[0].SourceID != "half" || decayed[0].Factor != 0.5 {
+		t.Fatalf("unexpected decay notes: %v", decayed)
+	}
+
+	// Default TTL applies to advice without its own; no decay when half-life is 0
+	kept, dropped, decayed = ageAdvice("BIOX", advs, DecayConfig{DefaultTTLSeconds: 600}, now, observMetrics{})
+	if len(kept) != 1 || len(dropped) != 2 || len(decayed) != 0 {
+		t.Fatalf("default TTL: kept=%v dropped=%v decayed=%v", kept, dropped, decayed)
+	}
+}
+
+func TestEvaluate_RetractedAdviceCannotDriveBuy(t *testing.T) {
+	cfg := Config{Positive: 0.35, VeryPos: 0.65, BaseUSD: 2000}
+	advs := []Advice{
+		{Symbol: "MEGA", Score: 0.6, Confidence: 0.8, SourceWeight: 1, Strategy: "source_prior",
+			SourceID: "reuters-010", RetractedBy: "reuters-010-corr", PublishedAt: time.Now()},
+	}
+	riskState := RiskState{MaxSpreadBps: 30}
+
+	act := Evaluate("MEGA", advs, Features{Symbol: "MEGA"}, riskState, cfg, nil, nil, nil, nil, nil)
+	if act.Intent != "HOLD" {
+		t.Fatalf("want HOLD, got %s", act.Intent)
+	}
+	if !contains(act.ReasonJSON, `"retracted_by":"reuters-010-corr"`) {
+		t.Fatalf("reason missing retraction: %s", act.ReasonJSON)
+	}
+}
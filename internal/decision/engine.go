@@ -1,11 +1,13 @@
 package decision
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"math"
+	"sync"
 	"time"
 	
+	"github.com/Rajchodisetti/trading-app/internal/clock"
 	"github.com/Rajchodisetti/trading-app/internal/observ"
 	"github.com/Rajchodisetti/trading-app/internal/portfolio"
 	"github.com/Rajchodisetti/trading-app/internal/risk"
@@ -20,6 +22,10 @@ type Advice struct {
 	Provider     string  // e.g., "businesswire", "reuters"
 	IsPR         bool    // true if this is a press release
 	PublishedAt  time.Time // event time for corroboration window
+	Strategy     string  // producing strategy, e.g., "lexicon_sentiment"
+	Version      string  // strategy version, e.g., "1.0.0"
+	SourceID     string  // originating news id, if any
+	RetractedBy  string  // id of the correction that superseded the source, if any
 }
 
 type Features struct {
@@ -31,6 +37,9 @@ type Features struct {
 	Premarket  bool
 	Postmarket bool
 	SpreadBps  float64
+	Bid        float64 // 0 when the feed carries no quote
+	Ask        float64
+	SSRActive  bool    // short sale restriction in force: no new shorts
 }
 
 type RiskState struct {
@@ -44,11 +53,17 @@ type RiskState struct {
 type Config struct {
 	Positive        float64 // e.g., 0.35
 	VeryPos         float64 // e.g., 0.65
+	Negative        float64 // e.g., -0.35; 0 disables REDUCE
+	VeryNeg         float64 // e.g., -0.65; 0 disables EXIT
 	BaseUSD         float64 // e.g., 2000
+	Short           ShortConfig
 	Corroboration   CorroborationConfig
 	EarningsEmbargo EarningsEmbargoConfig
 	Portfolio       PortfolioConfig
 	RiskControls    RiskControlsConfig
+	Fusion          FusionConfig
+	Decay           DecayConfig
+	Gates           GateConfig
 }
 
 type RiskControlsConfig struct {
@@ -64,6 +79,13 @@ type PortfolioConfig struct {
 	DailyTradeLimitPerSymbol    int
 	CooldownMinutesPerSymbol    int
 	MaxDailyExposureIncreasePct float64
+	MaxGrossExposurePct         float64 // longs plus shorts; 0 disables
+	MaxNetExposurePct           float64 // longs minus shorts, either way; 0 disables
+}
+
+type ShortConfig struct {
+	Enabled    bool
+	EntryScore float64 // e.g., -0.65; SELL_SHORT at or below this fused score
 }
 
 type CorroborationConfig struct {
@@ -88,12 +110,28 @@ type EarningsEvent struct {
 type Reason struct {
 	FusedScore      float64                 `json:"fused_score"`
 	PerStrategy     map[string]float64      `json:"per_strategy"`
+	FusionMethod    string                  `json:"fusion_method,omitempty"`
 	GatesPassed     []string                `json:"gates_passed"`
 	GatesBlocked    []string                `json:"gates_blocked"`
 	Policy          string                  `json:"policy"`
 	WhatWouldChange string                  `json:"what_would_change_it,omitempty"`
 	Corroboration   *CorroborationState     `json:"corroboration,omitempty"`
 	EarningsEmbargo *EarningsEmbargoState   `json:"earnings_embargo,omitempty"`
+	RetractedAdvice []AdviceNote            `json:"retracted_advice,omitempty"`
+	DroppedAdvice   []AdviceNote            `json:"dropped_advice,omitempty"`
+	DecayedAdvice   []AdviceNote            `json:"decayed_advice,omitempty"`
+	RiskManager     *RiskManagerState       `json:"risk_manager,omitempty"`
+	Gates           []GateVerdict           `json:"gates,omitempty"`
+}
+
+// blockedBy reports whether the named gate blocked the decision
+func (r Reason) blockedBy(gate string) bool {
+	for _, v := range r.Gates {
+		if v.Gate == gate && !v.Passed {
+			return true
+		}
+	}
+	return false
 }
 
 type CorroborationState struct {
@@ -111,69 +149,173 @@ type EarningsEmbargoState struct {
 
 type ProposedAction struct {
 	Symbol         string
-	Intent         string // BUY_1X | BUY_5X | REDUCE | HOLD | REJECT
+	Intent         string // BUY_1X | BUY_5X | SELL_SHORT | REDUCE | EXIT | COVER | HOLD | REJECT
 	BaseAmountUSD  float64
 	ScaledNotional float64
 	ReasonJSON     string
 }
 
-// Fuse: super simple weighted sum with tanh squash
-func fuse(advs []Advice) (float64, map[string]float64) {
-	per := map[string]float64{}
-	sum := 0.0
-	for _, a := range advs {
-		w := a.Confidence
-		if w <= 0 {
-			w = 0.5
-		}
-		w *= a.SourceWeight
-		contrib := a.Score * w
-		per[a.Symbol] += contrib // here we use Symbol as a stand-in for strategy label in this tiny demo
-		sum += contrib
-	}
-	// squash to [-1..1]
-	fs := math.Tanh(sum)
-	return fs, per
-}
-
-// fuseWithoutPR: fuse advice excluding PR contributions (for window expiry scenario)
-func fuseWithoutPR(advs []Advice) (float64, map[string]float64) {
-	per := map[string]float64{}
-	sum := 0.0
-	for _, a := range advs {
-		if a.IsPR {
-			continue // exclude PR advice
-		}
-		w := a.Confidence
-		if w <= 0 {
-			w = 0.5
-		}
-		w *= a.SourceWeight
-		contrib := a.Score * w
-		per[a.Symbol] += contrib
-		sum += contrib
+// Validate reports config the engine cannot run with
+func (c Config) Validate() error {
+	if !ValidFusionMethod(c.Fusion.Method) {
+		return fmt.Errorf("unknown fusion method %q", c.Fusion.Method)
 	}
-	// squash to [-1..1]
-	fs := math.Tanh(sum)
-	return fs, per
+	if c.Short.Enabled && c.Short.EntryScore >= 0 {
+		return fmt.Errorf("short entry score must be negative, got %g", c.Short.EntryScore)
+	}
+	return c.Gates.Validate()
+}
+
+// Metrics receives the engine's counters
+type Metrics interface {
+	IncCounter(name string, labels map[string]string)
+}
+
+// observMetrics sends counters to the process-wide observ registry
+type observMetrics struct{}
+
+func (observMetrics) IncCounter(name string, labels map[string]string) {
+	observ.IncCounter(name, labels)
+}
+
+// Inputs is the per-call market and risk state for one symbol
+type Inputs struct {
+	Advice   []Advice
+	Features Features
+	Risk     RiskState
+	Earnings []EarningsEvent
+}
+
+// Engine evaluates decisions against its config and the risk collaborators
+// it was built with. Collaborators left unset skip their gates.
+type Engine struct {
+	mu  sync.RWMutex
+	cfg Config
+
+	portfolio *portfolio.Manager
+	stopLoss  *risk.StopLossManager
+	sectors   *risk.SectorExposureManager
+	drawdown  *risk.DrawdownManager
+	borrow    *risk.BorrowList
+	clock     clock.Clock
+	metrics   Metrics
+}
+
+// Option configures an Engine
+type Option func(*Engine)
+
+// WithPortfolio enables the position-aware intents and portfolio gates
+func WithPortfolio(pm *portfolio.Manager) Option {
+	return func(e *Engine) { e.portfolio = pm }
+}
+
+// WithStopLoss enables the stop-loss cooldown gate
+func WithStopLoss(m *risk.StopLossManager) Option {
+	return func(e *Engine) { e.stopLoss = m }
+}
+
+// WithSectorExposure enables the sector limit gate
+func WithSectorExposure(m *risk.SectorExposureManager) Option {
+	return func(e *Engine) { e.sectors = m }
+}
+
+// WithDrawdown enables the drawdown gate
+func WithDrawdown(m *risk.DrawdownManager) Option {
+	return func(e *Engine) { e.drawdown = m }
+}
+
+// WithBorrowList sets the easy-to-borrow list short sales are checked
+// against; without one nothing is shorted
+func WithBorrowList(b *risk.BorrowList) Option {
+	return func(e *Engine) { e.borrow = b }
+}
+
+// WithClock sets the time source for advice age, corroboration windows,
+// earnings embargoes and cooldowns; the default is the wall clock
+func WithClock(c clock.Clock) Option {
+	return func(e *Engine) { e.clock = clock.Or(c) }
 }
 
-// Evaluate applies gates then threshold mapping.
-// For session #1 we only use GlobalPause and Halt gates + thresholds.
+// WithMetrics sets the counter sink; the default is the observ registry
+func WithMetrics(m Metrics) Option {
+	return func(e *Engine) { e.metrics = m }
+}
+
+// NewEngine builds an engine after validating cfg
+func NewEngine(cfg Config, opts ...Option) (*Engine, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return newEngine(cfg, opts...), nil
+}
+
+func newEngine(cfg Config, opts ...Option) *Engine {
+	e := &Engine{cfg: cfg, clock: clock.Real{}, metrics: observMetrics{}}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Config returns the config in effect
+func (e *Engine) Config() Config {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.cfg
+}
+
+// SetConfig swaps the config for subsequent evaluations. An invalid config is
+// rejected and the current one kept; evaluations in flight finish on the old one.
+func (e *Engine) SetConfig(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cfg = cfg
+	return nil
+}
+
+// Evaluate runs the gate pipeline (see gates.go) then threshold mapping for
+// one symbol. It fails only if ctx is already done.
+func (e *Engine) Evaluate(ctx context.Context, symbol string, in Inputs) (ProposedAction, error) {
+	if err := ctx.Err(); err != nil {
+		return ProposedAction{}, err
+	}
+	return e.evaluate(symbol, in, e.Config()), nil
+}
+
+// Evaluate is the one-shot form of Engine.Evaluate for callers that hold
+// their own collaborators; nil managers skip their gates.
 func Evaluate(symbol string, advs []Advice, feat Features, risk RiskState, cfg Config, earningsEvents []EarningsEvent, portfolioMgr *portfolio.Manager, stopLossMgr *risk.StopLossManager, sectorMgr *risk.SectorExposureManager, drawdownMgr *risk.DrawdownManager) ProposedAction {
-	now := time.Now()
+	e := newEngine(cfg, WithPortfolio(portfolioMgr), WithStopLoss(stopLossMgr), WithSectorExposure(sectorMgr), WithDrawdown(drawdownMgr))
+	act, _ := e.Evaluate(context.Background(), symbol, Inputs{Advice: advs, Features: feat, Risk: risk, Earnings: earningsEvents})
+	return act
+}
+
+func (e *Engine) evaluate(symbol string, in Inputs, cfg Config) ProposedAction {
+	advs, feat, risk, earningsEvents := in.Advice, in.Features, in.Risk, in.Earnings
+	portfolioMgr, stopLossMgr, sectorMgr, drawdownMgr := e.portfolio, e.stopLoss, e.sectors, e.drawdown
+	now := e.clock.Now()
+	
+	// Drop advice from retracted stories before anything else looks at it,
+	// so corroboration is computed only from live sources
+	advs, retracted := dropRetracted(symbol, advs, now, e.metrics)
+	
+	// Drop expired advice and decay the rest by age
+	advs, dropped, decayed := ageAdvice(symbol, advs, cfg.Decay, now, e.metrics)
 	
 	// Check corroboration requirements
 	needsCorroboration, corrobState := analyzeCorroboration(advs, cfg.Corroboration, now)
 	
 	// Track corroboration metrics
 	if corrobState != nil && corrobState.Required {
-		observ.IncCounter("corroboration_pending_total", map[string]string{"symbol": symbol})
+		e.metrics.IncCounter("corroboration_pending_total", map[string]string{"symbol": symbol})
 		
 		if len(corrobState.Missing) == 0 {
-			observ.IncCounter("corroboration_satisfied_total", map[string]string{"symbol": symbol})
+			e.metrics.IncCounter("corroboration_satisfied_total", map[string]string{"symbol": symbol})
 		} else if now.After(corrobState.Until) {
-			observ.IncCounter("corroboration_expired_total", map[string]string{"symbol": symbol})
+			e.metrics.IncCounter("corroboration_expired_total", map[string]string{"symbol": symbol})
 		}
 	}
 	
@@ -182,7 +324,7 @@ func Evaluate(symbol string, advs []Advice, feat Features, risk RiskState, cfg C
 	
 	// Track earnings embargo metrics
 	if earningsEmbargoActive && earningsState != nil {
-		observ.IncCounter("earnings_embargo_blocks_total", map[string]string{"symbol": symbol})
+		e.metrics.IncCounter("earnings_embargo_blocks_total", map[string]string{"symbol": symbol})
 	}
 	
 	var fused float64
@@ -205,190 +347,64 @@ func Evaluate(symbol string, advs []Advice, feat Features, risk RiskState, cfg C
 		// Handle different corroboration scenarios
 		if now.After(corrobState.Until) || hasLateCorroboration {
 			// Window expired or corroboration came too late, use non-PR advice only
-			fused, per = fuseWithoutPR(advs)
+			fused, per = fuseWithoutPR(advs, cfg.Fusion)
 		} else {
 			// Within window but pending corroboration
-			fused, per = fuse(advs)
+			fused, per = fuse(advs, cfg.Fusion)
 		}
 	} else {
 		// No corroboration needed or satisfied
-		fused, per = fuse(advs)
+		fused, per = fuse(advs, cfg.Fusion)
 	}
 
+	// Risk-reducing intents only apply when there is a position to reduce
+	var position portfolio.Position
+	if portfolioMgr != nil {
+		if pos, ok := portfolioMgr.GetPosition(symbol); ok {
+			position = pos
+		}
+	}
+	candidate := candidateIntent(fused, cfg, position.Quantity)
+	riskReducing := isRiskReducing(candidate)
+
 	reason := Reason{
 		FusedScore:      fused,
 		PerStrategy:     per,
+		FusionMethod:    cfg.Fusion.method(),
 		GatesPassed:     []string{},
 		GatesBlocked:    []string{},
-		Policy:          "positive>=0.35; very_positive>=0.65",
+		Policy:          policyString(cfg),
 		Corroboration:   corrobState,
 		EarningsEmbargo: earningsState,
-	}
-
-	// Collect all violated gates
-	if risk.GlobalPause {
-		reason.GatesBlocked = append(reason.GatesBlocked, "global_pause")
-	}
-	if feat.Halted {
-		reason.GatesBlocked = append(reason.GatesBlocked, "halt")
-	}
-
-	// Session gates - block pre/post market trading
-	if risk.BlockPremarket && feat.Premarket {
-		reason.GatesBlocked = append(reason.GatesBlocked, "session")
-	}
-	if risk.BlockPostmarket && feat.Postmarket {
-		reason.GatesBlocked = append(reason.GatesBlocked, "session")
-	}
-
-	// Liquidity gate - block wide spreads
-	if feat.SpreadBps > risk.MaxSpreadBps {
-		reason.GatesBlocked = append(reason.GatesBlocked, "liquidity")
-	}
-
-	// Frozen symbol gate - check if symbol is frozen
-	for _, frozen := range risk.FrozenSymbols {
-		if frozen == symbol {
-			reason.GatesBlocked = append(reason.GatesBlocked, "frozen")
-			break
-		}
-	}
-	
-	// Stop-loss cooldown gate
-	if stopLossMgr != nil && cfg.RiskControls.StopLoss.Enabled {
-		if stopLossMgr.IsInCooldown(symbol, now) {
-			reason.GatesBlocked = append(reason.GatesBlocked, "cooldown_stop")
-		}
-	}
-
-	// Portfolio gates - only check if portfolio management is enabled and trade would be BUY
-	if cfg.Portfolio.Enabled && portfolioMgr != nil && fused >= cfg.Positive {
-		// Caps gate - check position and portfolio exposure limits
-		pos, hasPosition := portfolioMgr.GetPosition(symbol)
-		
-		// Calculate new position value if this trade executes
-		var newPositionValue float64
-		if fused >= cfg.VeryPos {
-			newPositionValue = cfg.BaseUSD * 5
-		} else {
-			newPositionValue = cfg.BaseUSD
-		}
-		
-		// Check per-symbol position cap
-		if hasPosition {
-			currentPositionValue := abs(pos.CurrentNotional)
-			if currentPositionValue+newPositionValue > cfg.Portfolio.MaxPositionSizeUSD {
-				reason.GatesBlocked = append(reason.GatesBlocked, "caps")
-				observ.IncCounter("position_cap_violations_total", map[string]string{"symbol": symbol})
-			}
-		} else {
-			// New position
-			if newPositionValue > cfg.Portfolio.MaxPositionSizeUSD {
-				reason.GatesBlocked = append(reason.GatesBlocked, "caps")
-				observ.IncCounter("position_cap_violations_total", map[string]string{"symbol": symbol})
-			}
-		}
-		
-		// Check portfolio exposure cap
-		newExposurePct := ((portfolioMgr.GetExposureUSD() + newPositionValue) / (cfg.BaseUSD * 100)) * 100
-		if newExposurePct > cfg.Portfolio.MaxPortfolioExposurePct {
-			reason.GatesBlocked = append(reason.GatesBlocked, "caps")
-			observ.IncCounter("portfolio_exposure_violations_total", map[string]string{"symbol": symbol})
-		}
-		
-		// Cooldown gate - check minimum time between trades
-		if !portfolioMgr.CanTrade(symbol, cfg.Portfolio.CooldownMinutesPerSymbol) {
-			reason.GatesBlocked = append(reason.GatesBlocked, "cooldown")
-			observ.IncCounter("cooldown_gate_blocks_total", map[string]string{"symbol": symbol})
-		}
-		
-		// Daily trade limit gate
-		tradeCount := portfolioMgr.GetTradeCount(symbol)
-		if tradeCount >= cfg.Portfolio.DailyTradeLimitPerSymbol {
-			reason.GatesBlocked = append(reason.GatesBlocked, "caps")
-			observ.IncCounter("daily_limit_hits_total", map[string]string{"symbol": symbol})
-		}
-	}
-
-	// Corroboration soft gate - convert would-be BUY to HOLD
-	corroborationBlocked := false
-	if needsCorroboration && corrobState != nil && !now.After(corrobState.Until) && len(corrobState.Missing) > 0 {
-		// Only apply if this would be a BUY decision
-		if fused >= cfg.Positive {
-			reason.GatesBlocked = append(reason.GatesBlocked, "corroboration")
-			reason.WhatWouldChange = "editorial/regulatory confirmation before " + corrobState.Until.Format(time.RFC3339)
-			corroborationBlocked = true
-		}
-	}
-
-	// Earnings embargo soft gate - convert would-be BUY to HOLD
-	earningsBlocked := false
-	if earningsEmbargoActive && earningsState != nil {
-		// Only apply if this would be a BUY decision
-		if fused >= cfg.Positive {
-			reason.GatesBlocked = append(reason.GatesBlocked, "earnings_embargo")
-			reason.WhatWouldChange = "wait until " + earningsState.EndUTC.Format(time.RFC3339)
-			earningsBlocked = true
-		}
-	}
-
-	// Sector limits soft gate - check if BUY would exceed sector exposure
-	sectorBlocked := false
-	if sectorMgr != nil && cfg.RiskControls.SectorLimits.Enabled && portfolioMgr != nil && fused >= cfg.Positive {
-		var proposedNotional float64
-		if fused >= cfg.VeryPos {
-			proposedNotional = cfg.BaseUSD * 5
-		} else {
-			proposedNotional = cfg.BaseUSD
-		}
-		
-		nav := portfolioMgr.GetNAV()
-		positions := portfolioMgr.GetPositionNotionals()
-		
-		exceeds, sector := sectorMgr.CheckSectorLimit(symbol, proposedNotional, nav, positions, cfg.RiskControls.SectorLimits)
-		if exceeds {
-			reason.GatesBlocked = append(reason.GatesBlocked, "sector_limit")
-			reason.WhatWouldChange = "reduce " + sector + " sector exposure below " + fmt.Sprintf("%.1f%%", cfg.RiskControls.SectorLimits.MaxSectorExposurePct)
-			sectorBlocked = true
-		}
-	}
-
-	// Drawdown pause soft gate - check if drawdown should pause new buys
-	drawdownBlocked := false
-	if drawdownMgr != nil && cfg.RiskControls.Drawdown.Enabled {
-		var intent string
-		if fused >= cfg.VeryPos {
-			intent = "BUY_5X"
-		} else if fused >= cfg.Positive {
-			intent = "BUY_1X"
-		} else {
-			intent = "HOLD"
-		}
-		
-		blocked, gateName := drawdownMgr.CheckDrawdownGates(intent, cfg.RiskControls.Drawdown)
-		if blocked {
-			reason.GatesBlocked = append(reason.GatesBlocked, gateName)
-			reason.WhatWouldChange = "wait for drawdown to recover"
-			drawdownBlocked = true
-		}
-	}
-
-	// Hard gates (halt, session, liquidity, global_pause, frozen, caps, cooldown, cooldown_stop) -> REJECT
-	hardGates := []string{"global_pause", "halt", "session", "liquidity", "frozen", "caps", "cooldown", "cooldown_stop"}
-	hasHardGate := false
-	for _, gate := range reason.GatesBlocked {
-		for _, hardGate := range hardGates {
-			if gate == hardGate {
-				hasHardGate = true
-				break
-			}
-		}
-		if hasHardGate {
-			break
-		}
-	}
-
-	if hasHardGate {
+		RetractedAdvice: retracted,
+		DroppedAdvice:   dropped,
+		DecayedAdvice:   decayed,
+	}
+
+	// Run every registered gate in configured priority order
+	hardBlocked, softBlocked := runGates(&GateInput{
+		Symbol:             symbol,
+		Feat:               feat,
+		Risk:               risk,
+		Cfg:                cfg,
+		Now:                now,
+		Fused:              fused,
+		Candidate:          candidate,
+		RiskReducing:       riskReducing,
+		NeedsCorroboration: needsCorroboration,
+		Corroboration:      corrobState,
+		EarningsEmbargo:    earningsEmbargoActive,
+		Earnings:           earningsState,
+		Portfolio:          portfolioMgr,
+		StopLoss:           stopLossMgr,
+		Sectors:            sectorMgr,
+		Drawdown:           drawdownMgr,
+		Borrow:             e.borrow,
+		Metrics:            e.metrics,
+	}, &reason)
+
+	// Hard gates -> REJECT
+	if hardBlocked {
 		rj, _ := json.Marshal(reason)
 		return ProposedAction{Symbol: symbol, Intent: "REJECT", ReasonJSON: string(rj)}
 	}
@@ -399,33 +415,34 @@ func Evaluate(symbol string, advs []Advice, feat Features, risk RiskState, cfg C
 	intent := "HOLD"
 	usd := 0.0
 	
-	// If any soft gate is blocking, force HOLD regardless of score
-	if corroborationBlocked {
-		intent = "HOLD"
-		usd = 0.0
-		observ.IncCounter("corroboration_blocks_total", map[string]string{"symbol": symbol})
-	} else if earningsBlocked {
+	if riskReducing {
+		// Risk-reducing intents only face exit gates (see runGates) and size
+		// off the open position
+		intent = candidate
+		usd = abs(position.CurrentNotional)
+		if intent == "REDUCE" && cfg.BaseUSD < usd {
+			usd = cfg.BaseUSD
+		}
+		e.metrics.IncCounter("risk_reducing_decisions_total", map[string]string{"symbol": symbol, "intent": intent})
+	} else if softBlocked {
+		// A soft gate holds the would-be entry regardless of score
 		intent = "HOLD"
 		usd = 0.0
-	} else if sectorBlocked {
-		intent = "HOLD"
-		usd = 0.0
-	} else if drawdownBlocked {
-		intent = "HOLD"
-		usd = 0.0
-	} else {
-		// Normal threshold mapping with drawdown size multiplier
-		sizeMultiplier := 1.0
-		if drawdownMgr != nil {
-			sizeMultiplier = drawdownMgr.GetSizeMultiplier()
+		if reason.blockedBy("corroboration") {
+			e.metrics.IncCounter("corroboration_blocks_total", map[string]string{"symbol": symbol})
 		}
-		
+	} else {
+		// Normal threshold mapping; drawdown and circuit-breaker size
+		// multipliers are applied when the notional is sized into shares
 		if fused >= cfg.VeryPos {
 			intent = "BUY_5X"
-			usd = cfg.BaseUSD * 5 * sizeMultiplier
+			usd = cfg.BaseUSD * 5
 		} else if fused >= cfg.Positive {
 			intent = "BUY_1X"
-			usd = cfg.BaseUSD * sizeMultiplier
+			usd = cfg.BaseUSD
+		} else if candidate == "SELL_SHORT" {
+			intent = "SELL_SHORT"
+			usd = cfg.BaseUSD
 		}
 	}
 
@@ -439,12 +456,62 @@ func Evaluate(symbol string, advs []Advice, feat Features, risk RiskState, cfg C
 	}
 }
 
-// TTL helper (not used yet, but you'll use it in next sessions)
-func notExpired(created time.Time, ttlSeconds int) bool {
+// candidateIntent maps the fused score to an intent before any gate is applied.
+// REDUCE/EXIT are only proposed for an open long and COVER for an open short;
+// positive news covers a short rather than buying. SELL_SHORT opens or adds
+// to a short when shorting is enabled.
+func candidateIntent(fused float64, cfg Config, positionQty int) string {
+	hasPosition := positionQty > 0
+	switch {
+	case positionQty < 0 && fused >= cfg.Positive:
+		return "COVER"
+	case fused >= cfg.VeryPos:
+		return "BUY_5X"
+	case fused >= cfg.Positive:
+		return "BUY_1X"
+	case hasPosition && cfg.VeryNeg < 0 && fused <= cfg.VeryNeg:
+		return "EXIT"
+	case hasPosition && cfg.Negative < 0 && fused <= cfg.Negative:
+		return "REDUCE"
+	case positionQty <= 0 && cfg.Short.Enabled && fused <= cfg.Short.EntryScore:
+		return "SELL_SHORT"
+	}
+	return "HOLD"
+}
+
+// isRiskReducing matches the risk package's notion of intents that never add
+// exposure; the gate pipeline only checks them against exit gates. HOLD needs
+// no exemption here since no gate acts on it.
+func isRiskReducing(intent string) bool {
+	return intent == "REDUCE" || intent == "EXIT" || intent == "COVER"
+}
+
+// addsExposure reports whether an intent opens or adds to a position
+func addsExposure(intent string) bool {
+	return intent == "BUY_1X" || intent == "BUY_5X" || intent == "SELL_SHORT"
+}
+
+// policyString describes the threshold mapping in effect
+func policyString(cfg Config) string {
+	policy := fmt.Sprintf("positive>=%g; very_positive>=%g", cfg.Positive, cfg.VeryPos)
+	if cfg.Negative < 0 {
+		policy += fmt.Sprintf("; negative<=%g", cfg.Negative)
+	}
+	if cfg.VeryNeg < 0 {
+		policy += fmt.Sprintf("; very_negative<=%g", cfg.VeryNeg)
+	}
+	if cfg.Short.Enabled {
+		policy += fmt.Sprintf("; short<=%g", cfg.Short.EntryScore)
+	}
+	return policy
+}
+
+// notExpired reports whether advice created at created is still within its TTL at now
+func notExpired(created time.Time, ttlSeconds int, now time.Time) bool {
 	if ttlSeconds <= 0 {
 		return true
 	}
-	return time.Since(created) <= time.Duration(ttlSeconds)*time.Second
+	return now.Sub(created) <= time.Duration(ttlSeconds)*time.Second
 }
 
 // classifySource determines the source type for corroboration logic
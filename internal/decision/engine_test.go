@@ -1,7 +1,11 @@
 package decision
 
 import (
+	"context"
 	"testing"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/clock"
 	"github.com/Rajchodisetti/trading-app/internal/portfolio"
 	"github.com/Rajchodisetti/trading-app/internal/risk"
 )
@@ -34,3 +38,157 @@ func contains(s, sub string) bool {
 	}
 	return false
 }
+
+func TestEvaluate_NegativeScoreReducesOpenPosition(t *testing.T) {
+	pm := portfolio.NewManager(t.TempDir()+"/portfolio.json", 2000)
+	if err := pm.UpdatePosition("MEGA", 20, 100, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Config{Positive: 0.35, VeryPos: 0.65, Negative: -0.35, VeryNeg: -0.65, BaseUSD: 1000}
+	// Earnings embargo would hold a buy, but must not hold an exit
+	cfg.EarningsEmbargo = EarningsEmbargoConfig{Enabled: true}
+	earnings := []EarningsEvent{{Symbol: "MEGA", StartUTC: time.Now().Add(-time.Hour), EndUTC: time.Now().Add(time.Hour), Status: "confirmed"}}
+	riskState := RiskState{MaxSpreadBps: 30}
+	feat := Features{Symbol: "MEGA", Last: 100}
+
+	reduce := []Advice{{Symbol: "MEGA", Score: -0.5, Confidence: 0.9, SourceWeight: 1, PublishedAt: time.Now()}}
+	act := Evaluate("MEGA", reduce, feat, riskState, cfg, earnings, pm, nil, nil, nil)
+	if act.Intent != "REDUCE" || act.ScaledNotional != 1000 {
+		t.Fatalf("want REDUCE of 1000, got %s %.2f: %s", act.Intent, act.ScaledNotional, act.ReasonJSON)
+	}
+
+	exit := []Advice{{Symbol: "MEGA", Score: -1, Confidence: 1, SourceWeight: 1, PublishedAt: time.Now()}}
+	act = Evaluate("MEGA", exit, feat, riskState, cfg, earnings, pm, nil, nil, nil)
+	if act.Intent != "EXIT" {
+		t.Fatalf("want EXIT, got %s: %s", act.Intent, act.ReasonJSON)
+	}
+
+	// No position: negative news is a HOLD
+	act = Evaluate("NVDA", exit, Features{Symbol: "NVDA", Last: 100}, riskState, cfg, nil, pm, nil, nil, nil)
+	if act.Intent != "HOLD" {
+		t.Fatalf("want HOLD without position, got %s", act.Intent)
+	}
+}
+
+type countingMetrics map[string]int
+
+func (m countingMetrics) IncCounter(name string, labels map[string]string) { m[name]++ }
+
+func TestEngine_OptionsAndConfigSwap(t *testing.T) {
+	pm := portfolio.NewManager(t.TempDir()+"/portfolio.json", 2000)
+	if err := pm.UpdatePosition("MEGA", 20, 100, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	metrics := countingMetrics{}
+	cfg := Config{Positive: 0.35, VeryPos: 0.65, Negative: -0.35, VeryNeg: -0.65, BaseUSD: 1000}
+
+	e, err := NewEngine(cfg, WithPortfolio(pm), WithClock(clock.NewSim(now)), WithMetrics(metrics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := Inputs{
+		Advice:   []Advice{{Symbol: "MEGA", Score: -1, Confidence: 1, SourceWeight: 1, PublishedAt: now}},
+		Features: Features{Symbol: "MEGA", Last: 100},
+		Risk:     RiskState{MaxSpreadBps: 30},
+	}
+	act, err := e.Evaluate(context.Background(), "MEGA", in)
+	if err != nil || act.Intent != "EXIT" {
+		t.Fatalf("want EXIT, got %s %v: %s", act.Intent, err, act.ReasonJSON)
+	}
+	if metrics["risk_reducing_decisions_total"] != 1 {
+		t.Fatalf("metrics sink not used: %v", metrics)
+	}
+
+	bad := cfg
+	bad.Fusion.Method = "median"
+	if err := e.SetConfig(bad); err == nil {
+		t.Fatal("want invalid config rejected")
+	}
+	swapped := cfg
+	swapped.VeryNeg = 0 // disables EXIT
+	if err := e.SetConfig(swapped); err != nil {
+		t.Fatal(err)
+	}
+	if act, _ := e.Evaluate(context.Background(), "MEGA", in); act.Intent != "REDUCE" {
+		t.Fatalf("want REDUCE after swap, got %s", act.Intent)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := e.Evaluate(ctx, "MEGA", in); err == nil {
+		t.Fatal("want error from canceled context")
+	}
+}
+
+func TestEngine_ShortSelling(t *testing.T) {
+	pm := portfolio.NewManager(t.TempDir()+"/portfolio.json", 2000)
+	if err := pm.UpdatePosition("MEGA", -20, 100, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	cfg := Config{Positive: 0.35, VeryPos: 0.65, Negative: -0.35, VeryNeg: -0.65, BaseUSD: 1000}
+	cfg.Short = ShortConfig{Enabled: true, EntryScore: -0.65}
+	cfg.Portfolio = PortfolioConfig{Enabled: true, MaxPositionSizeUSD: 10000, MaxPortfolioExposurePct: 100, DailyTradeLimitPerSymbol: 10}
+
+	e, err := NewEngine(cfg, WithPortfolio(pm), WithBorrowList(risk.NewBorrowList("NVDA", "MEGA")), WithClock(clock.NewSim(now)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eval := func(sym string, score float64, feat Features) ProposedAction {
+		feat.Symbol, feat.Last = sym, 100
+		act, _ := e.Evaluate(context.Background(), sym, Inputs{
+			Advice:   []Advice{{Symbol: sym, Score: score, Confidence: 1, SourceWeight: 1, PublishedAt: now}},
+			Features: feat,
+			Risk:     RiskState{MaxSpreadBps: 30},
+		})
+		return act
+	}
+
+	if act := eval("NVDA", -1, Features{}); act.Intent != "SELL_SHORT" || act.ScaledNotional != 1000 {
+		t.Fatalf("want SELL_SHORT of 1000, got %s %.2f: %s", act.Intent, act.ScaledNotional, act.ReasonJSON)
+	}
+	if act := eval("NVDA", -0.5, Features{}); act.Intent != "HOLD" {
+		t.Fatalf("want HOLD above the short entry score, got %s", act.Intent)
+	}
+	if act := eval("BIOX", -1, Features{}); act.Intent != "REJECT" || !contains(act.ReasonJSON, "borrow") {
+		t.Fatalf("want hard-to-borrow short rejected, got %s: %s", act.Intent, act.ReasonJSON)
+	}
+	if act := eval("NVDA", -1, Features{SSRActive: true}); act.Intent != "REJECT" || !contains(act.ReasonJSON, "ssr") {
+		t.Fatalf("want short rejected under SSR, got %s: %s", act.Intent, act.ReasonJSON)
+	}
+
+	// Positive news covers an open short instead of buying
+	if act := eval("MEGA", 0.9, Features{}); act.Intent != "COVER" || act.ScaledNotional != 2000 {
+		t.Fatalf("want COVER of the whole short, got %s %.2f: %s", act.Intent, act.ScaledNotional, act.ReasonJSON)
+	}
+
+	// Adding another short would push net exposure past its limit
+	limited := cfg
+	limited.Portfolio.MaxNetExposurePct = 2
+	if err := e.SetConfig(limited); err != nil {
+		t.Fatal(err)
+	}
+	if act := eval("NVDA", -1, Features{}); act.Intent != "REJECT" || !contains(act.ReasonJSON, "caps") {
+		t.Fatalf("want net exposure cap to block, got %s: %s", act.Intent, act.ReasonJSON)
+	}
+
+	// A short adds to gross sector exposure just as a buy would
+	sectored := cfg
+	sectored.RiskControls.SectorLimits = risk.SectorLimitsConfig{Enabled: true, MaxSectorExposurePct: 100}
+	sectors := risk.NewSectorExposureManager(map[string]string{"MEGA": "tech", "NVDA": "tech"})
+	e, err = NewEngine(sectored, WithPortfolio(pm), WithBorrowList(risk.NewBorrowList("NVDA", "MEGA")), WithSectorExposure(sectors), WithClock(clock.NewSim(now)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act := eval("NVDA", -1, Features{}); act.Intent != "HOLD" || !contains(act.ReasonJSON, "sector_limit") {
+		t.Fatalf("want the soft sector limit to hold the short, got %s: %s", act.Intent, act.ReasonJSON)
+	}
+
+	bad := cfg
+	bad.Short.EntryScore = 0
+	if err := e.SetConfig(bad); err == nil {
+		t.Fatal("want a non-negative short entry score rejected")
+	}
+}
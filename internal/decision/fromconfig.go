@@ -0,0 +1,123 @@
+package decision
+
+import (
+	"sort"
+
+	"github.com/Rajchodisetti/trading-app/internal/config"
+	"github.com/Rajchodisetti/trading-app/internal/risk"
+)
+
+// ConfigFrom maps the loaded application config onto an engine config so
+// the live pipeline and replay evaluate with identical settings
+func ConfigFrom(cfg config.Root, integ config.Integration) Config {
+	return Config{
+		Positive: cfg.Thresholds.Positive,
+		VeryPos:  cfg.Thresholds.VeryPos,
+		Negative: cfg.Thresholds.Negative,
+		VeryNeg:  cfg.Thresholds.VeryNeg,
+		BaseUSD:  cfg.BaseUSD,
+		Short: ShortConfig{
+			Enabled:    cfg.Short.Enabled,
+			EntryScore: cfg.Short.EntryScore,
+		},
+		Corroboration: CorroborationConfig{
+			RequirePositivePR: cfg.Corroboration.RequirePositivePR,
+			WindowSeconds:     cfg.Corroboration.WindowSeconds,
+		},
+		EarningsEmbargo: EarningsEmbargoConfig{
+			Enabled:          cfg.EarningsEmbargo.Enabled,
+			BlockOnEstimated: cfg.EarningsEmbargo.BlockOnEstimated,
+			MinutesBefore:    cfg.EarningsEmbargo.MinutesBefore,
+			MinutesAfter:     cfg.EarningsEmbargo.MinutesAfter,
+		},
+		Portfolio: PortfolioConfig{
+			Enabled:                     cfg.Portfolio.Enabled,
+			MaxPositionSizeUSD:          cfg.Portfolio.MaxPositionSizeUSD,
+			MaxPortfolioExposurePct:     cfg.Portfolio.MaxPortfolioExposurePct,
+			DailyTradeLimitPerSymbol:    cfg.Portfolio.DailyTradeLimitPerSymbol,
+			CooldownMinutesPerSymbol:    cfg.Portfolio.CooldownMinutesPerSymbol,
+			MaxDailyExposureIncreasePct: cfg.Portfolio.MaxDailyExposureIncreasePct,
+			MaxGrossExposurePct:         cfg.Portfolio.MaxGrossExposurePct,
+			MaxNetExposurePct:           cfg.Portfolio.MaxNetExposurePct,
+		},
+		RiskControls: RiskControlsConfig{
+			StopLoss: risk.StopLossConfig{
+				Enabled:              cfg.RiskControls.StopLoss.Enabled,
+				DefaultStopLossPct:   cfg.RiskControls.StopLoss.DefaultStopLossPct,
+				EmergencyStopLossPct: cfg.RiskControls.StopLoss.EmergencyStopLossPct,
+				AllowAfterHours:      cfg.RiskControls.StopLoss.AllowAfterHours,
+				CooldownHours:        cfg.RiskControls.StopLoss.CooldownHours,
+				EntryReference:       cfg.RiskControls.StopLoss.EntryReference,
+			},
+			SectorLimits: risk.SectorLimitsConfig{
+				Enabled:              cfg.RiskControls.SectorLimits.Enabled,
+				MaxSectorExposurePct: cfg.RiskControls.SectorLimits.MaxSectorExposurePct,
+				SectorMap:            cfg.RiskControls.SectorLimits.SectorMap,
+			},
+			Drawdown: risk.DrawdownConfig{
+				Enabled:                    cfg.RiskControls.Drawdown.Enabled,
+				DailyWarningPct:            cfg.RiskControls.Drawdown.DailyWarningPct,
+				DailyPausePct:              cfg.RiskControls.Drawdown.DailyPausePct,
+				WeeklyWarningPct:           cfg.RiskControls.Drawdown.WeeklyWarningPct,
+				WeeklyPausePct:             cfg.RiskControls.Drawdown.WeeklyPausePct,
+				SizeMultiplierOnWarningPct: cfg.RiskControls.Drawdown.SizeMultiplierOnWarningPct,
+			},
+		},
+		Fusion: FusionConfig{
+			Method:  cfg.Fusion.Method,
+			Weights: cfg.Fusion.Weights,
+		},
+		Decay: DecayConfig{
+			HalfLifeSeconds:   cfg.Decay.HalfLifeSeconds,
+			DefaultTTLSeconds: cfg.Decay.DefaultTTLSeconds,
+		},
+		Gates: gateConfigFrom(cfg.Gates, integ),
+	}
+}
+
+// gateConfigFrom merges the caps/cooldown integration settings with the gates
+// section into the one gate config the engine and the risk manager share.
+// Priorities and classes set in the gates section win; with soft gates
+// disabled, the integration's soft conversion gates are hard.
+func gateConfigFrom(g config.Gates, integ config.Integration) GateConfig {
+	priorities := map[string]int{}
+	if integ.GatePriorityCaps > 0 {
+		priorities["caps"] = integ.GatePriorityCaps
+	}
+	if integ.GatePriorityCooldown > 0 {
+		priorities["cooldown"] = integ.GatePriorityCooldown
+	}
+	for name, prio := range g.Priorities {
+		priorities[name] = prio
+	}
+
+	hard := map[string]bool{}
+	for _, name := range integ.SoftConversionGates {
+		hard[name] = !integ.SoftGatesEnabled
+	}
+	for _, name := range integ.HardBlockGates {
+		hard[name] = true
+	}
+	for _, name := range append(append([]string{}, g.Hard...), g.Soft...) {
+		delete(hard, name)
+	}
+	names := make([]string, 0, len(hard))
+	for name := range hard {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	gc := GateConfig{
+		Priorities: priorities,
+		Hard:       append([]string{}, g.Hard...),
+		Soft:       append([]string{}, g.Soft...),
+	}
+	for _, name := range names {
+		if hard[name] {
+			gc.Hard = append(gc.Hard, name)
+		} else {
+			gc.Soft = append(gc.Soft, name)
+		}
+	}
+	return gc
+}
@@ -0,0 +1,134 @@
+package decision
+
+import "math"
+
+// Fusion methods
+const (
+	FusionWeightedSum    = "weighted_sum"    // tanh of the weighted sum of contributions
+	FusionConfidenceMean = "confidence_mean" // confidence-weighted mean of scores
+	FusionMaxAbs         = "max_abs"         // single strongest contribution wins
+)
+
+// FusionConfig selects how advice is combined into one score
+type FusionConfig struct {
+	Method  string             // weighted_sum (default) | confidence_mean | max_abs
+	Weights map[string]float64 // per-strategy multiplier; missing strategies weigh 1.0
+}
+
+// ValidFusionMethod reports whether m names a supported fusion method ("" means default)
+func ValidFusionMethod(m string) bool {
+	switch m {
+	case "", FusionWeightedSum, FusionConfidenceMean, FusionMaxAbs:
+		return true
+	}
+	return false
+}
+
+func (c FusionConfig) method() string {
+	if c.Method == "" {
+		return FusionWeightedSum
+	}
+	return c.Method
+}
+
+func (c FusionConfig) weight(label string) float64 {
+	if w, ok := c.Weights[label]; ok {
+		return w
+	}
+	return 1.0
+}
+
+// adviceLabel is the attribution key for an advice: its strategy, else its provider
+func adviceLabel(a Advice) string {
+	if a.Strategy != "" {
+		return a.Strategy
+	}
+	if a.Provider != "" {
+		return a.Provider
+	}
+	return "unknown"
+}
+
+// fuse combines advice into a score in [-1..1] and reports each strategy's
+// contribution to it
+func fuse(advs []Advice, cfg FusionConfig) (float64, map[string]float64) {
+	per := map[string]float64{}
+
+	// weight is confidence x source weight x strategy weight; score is applied on top
+	type term struct {
+		label  string
+		score  float64
+		weight float64
+	}
+	terms := make([]term, 0, len(advs))
+	for _, a := range advs {
+		w := a.Confidence
+		if w <= 0 {
+			w = 0.5
+		}
+		label := adviceLabel(a)
+		w *= a.SourceWeight * cfg.weight(label)
+		terms = append(terms, term{label: label, score: a.Score, weight: w})
+		per[label] += 0 // list every strategy that produced advice, even if it ends up contributing zero
+	}
+
+	switch cfg.method() {
+	case FusionConfidenceMean:
+		// Mean of scores weighted by confidence; contributions sum to the fused score
+		totalWeight := 0.0
+		for _, t := range terms {
+			totalWeight += math.Abs(t.weight)
+		}
+		if totalWeight == 0 {
+			return 0, per
+		}
+		fs := 0.0
+		for _, t := range terms {
+			contrib := t.score * t.weight / totalWeight
+			per[t.label] += contrib
+			fs += contrib
+		}
+		return clamp(fs, -1, 1), per
+
+	case FusionMaxAbs:
+		// Strongest single contribution decides; other strategies contribute zero
+		var best *term
+		for i := range terms {
+			if best == nil || math.Abs(terms[i].score*terms[i].weight) > math.Abs(best.score*best.weight) {
+				best = &terms[i]
+			}
+		}
+		if best == nil {
+			return 0, per
+		}
+		contrib := best.score * best.weight
+		per[best.label] = contrib
+		return math.Tanh(contrib), per
+
+	default:
+		// Weighted sum with tanh squash; per holds pre-squash contributions
+		sum := 0.0
+		for _, t := range terms {
+			contrib := t.score * t.weight
+			per[t.label] += contrib
+			sum += contrib
+		}
+		return math.Tanh(sum), per
+	}
+}
+
+// fuseWithoutPR: fuse advice excluding PR contributions (for window expiry scenario)
+func fuseWithoutPR(advs []Advice, cfg FusionConfig) (float64, map[string]float64) {
+	filtered := make([]Advice, 0, len(advs))
+	for _, a := range advs {
+		if a.IsPR {
+			continue // exclude PR advice
+		}
+		filtered = append(filtered, a)
+	}
+	return fuse(filtered, cfg)
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
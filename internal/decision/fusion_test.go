@@ -0,0 +1,56 @@
+package decision
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFuse_AttributesByStrategy(t *testing.T) {
+	advs := []Advice{
+		{Symbol: "BIOX", Score: 0.8, Confidence: 0.8, SourceWeight: 1.2, Strategy: "source_prior"},
+		{Symbol: "BIOX", Score: 0.6, Confidence: 0.8, SourceWeight: 1.0, Strategy: "source_prior"},
+		{Symbol: "BIOX", Score: -0.4, Confidence: 0.5, SourceWeight: 1.0, Strategy: "lexicon_sentiment"},
+	}
+
+	fs, per := fuse(advs, FusionConfig{})
+	if len(per) != 2 {
+		t.Fatalf("want 2 strategies, got %v", per)
+	}
+	if !approx(per["source_prior"], 0.768+0.48) || !approx(per["lexicon_sentiment"], -0.2) {
+		t.Fatalf("unexpected contributions: %v", per)
+	}
+	if !approx(fs, math.Tanh(0.768+0.48-0.2)) {
+		t.Fatalf("unexpected fused score %v", fs)
+	}
+
+	// Strategy weights scale contributions
+	_, per = fuse(advs, FusionConfig{Weights: map[string]float64{"lexicon_sentiment": 2}})
+	if !approx(per["lexicon_sentiment"], -0.4) {
+		t.Fatalf("weight not applied: %v", per)
+	}
+}
+
+func TestFuse_Methods(t *testing.T) {
+	advs := []Advice{
+		{Score: 0.8, Confidence: 1, SourceWeight: 1, Strategy: "a"},
+		{Score: -0.2, Confidence: 1, SourceWeight: 1, Strategy: "b"},
+	}
+
+	fs, per := fuse(advs, FusionConfig{Method: FusionConfidenceMean})
+	if !approx(fs, 0.3) || !approx(per["a"]+per["b"], fs) {
+		t.Fatalf("confidence_mean: fs=%v per=%v", fs, per)
+	}
+
+	fs, per = fuse(advs, FusionConfig{Method: FusionMaxAbs})
+	if !approx(fs, math.Tanh(0.8)) || per["b"] != 0 || !approx(per["a"], 0.8) {
+		t.Fatalf("max_abs: fs=%v per=%v", fs, per)
+	}
+
+	if fs, _ := fuse(nil, FusionConfig{Method: FusionConfidenceMean}); fs != 0 {
+		t.Fatalf("empty advice should fuse to 0, got %v", fs)
+	}
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,480 @@
+package decision
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/portfolio"
+	"github.com/Rajchodisetti/trading-app/internal/risk"
+)
+
+// GateInput is everything a gate may look at for one decision
+type GateInput struct {
+	Symbol       string
+	Feat         Features
+	Risk         RiskState
+	Cfg          Config
+	Now          time.Time
+	Fused        float64
+	Candidate    string // intent implied by the fused score before gating
+	RiskReducing bool   // candidate never adds exposure; only ExitGates see these
+
+	NeedsCorroboration bool
+	Corroboration      *CorroborationState
+	EarningsEmbargo    bool
+	Earnings           *EarningsEmbargoState
+
+	Portfolio *portfolio.Manager
+	StopLoss  *risk.StopLossManager
+	Sectors   *risk.SectorExposureManager
+	Drawdown  *risk.DrawdownManager
+	Borrow    *risk.BorrowList
+	Metrics   Metrics
+}
+
+// GateResult is what a gate reports. A gate passes when Blocked is empty; it
+// may block more than once (caps) or under a more specific name (drawdown).
+type GateResult struct {
+	Blocked         []string
+	WhatWouldChange string
+}
+
+// Gate is one check in the decision gate pipeline. Hard gates REJECT the
+// decision; soft gates turn it into a HOLD. Priority and class are defaults
+// that GateConfig may override.
+type Gate interface {
+	Name() string
+	Priority() int // lower runs first
+	Hard() bool
+	Check(in *GateInput) GateResult
+}
+
+// ExitGate is a gate that may also stop risk-reducing intents (REDUCE, EXIT,
+// COVER). The pipeline skips every other gate for them, so nothing meant to
+// limit new exposure can keep a position from being cut.
+type ExitGate interface {
+	Gate
+	ChecksExits() bool
+}
+
+// GateVerdict records one gate's outcome in the decision reason
+type GateVerdict struct {
+	Gate    string   `json:"gate"`
+	Class   string   `json:"class"` // hard | soft
+	Passed  bool     `json:"passed"`
+	Exempt  bool     `json:"exempt,omitempty"` // skipped: the intent reduces risk
+	Blocked []string `json:"blocked,omitempty"`
+}
+
+// GateConfig reorders and reclassifies gates by name. The classes cover the
+// risk manager's gates too (risk.GateNames): the same config is handed to
+// risk.RiskManager.SetGateClasses, so caps or cooldown is hard or soft in
+// both places at once.
+type GateConfig struct {
+	Priorities map[string]int // gate -> priority; unset gates keep their default
+	Hard       []string       // gates that REJECT
+	Soft       []string       // gates that convert to HOLD
+}
+
+// Validate reports gate names that are neither registered nor risk manager gates
+func (c GateConfig) Validate() error {
+	for name := range c.Priorities {
+		if _, ok := lookupGate(name); !ok {
+			return fmt.Errorf("gates: unknown gate %q in priorities", name)
+		}
+	}
+	for _, name := range append(append([]string{}, c.Hard...), c.Soft...) {
+		if !knownGate(name) {
+			return fmt.Errorf("gates: unknown gate %q", name)
+		}
+	}
+	for _, h := range c.Hard {
+		for _, s := range c.Soft {
+			if h == s {
+				return fmt.Errorf("gates: %q is listed as both hard and soft", h)
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	gatesMu      sync.RWMutex
+	gateRegistry = map[string]Gate{}
+)
+
+func init() {
+	for _, g := range builtinGates() {
+		if err := RegisterGate(g); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterGate adds a gate to the pipeline; names must be unique
+func RegisterGate(g Gate) error {
+	gatesMu.Lock()
+	defer gatesMu.Unlock()
+
+	if _, exists := gateRegistry[g.Name()]; exists {
+		return fmt.Errorf("gate %q already registered", g.Name())
+	}
+	gateRegistry[g.Name()] = g
+	return nil
+}
+
+// GateNames returns the registered gate names in sorted order
+func GateNames() []string {
+	gatesMu.RLock()
+	defer gatesMu.RUnlock()
+
+	names := make([]string, 0, len(gateRegistry))
+	for name := range gateRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupGate(name string) (Gate, bool) {
+	gatesMu.RLock()
+	defer gatesMu.RUnlock()
+	g, ok := gateRegistry[name]
+	return g, ok
+}
+
+// knownGate reports whether name is a registered gate or one the risk manager runs
+func knownGate(name string) bool {
+	if _, ok := lookupGate(name); ok {
+		return true
+	}
+	for _, rm := range risk.GateNames() {
+		if rm == name {
+			return true
+		}
+	}
+	return false
+}
+
+// plannedGate is a registered gate with its configured priority and class
+type plannedGate struct {
+	gate     Gate
+	priority int
+	hard     bool
+}
+
+// planGates orders the registered gates by configured priority, breaking ties by name
+func planGates(cfg GateConfig) []plannedGate {
+	gatesMu.RLock()
+	plan := make([]plannedGate, 0, len(gateRegistry))
+	for name, g := range gateRegistry {
+		p := plannedGate{gate: g, priority: g.Priority(), hard: g.Hard()}
+		if prio, ok := cfg.Priorities[name]; ok {
+			p.priority = prio
+		}
+		plan = append(plan, p)
+	}
+	gatesMu.RUnlock()
+
+	for i := range plan {
+		name := plan[i].gate.Name()
+		for _, h := range cfg.Hard {
+			if h == name {
+				plan[i].hard = true
+			}
+		}
+		for _, s := range cfg.Soft {
+			if s == name {
+				plan[i].hard = false
+			}
+		}
+	}
+	sort.Slice(plan, func(i, j int) bool {
+		if plan[i].priority != plan[j].priority {
+			return plan[i].priority < plan[j].priority
+		}
+		return plan[i].gate.Name() < plan[j].gate.Name()
+	})
+	return plan
+}
+
+// runGates evaluates every gate in priority order, recording each verdict and
+// blocked name in the reason. Risk-reducing candidates only face ExitGates.
+// It reports whether any hard or soft gate blocked.
+func runGates(in *GateInput, reason *Reason) (hardBlocked, softBlocked bool) {
+	for _, p := range planGates(in.Cfg.Gates) {
+		verdict := GateVerdict{Gate: p.gate.Name(), Class: "soft"}
+		if p.hard {
+			verdict.Class = "hard"
+		}
+		if in.RiskReducing && !checksExits(p.gate) {
+			verdict.Passed, verdict.Exempt = true, true
+			reason.Gates = append(reason.Gates, verdict)
+			continue
+		}
+
+		res := p.gate.Check(in)
+		verdict.Passed, verdict.Blocked = len(res.Blocked) == 0, res.Blocked
+		reason.Gates = append(reason.Gates, verdict)
+		if verdict.Passed {
+			continue
+		}
+
+		reason.GatesBlocked = append(reason.GatesBlocked, res.Blocked...)
+		if res.WhatWouldChange != "" {
+			reason.WhatWouldChange = res.WhatWouldChange
+		}
+		if p.hard {
+			hardBlocked = true
+		} else {
+			softBlocked = true
+		}
+	}
+	return hardBlocked, softBlocked
+}
+
+// checksExits reports whether g also applies to risk-reducing intents
+func checksExits(g Gate) bool {
+	eg, ok := g.(ExitGate)
+	return ok && eg.ChecksExits()
+}
+
+// funcGate adapts a check function to the Gate interface
+type funcGate struct {
+	name     string
+	priority int
+	hard     bool
+	check    func(in *GateInput) GateResult
+	exits    bool // also checks risk-reducing intents
+}
+
+func (g funcGate) Name() string                   { return g.name }
+func (g funcGate) Priority() int                  { return g.priority }
+func (g funcGate) Hard() bool                     { return g.hard }
+func (g funcGate) Check(in *GateInput) GateResult { return g.check(in) }
+func (g funcGate) ChecksExits() bool              { return g.exits }
+
+// builtinGates returns the engine's own gates. Priorities leave room between
+// them for custom gates; caps and cooldown sit after session and liquidity.
+// Only the global pause stops exits.
+func builtinGates() []Gate {
+	return []Gate{
+		funcGate{"global_pause", 10, true, checkGlobalPause, true},
+		funcGate{"halt", 15, true, checkHalt, false},
+		funcGate{"ssr", 16, true, checkSSR, false},
+		funcGate{"session", 20, true, checkSession, false},
+		funcGate{"liquidity", 22, true, checkLiquidity, false},
+		funcGate{"frozen", 24, true, checkFrozen, false},
+		funcGate{"cooldown_stop", 26, true, checkStopLossCooldown, false},
+		funcGate{"borrow", 28, true, checkBorrow, false},
+		funcGate{"caps", 30, true, checkCaps, false},
+		funcGate{"cooldown", 35, true, checkCooldown, false},
+		funcGate{"corroboration", 50, false, checkCorroboration, false},
+		funcGate{"earnings_embargo", 55, false, checkEarningsEmbargo, false},
+		funcGate{"sector_limit", 60, false, checkSectorLimit, false},
+		funcGate{"drawdown", 65, false, checkDrawdown, false},
+	}
+}
+
+func blocked(names ...string) GateResult {
+	return GateResult{Blocked: names}
+}
+
+func checkGlobalPause(in *GateInput) GateResult {
+	if in.Risk.GlobalPause {
+		return blocked("global_pause")
+	}
+	return GateResult{}
+}
+
+func checkHalt(in *GateInput) GateResult {
+	if in.Feat.Halted {
+		return blocked("halt")
+	}
+	return GateResult{}
+}
+
+// checkSSR blocks new shorts while the short sale restriction is in force
+func checkSSR(in *GateInput) GateResult {
+	if in.Candidate == "SELL_SHORT" && in.Feat.SSRActive {
+		in.Metrics.IncCounter("short_ssr_blocks_total", map[string]string{"symbol": in.Symbol})
+		return GateResult{Blocked: []string{"ssr"}, WhatWouldChange: "short sale restriction lifting"}
+	}
+	return GateResult{}
+}
+
+// checkSession blocks pre/post market trading
+func checkSession(in *GateInput) GateResult {
+	if (in.Risk.BlockPremarket && in.Feat.Premarket) || (in.Risk.BlockPostmarket && in.Feat.Postmarket) {
+		return blocked("session")
+	}
+	return GateResult{}
+}
+
+// checkLiquidity blocks wide spreads
+func checkLiquidity(in *GateInput) GateResult {
+	if in.Feat.SpreadBps > in.Risk.MaxSpreadBps {
+		return blocked("liquidity")
+	}
+	return GateResult{}
+}
+
+func checkFrozen(in *GateInput) GateResult {
+	for _, frozen := range in.Risk.FrozenSymbols {
+		if frozen == in.Symbol {
+			return blocked("frozen")
+		}
+	}
+	return GateResult{}
+}
+
+// checkStopLossCooldown blocks re-entry after a stop-loss
+func checkStopLossCooldown(in *GateInput) GateResult {
+	if in.StopLoss != nil && in.Cfg.RiskControls.StopLoss.Enabled {
+		if in.StopLoss.IsInCooldown(in.Symbol, in.Now) {
+			return blocked("cooldown_stop")
+		}
+	}
+	return GateResult{}
+}
+
+// checkBorrow blocks short sales in symbols missing from the easy-to-borrow list
+func checkBorrow(in *GateInput) GateResult {
+	if in.Candidate != "SELL_SHORT" || in.Borrow.EasyToBorrow(in.Symbol) {
+		return GateResult{}
+	}
+	in.Metrics.IncCounter("short_borrow_blocks_total", map[string]string{"symbol": in.Symbol})
+	return GateResult{Blocked: []string{"borrow"}, WhatWouldChange: in.Symbol + " on the easy-to-borrow list"}
+}
+
+// portfolioGated reports whether the portfolio gates apply: portfolio
+// management is enabled and the trade would open or add to a position
+func portfolioGated(in *GateInput) bool {
+	return in.Cfg.Portfolio.Enabled && in.Portfolio != nil && addsExposure(in.Candidate)
+}
+
+// buyNotional is the notional a would-be BUY or short sale adds
+func buyNotional(in *GateInput) float64 {
+	if in.Fused >= in.Cfg.VeryPos {
+		return in.Cfg.BaseUSD * 5
+	}
+	return in.Cfg.BaseUSD
+}
+
+// checkCaps enforces the per-symbol position cap, the portfolio exposure cap,
+// the gross and net exposure limits and the daily trade limit; each violation
+// is recorded separately
+func checkCaps(in *GateInput) GateResult {
+	if !portfolioGated(in) {
+		return GateResult{}
+	}
+	var res GateResult
+	labels := map[string]string{"symbol": in.Symbol}
+	newPositionValue := buyNotional(in)
+
+	currentPositionValue := 0.0
+	if pos, ok := in.Portfolio.GetPosition(in.Symbol); ok {
+		currentPositionValue = abs(pos.CurrentNotional)
+	}
+	if currentPositionValue+newPositionValue > in.Cfg.Portfolio.MaxPositionSizeUSD {
+		res.Blocked = append(res.Blocked, "caps")
+		in.Metrics.IncCounter("position_cap_violations_total", labels)
+	}
+
+	capital := in.Cfg.BaseUSD * 100
+	grossExposure := in.Portfolio.GetExposureUSD() + newPositionValue
+	newExposurePct := (grossExposure / capital) * 100
+	if newExposurePct > in.Cfg.Portfolio.MaxPortfolioExposurePct {
+		res.Blocked = append(res.Blocked, "caps")
+		in.Metrics.IncCounter("portfolio_exposure_violations_total", labels)
+	}
+	if limit := in.Cfg.Portfolio.MaxGrossExposurePct; limit > 0 && newExposurePct > limit {
+		res.Blocked = append(res.Blocked, "caps")
+		in.Metrics.IncCounter("gross_exposure_violations_total", labels)
+	}
+
+	// Net exposure only blocks trades that push it further from flat
+	net := in.Portfolio.GetNetExposureUSD()
+	newNet := net + newPositionValue
+	if in.Candidate == "SELL_SHORT" {
+		newNet = net - newPositionValue
+	}
+	if limit := in.Cfg.Portfolio.MaxNetExposurePct; limit > 0 && abs(newNet) > abs(net) && abs(newNet)/capital*100 > limit {
+		res.Blocked = append(res.Blocked, "caps")
+		in.Metrics.IncCounter("net_exposure_violations_total", labels)
+	}
+
+	if in.Portfolio.GetTradeCount(in.Symbol) >= in.Cfg.Portfolio.DailyTradeLimitPerSymbol {
+		res.Blocked = append(res.Blocked, "caps")
+		in.Metrics.IncCounter("daily_limit_hits_total", labels)
+	}
+	return res
+}
+
+// checkCooldown enforces the minimum time between trades in a symbol
+func checkCooldown(in *GateInput) GateResult {
+	if portfolioGated(in) && !in.Portfolio.CanTrade(in.Symbol, in.Cfg.Portfolio.CooldownMinutesPerSymbol) {
+		in.Metrics.IncCounter("cooldown_gate_blocks_total", map[string]string{"symbol": in.Symbol})
+		return blocked("cooldown")
+	}
+	return GateResult{}
+}
+
+// checkCorroboration holds a would-be BUY until a PR is corroborated
+func checkCorroboration(in *GateInput) GateResult {
+	c := in.Corroboration
+	if !in.NeedsCorroboration || c == nil || in.Now.After(c.Until) || len(c.Missing) == 0 {
+		return GateResult{}
+	}
+	if in.Fused < in.Cfg.Positive {
+		return GateResult{}
+	}
+	return GateResult{
+		Blocked:         []string{"corroboration"},
+		WhatWouldChange: "editorial/regulatory confirmation before " + c.Until.Format(time.RFC3339),
+	}
+}
+
+// checkEarningsEmbargo holds a would-be BUY or short sale around earnings
+func checkEarningsEmbargo(in *GateInput) GateResult {
+	if !in.EarningsEmbargo || in.Earnings == nil || !addsExposure(in.Candidate) {
+		return GateResult{}
+	}
+	return GateResult{
+		Blocked:         []string{"earnings_embargo"},
+		WhatWouldChange: "wait until " + in.Earnings.EndUTC.Format(time.RFC3339),
+	}
+}
+
+// checkSectorLimit holds a BUY or short sale that would exceed sector
+// exposure; exposure is gross, so a short adds to it like a long
+func checkSectorLimit(in *GateInput) GateResult {
+	limits := in.Cfg.RiskControls.SectorLimits
+	if in.Sectors == nil || !limits.Enabled || in.Portfolio == nil || !addsExposure(in.Candidate) {
+		return GateResult{}
+	}
+	nav := in.Portfolio.GetNAV()
+	positions := in.Portfolio.GetPositionNotionals()
+	exceeds, sector := in.Sectors.CheckSectorLimit(in.Symbol, buyNotional(in), nav, positions, limits)
+	if !exceeds {
+		return GateResult{}
+	}
+	return GateResult{
+		Blocked:         []string{"sector_limit"},
+		WhatWouldChange: "reduce " + sector + " sector exposure below " + fmt.Sprintf("%.1f%%", limits.MaxSectorExposurePct),
+	}
+}
+
+// checkDrawdown pauses new buys while drawdown is past its pause threshold
+func checkDrawdown(in *GateInput) GateResult {
+	if in.Drawdown == nil || !in.Cfg.RiskControls.Drawdown.Enabled {
+		return GateResult{}
+	}
+	isBlocked, gateName := in.Drawdown.CheckDrawdownGates(in.Candidate, in.Cfg.RiskControls.Drawdown)
+	if !isBlocked {
+		return GateResult{}
+	}
+	return GateResult{Blocked: []string{gateName}, WhatWouldChange: "wait for drawdown to recover"}
+}
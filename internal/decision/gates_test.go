@@ -0,0 +1,145 @@
+package decision
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/config"
+	"github.com/Rajchodisetti/trading-app/internal/portfolio"
+)
+
+func TestEvaluate_GateConfig(t *testing.T) {
+	advs := []Advice{{Symbol: "NVDA", Score: 0.7, Confidence: 0.9, SourceWeight: 1}}
+	wide := Features{Symbol: "NVDA", Last: 100, SpreadBps: 50}
+	riskState := RiskState{MaxSpreadBps: 30}
+
+	tests := []struct {
+		name       string
+		gates      GateConfig
+		wantIntent string
+		wantClass  string
+	}{
+		{name: "liquidity is hard by default", wantIntent: "REJECT", wantClass: "hard"},
+		{name: "liquidity reclassified soft", gates: GateConfig{Soft: []string{"liquidity"}}, wantIntent: "HOLD", wantClass: "soft"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Positive: 0.35, VeryPos: 0.65, BaseUSD: 2000, Gates: tt.gates}
+			act := Evaluate("NVDA", advs, wide, riskState, cfg, nil, nil, nil, nil, nil)
+			if act.Intent != tt.wantIntent {
+				t.Fatalf("want %s, got %s: %s", tt.wantIntent, act.Intent, act.ReasonJSON)
+			}
+			var reason Reason
+			if err := json.Unmarshal([]byte(act.ReasonJSON), &reason); err != nil {
+				t.Fatal(err)
+			}
+			if !reason.blockedBy("liquidity") {
+				t.Fatalf("liquidity verdict missing: %s", act.ReasonJSON)
+			}
+			for _, v := range reason.Gates {
+				if v.Gate == "liquidity" && v.Class != tt.wantClass {
+					t.Fatalf("want liquidity %s, got %s", tt.wantClass, v.Class)
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluate_GatePriorityOrdersVerdicts(t *testing.T) {
+	advs := []Advice{{Symbol: "NVDA", Score: 0.7, Confidence: 0.9, SourceWeight: 1}}
+	feat := Features{Symbol: "NVDA", Halted: true}
+	cfg := Config{Positive: 0.35, VeryPos: 0.65, BaseUSD: 2000}
+
+	act := Evaluate("NVDA", advs, feat, RiskState{GlobalPause: true}, cfg, nil, nil, nil, nil, nil)
+	var reason Reason
+	_ = json.Unmarshal([]byte(act.ReasonJSON), &reason)
+	if len(reason.Gates) != len(GateNames()) {
+		t.Fatalf("want a verdict per gate, got %d", len(reason.Gates))
+	}
+	if reason.GatesBlocked[0] != "global_pause" || reason.GatesBlocked[1] != "halt" {
+		t.Fatalf("want global_pause before halt, got %v", reason.GatesBlocked)
+	}
+
+	cfg.Gates = GateConfig{Priorities: map[string]int{"halt": 1}}
+	act = Evaluate("NVDA", advs, feat, RiskState{GlobalPause: true}, cfg, nil, nil, nil, nil, nil)
+	reason = Reason{}
+	_ = json.Unmarshal([]byte(act.ReasonJSON), &reason)
+	if reason.Gates[0].Gate != "halt" || reason.GatesBlocked[0] != "halt" {
+		t.Fatalf("want halt first, got %v", reason.GatesBlocked)
+	}
+}
+
+func TestGateConfig_Validate(t *testing.T) {
+	if err := (GateConfig{Priorities: map[string]int{"caps": 5}, Soft: []string{"caps"}}).Validate(); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+	if err := (GateConfig{Hard: []string{"circuit_breaker"}, Soft: []string{"data_quality"}}).Validate(); err != nil {
+		t.Fatalf("risk manager gates rejected: %v", err)
+	}
+	if err := (GateConfig{Hard: []string{"nope"}}).Validate(); err == nil {
+		t.Fatal("want error for unknown gate")
+	}
+	if err := (GateConfig{Hard: []string{"caps"}, Soft: []string{"caps"}}).Validate(); err == nil {
+		t.Fatal("want error for gate listed as hard and soft")
+	}
+}
+
+func TestEvaluate_ExitsOnlyFaceExitGates(t *testing.T) {
+	pm := portfolio.NewManager(t.TempDir()+"/portfolio.json", 2000)
+	if err := pm.UpdatePosition("MEGA", 20, 100, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{Positive: 0.35, VeryPos: 0.65, Negative: -0.35, VeryNeg: -0.65, BaseUSD: 1000}
+	exit := []Advice{{Symbol: "MEGA", Score: -1, Confidence: 1, SourceWeight: 1, PublishedAt: time.Now()}}
+
+	// Postmarket, wide, halted and frozen: every hard gate but the pause would block an entry
+	feat := Features{Symbol: "MEGA", Last: 100, Halted: true, Postmarket: true, SpreadBps: 80}
+	riskState := RiskState{BlockPostmarket: true, MaxSpreadBps: 30, FrozenSymbols: []string{"MEGA"}}
+	act := Evaluate("MEGA", exit, feat, riskState, cfg, nil, pm, nil, nil, nil)
+	if act.Intent != "EXIT" {
+		t.Fatalf("want EXIT past the entry gates, got %s: %s", act.Intent, act.ReasonJSON)
+	}
+	var reason Reason
+	_ = json.Unmarshal([]byte(act.ReasonJSON), &reason)
+	for _, v := range reason.Gates {
+		if v.Gate == "halt" && !v.Exempt {
+			t.Fatalf("want halt recorded as exempt, got %+v", v)
+		}
+	}
+
+	riskState.GlobalPause = true
+	if act := Evaluate("MEGA", exit, feat, riskState, cfg, nil, pm, nil, nil, nil); act.Intent != "REJECT" {
+		t.Fatalf("want the global pause to stop exits, got %s", act.Intent)
+	}
+}
+
+func TestGateConfigFrom_SharesOneClassification(t *testing.T) {
+	integ := config.Integration{
+		SoftGatesEnabled:    true,
+		HardBlockGates:      []string{"circuit_breaker", "data_quality"},
+		SoftConversionGates: []string{"caps", "cooldown"},
+	}
+	gc := gateConfigFrom(config.Gates{Hard: []string{"cooldown"}}, integ)
+	if !reflect.DeepEqual(gc.Hard, []string{"cooldown", "circuit_breaker", "data_quality"}) || !reflect.DeepEqual(gc.Soft, []string{"caps"}) {
+		t.Fatalf("unexpected classes hard=%v soft=%v", gc.Hard, gc.Soft)
+	}
+	if err := gc.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The engine's caps gate converts to HOLD under the same classification
+	pm := portfolio.NewManager(t.TempDir()+"/portfolio.json", 2000)
+	cfg := Config{Positive: 0.35, VeryPos: 0.65, BaseUSD: 2000, Gates: gc,
+		Portfolio: PortfolioConfig{Enabled: true, MaxPositionSizeUSD: 1000, MaxPortfolioExposurePct: 100, DailyTradeLimitPerSymbol: 5}}
+	advs := []Advice{{Symbol: "NVDA", Score: 0.5, Confidence: 1, SourceWeight: 1}}
+	if act := Evaluate("NVDA", advs, Features{Symbol: "NVDA", Last: 100}, RiskState{MaxSpreadBps: 30}, cfg, nil, pm, nil, nil, nil); act.Intent != "HOLD" {
+		t.Fatalf("want soft caps to HOLD, got %s: %s", act.Intent, act.ReasonJSON)
+	}
+
+	integ.SoftGatesEnabled = false
+	if gc := gateConfigFrom(config.Gates{}, integ); len(gc.Soft) != 0 {
+		t.Fatalf("want every gate hard with soft gates disabled, got soft=%v", gc.Soft)
+	}
+}
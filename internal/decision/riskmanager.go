@@ -0,0 +1,77 @@
+package decision
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Rajchodisetti/trading-app/internal/risk"
+)
+
+// RiskManagerState records how the risk manager adjusted a decision
+type RiskManagerState struct {
+	DecisionID     string   `json:"decision_id"`
+	Approved       bool     `json:"approved"`
+	OriginalIntent string   `json:"original_intent"`
+	SizeMultiplier float64  `json:"size_multiplier"`
+	BlockedBy      []string `json:"blocked_by,omitempty"`
+	Warnings       []string `json:"warnings,omitempty"`
+	RiskScore      float64  `json:"risk_score"`
+}
+
+// ApplyRiskResult merges a risk manager verdict into an action. Blocks turn
+// the action into a REJECT, soft caps/cooldown violations turn buys into
+// HOLD, and the size multiplier scales buy and short-sale notionals. Sells are never scaled,
+// matching the sizer. The verdict is recorded in the action's reason.
+func ApplyRiskResult(act ProposedAction, res risk.DecisionResult) ProposedAction {
+	var reason Reason
+	_ = json.Unmarshal([]byte(act.ReasonJSON), &reason)
+
+	reason.RiskManager = &RiskManagerState{
+		DecisionID:     res.DecisionID,
+		Approved:       res.Approved,
+		OriginalIntent: act.Intent,
+		SizeMultiplier: res.SizeMultiplier,
+		BlockedBy:      res.BlockedBy,
+		Warnings:       res.Warnings,
+		RiskScore:      res.RiskScore,
+	}
+
+	switch {
+	case !res.Approved:
+		reason.GatesBlocked = append(reason.GatesBlocked, res.BlockedBy...)
+		act.Intent = "REJECT"
+		act.ScaledNotional = 0
+	case res.Intent != act.Intent:
+		for _, w := range res.Warnings {
+			if strings.HasPrefix(w, "caps_") || strings.HasPrefix(w, "cooldown_") {
+				reason.GatesBlocked = append(reason.GatesBlocked, w)
+			}
+		}
+		act.Intent = res.Intent
+		act.ScaledNotional = 0
+	case strings.HasPrefix(act.Intent, "BUY_") || act.Intent == "SELL_SHORT":
+		act.ScaledNotional *= res.SizeMultiplier
+	}
+
+	rj, _ := json.Marshal(reason)
+	act.ReasonJSON = string(rj)
+	return act
+}
+
+// StopLossAction closes out a position whose stop-loss triggered: a REDUCE
+// of the whole long or a COVER of the whole short, sized from the position
+// at price. It bypasses the engine and the risk manager, like any exit.
+func StopLossAction(symbol string, positionQty int, price float64) ProposedAction {
+	qty := positionQty
+	if qty < 0 {
+		qty = -qty
+	}
+	rj, _ := json.Marshal(Reason{Policy: "stop_loss"})
+	return ProposedAction{
+		Symbol:         symbol,
+		Intent:         risk.StopIntent(positionQty < 0),
+		BaseAmountUSD:  float64(qty) * price,
+		ScaledNotional: float64(qty) * price,
+		ReasonJSON:     string(rj),
+	}
+}
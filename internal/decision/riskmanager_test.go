@@ -0,0 +1,93 @@
+package decision
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Rajchodisetti/trading-app/internal/risk"
+)
+
+func TestApplyRiskResult(t *testing.T) {
+	base := ProposedAction{Symbol: "AAPL", Intent: "BUY_1X", BaseAmountUSD: 2000, ScaledNotional: 2000, ReasonJSON: `{"fused_score":0.5,"gates_passed":["no_halt"],"gates_blocked":[]}`}
+
+	tests := []struct {
+		name         string
+		act          ProposedAction
+		res          risk.DecisionResult
+		wantIntent   string
+		wantNotional float64
+		wantBlocked  []string
+	}{
+		{
+			name:         "approved buy is scaled",
+			act:          base,
+			res:          risk.DecisionResult{Approved: true, Intent: "BUY_1X", SizeMultiplier: 0.7},
+			wantIntent:   "BUY_1X",
+			wantNotional: 1400,
+		},
+		{
+			name:         "exit is never scaled",
+			act:          ProposedAction{Symbol: "AAPL", Intent: "EXIT", ScaledNotional: 5000, ReasonJSON: `{}`},
+			res:          risk.DecisionResult{Approved: true, Intent: "EXIT", SizeMultiplier: 0.5},
+			wantIntent:   "EXIT",
+			wantNotional: 5000,
+		},
+		{
+			name:        "hard block rejects",
+			act:         base,
+			res:         risk.DecisionResult{Approved: false, Intent: "BUY_1X", SizeMultiplier: 0, BlockedBy: []string{"circuit_breaker_halted"}},
+			wantIntent:  "REJECT",
+			wantBlocked: []string{"circuit_breaker_halted"},
+		},
+		{
+			name:        "soft gate holds",
+			act:         base,
+			res:         risk.DecisionResult{Approved: true, Intent: "HOLD", SizeMultiplier: 1, Warnings: []string{"volatility_adjustment_1.00", "cooldown_global_remaining_3s"}},
+			wantIntent:  "HOLD",
+			wantBlocked: []string{"cooldown_global_remaining_3s"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ApplyRiskResult(tt.act, tt.res)
+			if got.Intent != tt.wantIntent || got.ScaledNotional != tt.wantNotional {
+				t.Fatalf("got %s $%.0f, want %s $%.0f", got.Intent, got.ScaledNotional, tt.wantIntent, tt.wantNotional)
+			}
+			var reason Reason
+			if err := json.Unmarshal([]byte(got.ReasonJSON), &reason); err != nil {
+				t.Fatal(err)
+			}
+			if reason.RiskManager == nil || reason.RiskManager.OriginalIntent != tt.act.Intent {
+				t.Fatalf("risk manager state missing from reason: %s", got.ReasonJSON)
+			}
+			if len(reason.GatesBlocked) != len(tt.wantBlocked) {
+				t.Fatalf("gates_blocked %v, want %v", reason.GatesBlocked, tt.wantBlocked)
+			}
+			for i := range tt.wantBlocked {
+				if reason.GatesBlocked[i] != tt.wantBlocked[i] {
+					t.Fatalf("gates_blocked %v, want %v", reason.GatesBlocked, tt.wantBlocked)
+				}
+			}
+		})
+	}
+}
+
+func TestStopLossAction(t *testing.T) {
+	sizer := risk.NewSizer(risk.SizingConfig{}, nil, nil)
+	// 15 shares at 1.10 is worth 16.5, which divides back to 14.999...
+	for _, qty := range []int{15, 7, -15} {
+		act := StopLossAction("AAPL", qty, 1.1)
+		wantIntent := "REDUCE"
+		if qty < 0 {
+			wantIntent = "COVER"
+		}
+		if act.Intent != wantIntent {
+			t.Fatalf("position %d: want %s, got %s", qty, wantIntent, act.Intent)
+		}
+		// The whole position is closed, whatever float error the notional carries
+		size := sizer.Size(risk.SizeRequest{Symbol: "AAPL", Intent: act.Intent, NotionalUSD: act.ScaledNotional, Price: 1.1, PositionQty: qty})
+		if want := max(qty, -qty); size.Quantity != want {
+			t.Fatalf("position %d: want %d shares, got %d", qty, want, size.Quantity)
+		}
+	}
+}
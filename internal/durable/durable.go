@@ -0,0 +1,88 @@
+package durable
+
+import (
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SyncPolicy controls when appended data is flushed to stable storage
+type SyncPolicy string
+
+const (
+	SyncAlways  SyncPolicy = "always"  // fsync after every write
+	SyncBatched SyncPolicy = "batched" // fsync every BatchRecords writes or BatchInterval, whichever first
+	SyncNone    SyncPolicy = "none"    // leave flushing to the OS
+)
+
+// SyncConfig is an fsync policy plus its batching bounds
+type SyncConfig struct {
+	Policy        SyncPolicy
+	BatchRecords  int           // batched: sync after this many unsynced writes
+	BatchInterval time.Duration // batched: sync unsynced writes at least this often
+}
+
+// ParseSyncPolicy validates a policy name; empty means always
+func ParseSyncPolicy(s string) (SyncPolicy, error) {
+	switch SyncPolicy(s) {
+	case "":
+		return SyncAlways, nil
+	case SyncAlways, SyncBatched, SyncNone:
+		return SyncPolicy(s), nil
+	}
+	return "", fmt.Errorf("unknown fsync policy %q (want always, batched or none)", s)
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Checksum returns the CRC-32C of data as 8 hex digits
+func Checksum(data []byte) string {
+	return fmt.Sprintf("%08x", crc32.Checksum(data, castagnoli))
+}
+
+// WriteFileAtomic replaces path with data so that readers, and a restart after
+// power loss, see either the old or the new contents in full. With sync the
+// data and the rename are flushed before returning.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode, sync bool) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if sync {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if sync {
+		return SyncDir(filepath.Dir(path))
+	}
+	return nil
+}
+
+// SyncDir flushes directory metadata so renames and new files survive power loss
+func SyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
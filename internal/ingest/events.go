@@ -0,0 +1,124 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Rajchodisetti/trading-app/internal/transport"
+)
+
+// NewsItem is the normalized wire news payload
+type NewsItem struct {
+	ID             string   `json:"id"`
+	Provider       string   `json:"provider"`
+	PublishedAtUTC string   `json:"published_at_utc"`
+	Headline       string   `json:"headline"`
+	Body           string   `json:"body"`
+	URLs           []string `json:"urls"`
+	Tickers        []string `json:"tickers"`
+	IsPressRelease bool     `json:"is_press_release"`
+	IsCorrection   bool     `json:"is_correction"`
+	SupersedesID   *string  `json:"supersedes_id"`
+	SourceWeight   float64  `json:"source_weight"`
+	HeadlineHash   string   `json:"headline_hash"`
+
+	// RetractedBy is set by World to the id of the correction that superseded this item
+	RetractedBy string `json:"-"`
+}
+
+// Tick is the normalized wire market tick payload
+type Tick struct {
+	TsUTC      string  `json:"ts_utc"`
+	Symbol     string  `json:"symbol"`
+	Last       float64 `json:"last"`
+	VWAP5m     float64 `json:"vwap_5m"`
+	RelVolume  float64 `json:"rel_volume"`
+	Halted     bool    `json:"halted"`
+	Premarket  bool    `json:"premarket"`
+	Postmarket bool    `json:"postmarket"`
+	Bid        float64 `json:"bid"`
+	Ask        float64 `json:"ask"`
+	SSRActive  bool    `json:"ssr_active"` // short sale restriction (Rule 201) in force
+}
+
+// Halt is the normalized wire halt payload
+type Halt struct {
+	Symbol string `json:"symbol"`
+	Halted bool   `json:"halted"`
+}
+
+// Earnings is the normalized wire earnings payload
+type Earnings struct {
+	Symbol   string `json:"symbol"`
+	StartUTC string `json:"start_utc"`
+	EndUTC   string `json:"end_utc"`
+	Status   string `json:"status"`
+	Type     string `json:"type"` // wire name for status
+}
+
+// Event is a single parsed wire event; at most one payload pointer is set
+// (control events such as watermarks carry none)
+type Event struct {
+	Type     string
+	ID       string
+	News     *NewsItem
+	Tick     *Tick
+	Halt     *Halt
+	Earnings *Earnings
+}
+
+// ParseEnvelope decodes a transport envelope into a typed event.
+// Stub servers wrap the payload in the full wire event ({"type":..,"payload":{..}}),
+// so the nested payload is unwrapped when present.
+func ParseEnvelope(env transport.EventEnvelope) (Event, error) {
+	ev := Event{Type: env.Type, ID: env.ID}
+	raw := unwrapPayload(env.Payload)
+
+	switch env.Type {
+	case "news":
+		var n NewsItem
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return ev, fmt.Errorf("parse news event %s: %w", env.ID, err)
+		}
+		ev.News = &n
+	case "tick":
+		var t Tick
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return ev, fmt.Errorf("parse tick event %s: %w", env.ID, err)
+		}
+		ev.Tick = &t
+	case "halt":
+		var h Halt
+		if err := json.Unmarshal(raw, &h); err != nil {
+			return ev, fmt.Errorf("parse halt event %s: %w", env.ID, err)
+		}
+		ev.Halt = &h
+	case "earnings":
+		var e Earnings
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return ev, fmt.Errorf("parse earnings event %s: %w", env.ID, err)
+		}
+		if e.Status == "" {
+			e.Status = e.Type
+		}
+		ev.Earnings = &e
+	case "watermark":
+		// Server progress marker; carries no decision inputs
+	default:
+		return ev, fmt.Errorf("unknown event type %q", env.Type)
+	}
+
+	return ev, nil
+}
+
+// unwrapPayload returns the nested "payload" field if the raw message is a wire event
+func unwrapPayload(raw json.RawMessage) json.RawMessage {
+	var wrapper struct {
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(raw, &wrapper); err == nil && len(wrapper.Payload) > 0 && strings.TrimSpace(string(wrapper.Payload)) != "null" {
+		return wrapper.Payload
+	}
+	return raw
+}
@@ -0,0 +1,79 @@
+package ingest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Rajchodisetti/trading-app/internal/transport"
+)
+
+func TestParseEnvelope(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     transport.EventEnvelope
+		check   func(t *testing.T, ev Event)
+		wantErr bool
+	}{
+		{
+			name: "bare tick",
+			env:  transport.EventEnvelope{Type: "tick", ID: "1", Payload: json.RawMessage(`{"symbol":"AAPL","last":101.5,"halted":true}`)},
+			check: func(t *testing.T, ev Event) {
+				if ev.Tick == nil || ev.Tick.Symbol != "AAPL" || ev.Tick.Last != 101.5 || !ev.Tick.Halted {
+					t.Fatalf("tick = %+v", ev.Tick)
+				}
+			},
+		},
+		{
+			name: "wrapped news from a stub server",
+			env:  transport.EventEnvelope{Type: "news", ID: "2", Payload: json.RawMessage(`{"type":"news","payload":{"id":"n1","tickers":["NVDA"]}}`)},
+			check: func(t *testing.T, ev Event) {
+				if ev.News == nil || ev.News.ID != "n1" || len(ev.News.Tickers) != 1 {
+					t.Fatalf("news = %+v", ev.News)
+				}
+			},
+		},
+		{
+			name: "halt",
+			env:  transport.EventEnvelope{Type: "halt", ID: "3", Payload: json.RawMessage(`{"symbol":"BIOX","halted":true}`)},
+			check: func(t *testing.T, ev Event) {
+				if ev.Halt == nil || ev.Halt.Symbol != "BIOX" || !ev.Halt.Halted {
+					t.Fatalf("halt = %+v", ev.Halt)
+				}
+			},
+		},
+		{
+			name: "earnings status from the wire type",
+			env:  transport.EventEnvelope{Type: "earnings", ID: "4", Payload: json.RawMessage(`{"symbol":"AAPL","type":"confirmed"}`)},
+			check: func(t *testing.T, ev Event) {
+				if ev.Earnings == nil || ev.Earnings.Status != "confirmed" {
+					t.Fatalf("earnings = %+v", ev.Earnings)
+				}
+			},
+		},
+		{
+			name: "watermark carries no payload",
+			env:  transport.EventEnvelope{Type: "watermark", ID: "5"},
+			check: func(t *testing.T, ev Event) {
+				if ev.News != nil || ev.Tick != nil || ev.Halt != nil || ev.Earnings != nil {
+					t.Fatalf("watermark decoded a payload: %+v", ev)
+				}
+			},
+		},
+		{name: "unknown type", env: transport.EventEnvelope{Type: "trade", ID: "6"}, wantErr: true},
+		{name: "malformed payload", env: transport.EventEnvelope{Type: "tick", ID: "7", Payload: json.RawMessage(`{"last":"x"}`)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, err := ParseEnvelope(tt.env)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %t", err, tt.wantErr)
+			}
+			if ev.Type != tt.env.Type || ev.ID != tt.env.ID {
+				t.Fatalf("type/id = %q/%q", ev.Type, ev.ID)
+			}
+			if tt.check != nil {
+				tt.check(t, ev)
+			}
+		})
+	}
+}
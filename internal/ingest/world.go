@@ -0,0 +1,297 @@
+package ingest
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/adapters"
+	"github.com/Rajchodisetti/trading-app/internal/decision"
+	"github.com/Rajchodisetti/trading-app/internal/observ"
+)
+
+// maxTickHistory bounds the ticks retained per symbol for strategies
+const maxTickHistory = 50
+
+// World holds per-symbol state built incrementally from wire events.
+// Every Apply* method returns the symbols whose decision inputs changed.
+type World struct {
+	mu        sync.RWMutex
+	halted    map[string]bool
+	features  map[string]decision.Features
+	ticks     map[string][]Tick     // symbol -> recent ticks, oldest first
+	news      map[string][]NewsItem // symbol -> news in arrival order
+	seenHash  map[string]bool
+	byID      map[string][]string // news id -> tickers it was recorded under
+	retracted map[string]string   // superseded news id -> correction id
+	earnings  []decision.EarningsEvent
+	symbols   map[string]bool
+}
+
+// NewWorld creates an empty world state
+func NewWorld() *World {
+	return &World{
+		halted:    make(map[string]bool),
+		features:  make(map[string]decision.Features),
+		ticks:     make(map[string][]Tick),
+		news:      make(map[string][]NewsItem),
+		seenHash:  make(map[string]bool),
+		byID:      make(map[string][]string),
+		retracted: make(map[string]string),
+		symbols:   make(map[string]bool),
+	}
+}
+
+// Apply routes a parsed event to the matching handler
+func (w *World) Apply(ev Event) []string {
+	switch {
+	case ev.News != nil:
+		return w.ApplyNews(*ev.News)
+	case ev.Tick != nil:
+		return w.ApplyTick(*ev.Tick)
+	case ev.Halt != nil:
+		return w.ApplyHalt(*ev.Halt)
+	case ev.Earnings != nil:
+		return w.ApplyEarnings(*ev.Earnings)
+	}
+	return nil
+}
+
+// ApplyNews records a news item for each of its tickers, deduplicating by headline hash.
+// A correction retracts the item it supersedes; the superseded item's tickers are
+// reported as affected so their decisions are re-evaluated. A correction that
+// arrives before the original still retracts it once it shows up.
+func (w *World) ApplyNews(n NewsItem) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if n.HeadlineHash != "" {
+		if w.seenHash[n.HeadlineHash] {
+			return nil
+		}
+		w.seenHash[n.HeadlineHash] = true
+	}
+
+	affected := map[string]bool{}
+	var tickers []string
+	for _, sym := range n.Tickers {
+		sym = strings.ToUpper(sym)
+		w.news[sym] = append(w.news[sym], n)
+		w.symbols[sym] = true
+		tickers = append(tickers, sym)
+		affected[sym] = true
+	}
+	if n.ID != "" {
+		w.byID[n.ID] = tickers
+	}
+
+	if n.SupersedesID != nil && *n.SupersedesID != "" {
+		supersededID := *n.SupersedesID
+		w.retracted[supersededID] = n.ID
+		for _, sym := range w.byID[supersededID] {
+			affected[sym] = true
+		}
+		observ.IncCounter("news_retractions_total", map[string]string{"provider": n.Provider})
+		observ.Log("news_retraction", map[string]any{
+			"superseded_id": supersededID,
+			"correction_id": n.ID,
+			"known":         len(w.byID[supersededID]) > 0,
+		})
+	}
+
+	out := make([]string, 0, len(affected))
+	for sym := range affected {
+		out = append(out, sym)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ApplyTick replaces the symbol's features with the latest tick
+func (w *World) ApplyTick(t Tick) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sym := strings.ToUpper(t.Symbol)
+	if sym == "" {
+		return nil
+	}
+
+	// Calculate spread in basis points
+	spreadBps := 0.0
+	if t.Ask > 0 && t.Bid > 0 {
+		spreadBps = ((t.Ask - t.Bid) / ((t.Ask + t.Bid) / 2)) * 10000
+	}
+	w.features[sym] = decision.Features{
+		Symbol:     sym,
+		Halted:     t.Halted,
+		Last:       t.Last,
+		VWAP5m:     t.VWAP5m,
+		RelVolume:  t.RelVolume,
+		Premarket:  t.Premarket,
+		Postmarket: t.Postmarket,
+		SpreadBps:  spreadBps,
+		Bid:        t.Bid,
+		Ask:        t.Ask,
+		SSRActive:  t.SSRActive,
+	}
+	w.ticks[sym] = append(w.ticks[sym], t)
+	if n := len(w.ticks[sym]); n > maxTickHistory {
+		w.ticks[sym] = w.ticks[sym][n-maxTickHistory:]
+	}
+	w.symbols[sym] = true
+	return []string{sym}
+}
+
+// ApplyHalt records the halt status for a symbol
+func (w *World) ApplyHalt(h Halt) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sym := strings.ToUpper(h.Symbol)
+	if sym == "" {
+		return nil
+	}
+	w.halted[sym] = h.Halted
+	w.symbols[sym] = true
+	return []string{sym}
+}
+
+// ApplyEarnings records an earnings window; invalid timestamps are dropped
+func (w *World) ApplyEarnings(e Earnings) []string {
+	startUTC, err := time.Parse(time.RFC3339, e.StartUTC)
+	if err != nil {
+		return nil
+	}
+	endUTC, err := time.Parse(time.RFC3339, e.EndUTC)
+	if err != nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sym := strings.ToUpper(e.Symbol)
+	w.earnings = append(w.earnings, decision.EarningsEvent{
+		Symbol:   sym,
+		StartUTC: startUTC,
+		EndUTC:   endUTC,
+		Status:   e.Status,
+	})
+	w.symbols[sym] = true
+	return []string{sym}
+}
+
+// ApplyQuote enriches the symbol's features with a live quote. A symbol
+// without tick features gets features from the quote alone, with the last
+// price standing in for VWAP.
+func (w *World) ApplyQuote(quote *adapters.Quote) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sym := strings.ToUpper(quote.Symbol)
+	if sym == "" {
+		return false
+	}
+	existing, ok := w.features[sym]
+	if !ok {
+		w.features[sym] = decision.Features{
+			Symbol:     sym,
+			Halted:     quote.Halted,
+			Last:       quote.Last,
+			VWAP5m:     quote.Last, // Use last as fallback for VWAP
+			RelVolume:  1.0,        // Default relative volume
+			Premarket:  quote.Session == "PRE",
+			Postmarket: quote.Session == "POST",
+			SpreadBps:  quote.SpreadBps(),
+			Bid:        quote.Bid,
+			Ask:        quote.Ask,
+		}
+		w.symbols[sym] = true
+		return true
+	}
+	w.features[sym] = decision.Features{
+		Symbol:     sym,
+		Halted:     quote.Halted || existing.Halted, // Respect halt from both sources
+		Last:       quote.Last,
+		VWAP5m:     existing.VWAP5m,    // Keep existing VWAP
+		RelVolume:  existing.RelVolume, // Keep existing relative volume
+		Premarket:  quote.Session == "PRE",
+		Postmarket: quote.Session == "POST",
+		SpreadBps:  quote.SpreadBps(),
+		Bid:        quote.Bid,
+		Ask:        quote.Ask,
+		SSRActive:  existing.SSRActive, // Quotes carry no SSR flag
+	}
+	return true
+}
+
+// HasFeatures reports whether tick or quote features exist for a symbol
+func (w *World) HasFeatures(symbol string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	_, ok := w.features[symbol]
+	return ok
+}
+
+// Features returns the symbol's features. It is halted if either the latest
+// tick or quote or the halt feed says so; a halt event never clears a halt a
+// tick reported.
+func (w *World) Features(symbol string) decision.Features {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	feat := w.features[symbol]
+	if feat.Symbol == "" {
+		feat.Symbol = symbol
+	}
+	feat.Halted = feat.Halted || w.halted[symbol]
+	return feat
+}
+
+// News returns a copy of the news items recorded for a symbol, with retractions applied
+func (w *World) News(symbol string) []NewsItem {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	items := make([]NewsItem, len(w.news[symbol]))
+	copy(items, w.news[symbol])
+	for i := range items {
+		items[i].RetractedBy = w.retracted[items[i].ID]
+	}
+	return items
+}
+
+// Ticks returns a copy of the recent ticks recorded for a symbol
+func (w *World) Ticks(symbol string) []Tick {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	ticks := make([]Tick, len(w.ticks[symbol]))
+	copy(ticks, w.ticks[symbol])
+	return ticks
+}
+
+// Earnings returns a copy of all known earnings windows
+func (w *World) Earnings() []decision.EarningsEvent {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	events := make([]decision.EarningsEvent, len(w.earnings))
+	copy(events, w.earnings)
+	return events
+}
+
+// Symbols returns every symbol seen so far in sorted order
+func (w *World) Symbols() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	syms := make([]string, 0, len(w.symbols))
+	for sym := range w.symbols {
+		syms = append(syms, sym)
+	}
+	sort.Strings(syms)
+	return syms
+}
@@ -0,0 +1,139 @@
+package ingest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Rajchodisetti/trading-app/internal/adapters"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestWorld_CorrectionRetractsSupersededNews(t *testing.T) {
+	w := NewWorld()
+
+	orig := NewsItem{ID: "reuters-010", Provider: "reuters", Tickers: []string{"mega"}, HeadlineHash: "h3"}
+	corr := NewsItem{ID: "reuters-010-corr", Provider: "reuters", Tickers: []string{"MEGA"}, HeadlineHash: "h4",
+		IsCorrection: true, SupersedesID: strPtr("reuters-010")}
+
+	if got := w.ApplyNews(orig); !reflect.DeepEqual(got, []string{"MEGA"}) {
+		t.Fatalf("affected = %v", got)
+	}
+	if got := w.ApplyNews(corr); !reflect.DeepEqual(got, []string{"MEGA"}) {
+		t.Fatalf("affected = %v", got)
+	}
+
+	news := w.News("MEGA")
+	if len(news) != 2 {
+		t.Fatalf("want 2 items, got %d", len(news))
+	}
+	if news[0].RetractedBy != "reuters-010-corr" || news[1].RetractedBy != "" {
+		t.Fatalf("retraction not applied: %+v", news)
+	}
+}
+
+func TestWorld_CorrectionBeforeOriginal(t *testing.T) {
+	w := NewWorld()
+
+	// Correction for a story on another ticker arrives first
+	w.ApplyNews(NewsItem{ID: "c1", Tickers: []string{"AAPL"}, SupersedesID: strPtr("o1")})
+	w.ApplyNews(NewsItem{ID: "o1", Tickers: []string{"NVDA"}})
+
+	if got := w.News("NVDA")[0].RetractedBy; got != "c1" {
+		t.Fatalf("late original should be retracted, got %q", got)
+	}
+
+	// A second correction re-affects the original's tickers
+	if got := w.ApplyNews(NewsItem{ID: "c2", Tickers: []string{"AAPL"}, SupersedesID: strPtr("o1")}); !reflect.DeepEqual(got, []string{"AAPL", "NVDA"}) {
+		t.Fatalf("affected = %v", got)
+	}
+}
+
+func TestWorld_ApplyRoutesEvents(t *testing.T) {
+	w := NewWorld()
+
+	if got := w.Apply(Event{Tick: &Tick{Symbol: "aapl", Last: 100, Bid: 99.95, Ask: 100.05}}); !reflect.DeepEqual(got, []string{"AAPL"}) {
+		t.Fatalf("tick affected = %v", got)
+	}
+	if got := w.Apply(Event{Halt: &Halt{Symbol: "biox", Halted: true}}); !reflect.DeepEqual(got, []string{"BIOX"}) {
+		t.Fatalf("halt affected = %v", got)
+	}
+	if got := w.Apply(Event{Earnings: &Earnings{Symbol: "nvda", StartUTC: "bad", EndUTC: "bad"}}); got != nil {
+		t.Fatalf("invalid earnings affected = %v", got)
+	}
+	if got := w.Apply(Event{Type: "watermark"}); got != nil {
+		t.Fatalf("watermark affected = %v", got)
+	}
+
+	feat := w.Features("AAPL")
+	if feat.Last != 100 || feat.SpreadBps < 9.99 || feat.SpreadBps > 10.01 {
+		t.Fatalf("features = %+v", feat)
+	}
+	if got := w.Symbols(); !reflect.DeepEqual(got, []string{"AAPL", "BIOX"}) {
+		t.Fatalf("symbols = %v", got)
+	}
+}
+
+func TestWorld_TickHistoryBounded(t *testing.T) {
+	w := NewWorld()
+	for i := 0; i < maxTickHistory+10; i++ {
+		w.ApplyTick(Tick{Symbol: "AAPL", Last: float64(i)})
+	}
+	ticks := w.Ticks("AAPL")
+	if len(ticks) != maxTickHistory || ticks[len(ticks)-1].Last != float64(maxTickHistory+9) {
+		t.Fatalf("kept %d ticks ending at %v", len(ticks), ticks[len(ticks)-1].Last)
+	}
+}
+
+func TestWorld_HaltFromEitherSource(t *testing.T) {
+	w := NewWorld()
+
+	// A halt feed that has not caught up must not clear a tick's halt
+	w.ApplyTick(Tick{Symbol: "AAPL", Last: 100, Halted: true})
+	w.ApplyHalt(Halt{Symbol: "AAPL", Halted: false})
+	if !w.Features("AAPL").Halted {
+		t.Fatal("halt event cleared the tick's halt")
+	}
+
+	// Nor may a later tick clear the halt feed's
+	w.ApplyHalt(Halt{Symbol: "NVDA", Halted: true})
+	w.ApplyTick(Tick{Symbol: "NVDA", Last: 450})
+	if !w.Features("NVDA").Halted {
+		t.Fatal("tick cleared the halt feed's halt")
+	}
+
+	// Once both sources resume, the symbol trades
+	w.ApplyTick(Tick{Symbol: "AAPL", Last: 101})
+	if w.Features("AAPL").Halted {
+		t.Fatal("still halted after both sources resumed")
+	}
+}
+
+func TestWorld_ApplyQuote(t *testing.T) {
+	w := NewWorld()
+
+	// A quote enriches tick features but keeps what only ticks carry
+	w.ApplyTick(Tick{Symbol: "AAPL", Last: 100, VWAP5m: 99, RelVolume: 2.5, SSRActive: true, Halted: true})
+	if !w.ApplyQuote(&adapters.Quote{Symbol: "AAPL", Bid: 100.9, Ask: 101.1, Last: 101, Session: "PRE"}) {
+		t.Fatal("quote not applied")
+	}
+	feat := w.Features("AAPL")
+	if feat.Last != 101 || feat.VWAP5m != 99 || feat.RelVolume != 2.5 || !feat.SSRActive || !feat.Halted || !feat.Premarket {
+		t.Fatalf("enriched features = %+v", feat)
+	}
+
+	// A symbol no tick has covered is priced from the quote alone
+	if !w.ApplyQuote(&adapters.Quote{Symbol: "msft", Bid: 399.9, Ask: 400.1, Last: 400, Session: "RTH"}) {
+		t.Fatal("quote-only symbol not applied")
+	}
+	if !w.HasFeatures("MSFT") {
+		t.Fatal("quote-only symbol has no features")
+	}
+	feat = w.Features("MSFT")
+	if feat.Last != 400 || feat.VWAP5m != 400 || feat.RelVolume != 1.0 || feat.Bid != 399.9 || feat.SpreadBps == 0 {
+		t.Fatalf("quote-only features = %+v", feat)
+	}
+	if got := w.Symbols(); !reflect.DeepEqual(got, []string{"AAPL", "MSFT"}) {
+		t.Fatalf("symbols = %v", got)
+	}
+}
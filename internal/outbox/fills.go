@@ -1,6 +1,7 @@
 package outbox
 
 import (
+	"math"
 	"math/rand"
 	"time"
 )
@@ -10,8 +11,22 @@ type FillSimulator struct {
 	latencyMsMax  int
 	slippageBpsMin int
 	slippageBpsMax int
+	partial        PartialFillConfig
+	rng            *rand.Rand // nil draws from the global source
 }
 
+// PartialFillConfig controls how large orders are split across fills.
+// Each slice may take ParticipationRate of the volume the symbol trades
+// in one interval, estimated from the quote's daily volume.
+type PartialFillConfig struct {
+	ParticipationRate float64 // 0 disables partial fills
+	MinOrderQty       int     // orders of at most this many shares fill in one shot
+	IntervalMs        int     // simulated time between slices
+}
+
+// sessionSeconds is the length of the regular session used to spread daily volume
+const sessionSeconds = 6.5 * 60 * 60
+
 func NewFillSimulator(latencyMsMin, latencyMsMax, slippageBpsMin, slippageBpsMax int) *FillSimulator {
 	return &FillSimulator{
 		latencyMsMin:   latencyMsMin,
@@ -21,9 +36,78 @@ func NewFillSimulator(latencyMsMin, latencyMsMax, slippageBpsMin, slippageBpsMax
 	}
 }
 
+// SetPartialFills enables partial fills; a zero config restores full fills
+func (fs *FillSimulator) SetPartialFills(cfg PartialFillConfig) {
+	if cfg.IntervalMs <= 0 {
+		cfg.IntervalMs = 1000
+	}
+	fs.partial = cfg
+}
+
+// SetSeed makes latency and slippage draws repeatable. The seeded source is
+// not safe for concurrent use; callers must serialize draws.
+func (fs *FillSimulator) SetSeed(seed int64) {
+	fs.rng = rand.New(rand.NewSource(seed))
+}
+
+// intn draws from the seeded source when one is set
+func (fs *FillSimulator) intn(n int) int {
+	if fs.rng != nil {
+		return fs.rng.Intn(n)
+	}
+	return rand.Intn(n)
+}
+
+// SliceInterval is the simulated time between partial fills
+func (fs *FillSimulator) SliceInterval() time.Duration {
+	return time.Duration(fs.partial.IntervalMs) * time.Millisecond
+}
+
+// Capacity returns how many shares of a symbol may execute in one slice given
+// its daily volume, or -1 when partial fills are disabled or volume is unknown.
+// Liquid symbols always allow at least one share per slice.
+func (fs *FillSimulator) Capacity(dailyVolume int64) int {
+	if fs.partial.ParticipationRate <= 0 || dailyVolume <= 0 {
+		return -1
+	}
+	perInterval := float64(dailyVolume) / sessionSeconds * float64(fs.partial.IntervalMs) / 1000
+	capacity := int(perInterval * fs.partial.ParticipationRate)
+	if capacity < 1 {
+		capacity = 1
+	}
+	return capacity
+}
+
+// SliceQuantity returns how much of an order's remaining quantity fills now.
+// Orders at or below the minimum size fill in full; larger ones take what is
+// left of the slice capacity after orders ahead of them in the queue.
+func (fs *FillSimulator) SliceQuantity(orderQty, remaining, capacity int) int {
+	if capacity < 0 || orderQty <= fs.partial.MinOrderQty {
+		return remaining
+	}
+	if remaining < capacity {
+		return remaining
+	}
+	return capacity
+}
+
 func (fs *FillSimulator) SimulateFill(order Order, marketPrice float64) (Fill, time.Duration) {
-	latencyMs := fs.latencyMsMin + rand.Intn(fs.latencyMsMax-fs.latencyMsMin+1)
-	slippageBps := fs.slippageBpsMin + rand.Intn(fs.slippageBpsMax-fs.slippageBpsMin+1)
+	latency := fs.SampleLatency()
+	fill := fs.FillAt(order, marketPrice, time.Now().UTC().Add(latency))
+	fill.LatencyMs = int(latency / time.Millisecond)
+	return fill, latency
+}
+
+// SampleLatency draws a venue latency from the configured range
+func (fs *FillSimulator) SampleLatency() time.Duration {
+	latencyMs := fs.latencyMsMin + fs.intn(fs.latencyMsMax-fs.latencyMsMin+1)
+	return time.Duration(latencyMs) * time.Millisecond
+}
+
+// FillAt fills the order at marketPrice plus sampled slippage, stamped at ts.
+// Limit orders never fill through their limit price.
+func (fs *FillSimulator) FillAt(order Order, marketPrice float64, ts time.Time) Fill {
+	slippageBps := fs.slippageBpsMin + fs.intn(fs.slippageBpsMax-fs.slippageBpsMin+1)
 	
 	var quantity float64
 	var side string
@@ -35,31 +119,52 @@ func (fs *FillSimulator) SimulateFill(order Order, marketPrice float64) (Fill, t
 	case "BUY_5X":
 		quantity = 5.0
 		side = "BUY"
-	case "REDUCE":
+	case "REDUCE", "EXIT", "SELL_SHORT":
 		quantity = 1.0
 		side = "SELL"
+	case "COVER":
+		quantity = 1.0
+		side = "BUY"
 	default:
 		quantity = 0
 		side = "NONE"
 	}
 	
+	// Sized orders carry their own side and quantity; the table above is the legacy fallback
+	if order.Side == "BUY" || order.Side == "SELL" {
+		side = order.Side
+	}
+	if order.Quantity > 0 && side != "NONE" {
+		quantity = float64(order.Quantity)
+	}
+	
 	slippageMultiplier := 1.0 + float64(slippageBps)/10000.0
+	price := marketPrice
 	if side == "BUY" {
-		marketPrice *= slippageMultiplier
+		price *= slippageMultiplier
 	} else if side == "SELL" {
-		marketPrice /= slippageMultiplier
+		price /= slippageMultiplier
 	}
 	
-	fill := Fill{
+	// A limit caps slippage: never pay more (or receive less) than the limit
+	if order.LimitPrice > 0 {
+		if side == "BUY" && price > order.LimitPrice {
+			price = order.LimitPrice
+		} else if side == "SELL" && price < order.LimitPrice {
+			price = order.LimitPrice
+		}
+		if marketPrice > 0 {
+			slippageBps = int(math.Round(math.Abs(price-marketPrice) / marketPrice * 10000))
+		}
+	}
+	
+	return Fill{
 		OrderID:     order.ID,
 		Symbol:      order.Symbol,
 		Quantity:    quantity,
-		Price:       marketPrice,
+		Price:       price,
 		Side:        side,
-		Timestamp:   time.Now().UTC().Add(time.Duration(latencyMs) * time.Millisecond),
-		LatencyMs:   latencyMs,
+		Timestamp:   ts,
 		SlippageBps: slippageBps,
 	}
-	
-	return fill, time.Duration(latencyMs) * time.Millisecond
-}
\ No newline at end of file
+}
@@ -0,0 +1,105 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/Rajchodisetti/trading-app/internal/durable"
+	"github.com/Rajchodisetti/trading-app/internal/observ"
+)
+
+// Each line is the JSON entry with a trailing "crc" member holding the
+// CRC-32C of the line as it would be without that member:
+//
+//	{"type":"order","data":{...},"event":"...","crc":"1a2b3c4d"}
+//
+// Lines written before checksums existed have no "crc" and are accepted as-is.
+const crcPrefix = `,"crc":"`
+
+// crcSuffixLen is len(`,"crc":"` + 8 hex digits + `"}`)
+const crcSuffixLen = len(crcPrefix) + 8 + 2
+
+// encodeRecord serializes an entry as one checksummed, newline-terminated line
+func encodeRecord(entry OutboxEntry) ([]byte, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	sum := durable.Checksum(data)
+	line := make([]byte, 0, len(data)+crcSuffixLen)
+	line = append(line, data[:len(data)-1]...)
+	line = append(line, crcPrefix...)
+	line = append(line, sum...)
+	line = append(line, '"', '}', '\n')
+	return line, nil
+}
+
+// validRecord reports whether a line (without its newline) is intact
+func validRecord(line []byte) bool {
+	n := len(line)
+	if n > crcSuffixLen && bytes.Equal(line[n-crcSuffixLen:n-crcSuffixLen+len(crcPrefix)], []byte(crcPrefix)) {
+		body := make([]byte, 0, n-crcSuffixLen+1)
+		body = append(body, line[:n-crcSuffixLen]...)
+		body = append(body, '}')
+		sum := string(line[n-crcSuffixLen+len(crcPrefix) : n-2])
+		return durable.Checksum(body) == sum
+	}
+	return json.Valid(line)
+}
+
+// repairTail truncates a torn tail from the active segment: a final line
+// without its newline, or trailing lines that fail their checksum, as left by
+// a crash mid-append. Damage followed by intact records is left for readers
+// to skip. It returns the resulting file size.
+func repairTail(path string) (int64, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+
+	var offset, lastGood int64
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		offset += int64(len(line))
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			body := bytes.TrimRight(line, "\r\n")
+			if len(body) == 0 || validRecord(body) {
+				lastGood = offset
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if lastGood == size {
+		return size, nil
+	}
+	if err := f.Truncate(lastGood); err != nil {
+		return 0, err
+	}
+	if err := f.Sync(); err != nil {
+		return 0, err
+	}
+	observ.IncCounter("outbox_torn_tail_truncated_total", nil)
+	observ.Log("outbox_torn_tail_truncated", map[string]any{
+		"path":            path,
+		"truncated_bytes": size - lastGood,
+		"size":            lastGood,
+	})
+	return lastGood, nil
+}
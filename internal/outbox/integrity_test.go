@@ -0,0 +1,82 @@
+package outbox
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Rajchodisetti/trading-app/internal/durable"
+)
+
+func TestOutbox_TruncatesTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	ob, err := New(path, 90)
+	require.NoError(t, err)
+	require.NoError(t, ob.WriteOrder(Order{ID: "o1", IdempotencyKey: "k1", Status: "SENT"}))
+	require.NoError(t, ob.WriteFill(Fill{OrderID: "o1", Quantity: 1, Price: 10}))
+	require.NoError(t, ob.Close())
+
+	intact, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// Power loss mid-append: half a record, no newline
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"type":"order","data":{"id":"o2","sta`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	ob, err = New(path, 90)
+	require.NoError(t, err)
+	defer ob.Close()
+
+	repaired, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, intact, repaired)
+
+	// Appends continue cleanly after the repair
+	require.NoError(t, ob.WriteOrder(Order{ID: "o2", IdempotencyKey: "k2", Status: "SENT"}))
+	open, err := ob.OpenOrders()
+	require.NoError(t, err)
+	require.Len(t, open, 2)
+	assert.Equal(t, "o2", open[1].Order.ID)
+}
+
+func TestOutbox_SkipsCorruptRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	ob, err := NewWithOptions(path, 90, Options{Sync: durable.SyncConfig{Policy: durable.SyncBatched, BatchInterval: time.Millisecond}})
+	require.NoError(t, err)
+	for _, id := range []string{"o1", "o2", "o3"} {
+		require.NoError(t, ob.WriteOrder(Order{ID: id, Symbol: "AAPL", Status: "SENT"}))
+	}
+	require.NoError(t, ob.Close())
+
+	// Flip a byte inside the second record; the checksum no longer matches
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	lines[1] = bytes.Replace(lines[1], []byte("AAPL"), []byte("AAPX"), 1)
+	require.NoError(t, os.WriteFile(path, bytes.Join(lines, nil), 0644))
+
+	ob, err = New(path, 90)
+	require.NoError(t, err)
+	defer ob.Close()
+	var ids []string
+	require.NoError(t, ob.Iterate(func(r Record) error {
+		o, err := r.Order()
+		require.NoError(t, err)
+		ids = append(ids, o.ID)
+		return nil
+	}))
+	assert.Equal(t, []string{"o1", "o3"}, ids)
+}
+
+func TestValidRecord_AcceptsLegacyLines(t *testing.T) {
+	assert.True(t, validRecord([]byte(`{"type":"fill","data":{"order_id":"x"},"event":"2025-08-21T19:17:19Z"}`)))
+	assert.False(t, validRecord([]byte(`{"type":"fill","data":{"order_id":"x"`)))
+}
@@ -2,11 +2,20 @@ package outbox
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/clock"
+	"github.com/Rajchodisetti/trading-app/internal/durable"
+	"github.com/Rajchodisetti/trading-app/internal/observ"
 )
 
+// indexPruneMin is the smallest index size at which expired keys are pruned
+const indexPruneMin = 1024
+
 type Order struct {
 	ID          string    `json:"id"`
 	Symbol      string    `json:"symbol"`
@@ -14,6 +23,15 @@ type Order struct {
 	Timestamp   time.Time `json:"timestamp"`
 	Status      string    `json:"status"`
 	IdempotencyKey string `json:"idempotency_key"`
+	Side        string    `json:"side,omitempty"`         // BUY | SELL; derived from intent when empty
+	Quantity    int       `json:"quantity,omitempty"`     // shares; 0 = legacy fixed size by intent
+	NotionalUSD float64   `json:"notional_usd,omitempty"` // quantity x reference price at sizing
+	OrderType   string    `json:"order_type,omitempty"`    // MKT | LMT | IOC | MARKETABLE_LIMIT
+	LimitPrice  float64   `json:"limit_price,omitempty"`   // worst acceptable price for limit types
+	TimeInForce string    `json:"time_in_force,omitempty"` // DAY | IOC
+	FilledQty   int       `json:"filled_qty,omitempty"`    // shares filled so far, for PARTIAL updates
+	Reason      string    `json:"reason,omitempty"`        // why canceled, rejected or expired
+	Guard       json.RawMessage `json:"guard,omitempty"`   // pre-send guard result for guard cancellations
 }
 
 type Fill struct {
@@ -25,6 +43,8 @@ type Fill struct {
 	Timestamp    time.Time `json:"timestamp"`
 	LatencyMs    int       `json:"latency_ms"`
 	SlippageBps  int       `json:"slippage_bps"`
+	Commission   float64   `json:"commission,omitempty"` // broker commission charged on this fill
+	Fees         float64   `json:"fees,omitempty"`       // regulatory fees (SEC, TAF) charged on this fill
 }
 
 type OutboxEntry struct {
@@ -33,112 +53,286 @@ type OutboxEntry struct {
 	Event time.Time   `json:"event"`
 }
 
+// Outbox is the append-only order/fill journal. The active segment lives at
+// path; rotated segments sit next to it (see SegmentConfig). Idempotency keys
+// are kept in memory so dedupe checks never touch the disk. Every line carries
+// a CRC so torn or corrupted records are detected on read.
 type Outbox struct {
-	path string
+	mu           sync.Mutex
+	path         string
 	dedupeWindow time.Duration
+	segments     SegmentConfig
+	sync         durable.SyncConfig
+	clock        clock.Clock // stamps order and fill events
+
+	index      map[string]time.Time // idempotency key -> latest order event
+	pruneAt    int                  // index size that triggers the next prune
+	file       *os.File             // active segment, opened on first write
+	activeSize int64
+	activeDate string // UTC date (YYYY-MM-DD) of the active segment
+	unsynced   int    // writes since the last fsync
+
+	stop      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// Options configures rotation and durability of an outbox
+type Options struct {
+	Segments SegmentConfig
+	Sync     durable.SyncConfig // zero value syncs every write
+	Clock    clock.Clock        // nil uses the wall clock
 }
 
 func New(path string, dedupeWindowSecs int) (*Outbox, error) {
+	return NewWithOptions(path, dedupeWindowSecs, Options{})
+}
+
+// NewWithOptions opens an outbox, truncating a torn tail left by a crash,
+// and rebuilds the idempotency index from the segments inside the dedupe window
+func NewWithOptions(path string, dedupeWindowSecs int, opts Options) (*Outbox, error) {
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return nil, err
 	}
+	if opts.Sync.Policy == "" {
+		opts.Sync.Policy = durable.SyncAlways
+	}
+	if opts.Sync.BatchRecords <= 0 {
+		opts.Sync.BatchRecords = 64
+	}
+	if opts.Sync.BatchInterval <= 0 {
+		opts.Sync.BatchInterval = 100 * time.Millisecond
+	}
 	
-	return &Outbox{
-		path: path,
+	o := &Outbox{
+		path:         path,
 		dedupeWindow: time.Duration(dedupeWindowSecs) * time.Second,
-	}, nil
+		segments:     opts.Segments,
+		sync:         opts.Sync,
+		clock:        clock.Or(opts.Clock),
+		index:        make(map[string]time.Time),
+		pruneAt:      indexPruneMin,
+		activeDate:   clock.Or(opts.Clock).Now().UTC().Format(dateLayout),
+		stop:         make(chan struct{}),
+	}
+	if info, err := os.Stat(path); err == nil {
+		o.activeDate = info.ModTime().UTC().Format(dateLayout)
+		size, err := repairTail(path)
+		if err != nil {
+			return nil, fmt.Errorf("repair outbox tail: %w", err)
+		}
+		o.activeSize = size
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := o.rebuildIndex(o.clock.Now().UTC()); err != nil {
+		return nil, fmt.Errorf("rebuild outbox index: %w", err)
+	}
+	
+	if o.sync.Policy == durable.SyncBatched {
+		o.wg.Add(1)
+		go o.syncLoop()
+	}
+	return o, nil
 }
 
 func (o *Outbox) WriteOrder(order Order) error {
 	entry := OutboxEntry{
 		Type:  "order",
 		Data:  order,
-		Event: time.Now().UTC(),
+		Event: o.clock.Now().UTC(),
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.appendEntryLocked(entry); err != nil {
+		return err
 	}
-	return o.appendEntry(entry)
+	o.indexOrderLocked(order.IdempotencyKey, entry.Event)
+	return nil
 }
 
 func (o *Outbox) WriteFill(fill Fill) error {
 	entry := OutboxEntry{
 		Type:  "fill",
 		Data:  fill,
-		Event: time.Now().UTC(),
+		Event: o.clock.Now().UTC(),
 	}
-	return o.appendEntry(entry)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.appendEntryLocked(entry)
 }
 
-func (o *Outbox) appendEntry(entry OutboxEntry) error {
-	data, err := json.Marshal(entry)
+func (o *Outbox) appendEntryLocked(entry OutboxEntry) error {
+	line, err := encodeRecord(entry)
 	if err != nil {
 		return err
 	}
 	
-	f, err := os.OpenFile(o.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
+	if o.shouldRotateLocked(entry.Event, int64(len(line))) {
+		if err := o.rotateLocked(entry.Event); err != nil {
+			return fmt.Errorf("rotate outbox: %w", err)
+		}
 	}
-	defer f.Close()
 	
-	_, err = f.WriteString(string(data) + "\n")
-	return err
-}
-
-func (o *Outbox) HasRecentOrder(idempotencyKey string) (bool, error) {
-	if _, err := os.Stat(o.path); os.IsNotExist(err) {
-		return false, nil
+	if o.file == nil {
+		if err := o.openActiveLocked(); err != nil {
+			return err
+		}
 	}
 	
-	data, err := os.ReadFile(o.path)
+	n, err := o.file.Write(line)
+	o.activeSize += int64(n)
 	if err != nil {
-		return false, err
+		return err
 	}
 	
-	cutoff := time.Now().UTC().Add(-o.dedupeWindow)
-	lines := string(data)
-	
-	for _, line := range splitLines(lines) {
-		if line == "" {
-			continue
-		}
-		
-		var entry OutboxEntry
-		if err := json.Unmarshal([]byte(line), &entry); err != nil {
-			continue
+	o.unsynced++
+	switch o.sync.Policy {
+	case durable.SyncAlways:
+		return o.syncLocked()
+	case durable.SyncBatched:
+		if o.unsynced >= o.sync.BatchRecords {
+			return o.syncLocked()
 		}
-		
-		if entry.Type != "order" || entry.Event.Before(cutoff) {
-			continue
-		}
-		
-		orderData, err := json.Marshal(entry.Data)
-		if err != nil {
-			continue
+	}
+	return nil
+}
+
+// openActiveLocked opens the active segment for appending, making a newly
+// created file's directory entry durable
+func (o *Outbox) openActiveLocked() error {
+	_, statErr := os.Stat(o.path)
+	f, err := os.OpenFile(o.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	o.file = f
+	if os.IsNotExist(statErr) && o.sync.Policy != durable.SyncNone {
+		return durable.SyncDir(filepath.Dir(o.path))
+	}
+	return nil
+}
+
+// syncLocked flushes unsynced writes of the active segment
+func (o *Outbox) syncLocked() error {
+	if o.file == nil || o.unsynced == 0 {
+		return nil
+	}
+	if err := o.file.Sync(); err != nil {
+		observ.IncCounter("outbox_fsync_errors_total", nil)
+		return fmt.Errorf("fsync outbox: %w", err)
+	}
+	o.unsynced = 0
+	return nil
+}
+
+// syncLoop bounds how long batched writes stay unsynced
+func (o *Outbox) syncLoop() {
+	defer o.wg.Done()
+	ticker := time.NewTicker(o.sync.BatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-o.stop:
+			return
+		case <-ticker.C:
+			o.mu.Lock()
+			if err := o.syncLocked(); err != nil {
+				observ.Log("outbox_fsync_failed", map[string]any{"error": err.Error()})
+			}
+			o.mu.Unlock()
 		}
+	}
+}
+
+// Sync flushes any unsynced writes to stable storage
+func (o *Outbox) Sync() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.syncLocked()
+}
+
+// Close flushes and closes the active segment
+func (o *Outbox) Close() error {
+	var err error
+	o.closeOnce.Do(func() {
+		close(o.stop)
+		o.wg.Wait()
 		
-		var order Order
-		if err := json.Unmarshal(orderData, &order); err != nil {
-			continue
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		if o.file == nil {
+			return
 		}
-		
-		if order.IdempotencyKey == idempotencyKey {
-			return true, nil
+		err = o.syncLocked()
+		if cerr := o.file.Close(); err == nil {
+			err = cerr
 		}
-	}
+		o.file = nil
+	})
+	return err
+}
+
+// HasRecentOrder reports whether an order with this idempotency key was
+// written within the dedupe window
+func (o *Outbox) HasRecentOrder(idempotencyKey string) (bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
 	
-	return false, nil
+	at, ok := o.index[idempotencyKey]
+	if !ok {
+		return false, nil
+	}
+	return !at.Before(o.clock.Now().UTC().Add(-o.dedupeWindow)), nil
 }
 
-func splitLines(s string) []string {
-	var lines []string
-	start := 0
-	for i, r := range s {
-		if r == '\n' {
-			lines = append(lines, s[start:i])
-			start = i + 1
+// indexOrderLocked records an order's idempotency key, pruning expired keys
+// whenever the index has doubled since the last prune
+func (o *Outbox) indexOrderLocked(key string, at time.Time) {
+	if key == "" {
+		return
+	}
+	if prev, ok := o.index[key]; !ok || at.After(prev) {
+		o.index[key] = at
+	}
+	if len(o.index) >= o.pruneAt {
+		cutoff := o.clock.Now().UTC().Add(-o.dedupeWindow)
+		for k, t := range o.index {
+			if t.Before(cutoff) {
+				delete(o.index, k)
+			}
 		}
+		o.pruneAt = max(2*len(o.index), indexPruneMin)
 	}
-	if start < len(s) {
-		lines = append(lines, s[start:])
+}
+
+// rebuildIndex loads idempotency keys from segments that may hold orders
+// inside the dedupe window
+func (o *Outbox) rebuildIndex(now time.Time) error {
+	cutoff := now.Add(-o.dedupeWindow)
+	paths, err := o.Segments()
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		// Archived segments are named for the day they were closed
+		if date, ok := segmentDate(p); ok && date < cutoff.Format(dateLayout) {
+			continue
+		}
+		err := readSegment(p, func(r Record) error {
+			if r.Type != "order" || r.Event.Before(cutoff) {
+				return nil
+			}
+			order, err := r.Order()
+			if err != nil {
+				return nil
+			}
+			o.indexOrderLocked(order.IdempotencyKey, r.Event)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
 	}
-	return lines
-}
\ No newline at end of file
+	return nil
+}
@@ -0,0 +1,81 @@
+package outbox
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// OrderHistory is an order's latest outbox record plus the fills recorded against it
+type OrderHistory struct {
+	Order      Order
+	FirstSeen  time.Time
+	FilledQty  int
+	FilledCost float64 // sum of fill quantity x price
+}
+
+// TerminalStatus reports whether an outbox order status is final. Legacy
+// lowercase statuses ("cancelled", "filled") are accepted.
+func TerminalStatus(status string) bool {
+	switch strings.ToUpper(status) {
+	case "FILLED", "CANCELED", "CANCELLED", "REJECTED", "EXPIRED":
+		return true
+	}
+	return false
+}
+
+// Replay rebuilds every order's latest state and fill totals from all segments
+func (o *Outbox) Replay() (map[string]*OrderHistory, error) {
+	orders := make(map[string]*OrderHistory)
+	err := o.Iterate(func(r Record) error {
+		switch r.Type {
+		case "order":
+			order, err := r.Order()
+			if err != nil || order.ID == "" {
+				return nil
+			}
+			h, ok := orders[order.ID]
+			if !ok {
+				h = &OrderHistory{FirstSeen: r.Event}
+				orders[order.ID] = h
+			}
+			h.Order = order
+		case "fill":
+			fill, err := r.Fill()
+			if err != nil {
+				return nil
+			}
+			h, ok := orders[fill.OrderID]
+			if !ok {
+				// Fill for an order we never recorded; keep the totals anyway
+				h = &OrderHistory{FirstSeen: r.Event, Order: Order{ID: fill.OrderID, Symbol: fill.Symbol, Side: fill.Side}}
+				orders[fill.OrderID] = h
+			}
+			h.FilledQty += int(fill.Quantity)
+			h.FilledCost += fill.Quantity * fill.Price
+		}
+		return nil
+	})
+	return orders, err
+}
+
+// OpenOrders returns orders whose latest record is not terminal, oldest first
+func (o *Outbox) OpenOrders() ([]OrderHistory, error) {
+	orders, err := o.Replay()
+	if err != nil {
+		return nil, err
+	}
+	var open []OrderHistory
+	for _, h := range orders {
+		if h.Order.Status != "" && !TerminalStatus(h.Order.Status) {
+			open = append(open, *h)
+		}
+	}
+	sort.Slice(open, func(i, j int) bool {
+		if !open[i].FirstSeen.Equal(open[j].FirstSeen) {
+			return open[i].FirstSeen.Before(open[j].FirstSeen)
+		}
+		return open[i].Order.ID < open[j].Order.ID
+	})
+	return open, nil
+}
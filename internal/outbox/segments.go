@@ -0,0 +1,324 @@
+package outbox
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/durable"
+	"github.com/Rajchodisetti/trading-app/internal/observ"
+)
+
+const dateLayout = "2006-01-02"
+
+// maxRecordBytes bounds a single outbox line when reading segments back
+const maxRecordBytes = 4 << 20
+
+// SegmentConfig controls when the active outbox segment is rolled over.
+// Rotated segments are renamed to <base>-<date>-<seq>.jsonl next to the
+// active file, where date is the UTC day the segment was closed.
+type SegmentConfig struct {
+	MaxBytes int64 // rotate before the active segment would exceed this size; 0 disables
+	Daily    bool  // rotate on the first write of a new UTC day
+	Compress bool  // gzip rotated segments
+}
+
+// ErrStop may be returned from an Iterate callback to end iteration early
+var ErrStop = errors.New("stop iteration")
+
+// Record is one outbox line as stored on disk
+type Record struct {
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data"`
+	Event time.Time       `json:"event"`
+}
+
+// Order decodes an order record
+func (r Record) Order() (Order, error) {
+	var order Order
+	if r.Type != "order" {
+		return order, fmt.Errorf("record type %q is not an order", r.Type)
+	}
+	err := json.Unmarshal(r.Data, &order)
+	return order, err
+}
+
+// Fill decodes a fill record
+func (r Record) Fill() (Fill, error) {
+	var fill Fill
+	if r.Type != "fill" {
+		return fill, fmt.Errorf("record type %q is not a fill", r.Type)
+	}
+	err := json.Unmarshal(r.Data, &fill)
+	return fill, err
+}
+
+// Segments returns every segment path, oldest first, with the active segment last
+func (o *Outbox) Segments() ([]string, error) {
+	base := o.segmentBase()
+	matches, err := filepath.Glob(base + "-*.jsonl*")
+	if err != nil {
+		return nil, err
+	}
+
+	// A crash mid-compression can leave both forms; the .gz is only renamed
+	// into place once complete, so it wins
+	seen := make(map[string]bool)
+	var archived []string
+	for _, m := range matches {
+		if strings.HasSuffix(m, ".jsonl.gz") && !seen[strings.TrimSuffix(m, ".gz")] {
+			seen[strings.TrimSuffix(m, ".gz")] = true
+			archived = append(archived, m)
+		}
+	}
+	for _, m := range matches {
+		if strings.HasSuffix(m, ".jsonl") && !seen[m] {
+			archived = append(archived, m)
+		}
+	}
+	sort.Slice(archived, func(i, j int) bool {
+		return strings.TrimSuffix(archived[i], ".gz") < strings.TrimSuffix(archived[j], ".gz")
+	})
+
+	if _, err := os.Stat(o.path); err == nil {
+		archived = append(archived, o.path)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return archived, nil
+}
+
+// Iterate calls fn for every record across all segments, oldest first.
+// Records written after Iterate starts are not visited. Malformed lines are
+// skipped. Returning ErrStop from fn ends iteration without error.
+func (o *Outbox) Iterate(fn func(Record) error) error {
+	o.mu.Lock()
+	paths, err := o.Segments()
+	var active *os.File
+	var activeSize int64
+	if err == nil && len(paths) > 0 && paths[len(paths)-1] == o.path {
+		// Hold the active segment open so a concurrent rotation cannot move it away
+		active, err = os.Open(o.path)
+		activeSize = o.activeSize
+		paths = paths[:len(paths)-1]
+	}
+	o.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if active != nil {
+		defer active.Close()
+	}
+
+	for _, p := range paths {
+		if err := readSegment(p, fn); err != nil {
+			if errors.Is(err, ErrStop) {
+				return nil
+			}
+			return err
+		}
+	}
+	if active != nil {
+		if err := scanRecords(io.LimitReader(active, activeSize), fn); err != nil {
+			if errors.Is(err, ErrStop) {
+				return nil
+			}
+			return fmt.Errorf("read %s: %w", o.path, err)
+		}
+	}
+	return nil
+}
+
+// segmentBase is the active path without its extension
+func (o *Outbox) segmentBase() string {
+	return strings.TrimSuffix(o.path, filepath.Ext(o.path))
+}
+
+// segmentDate extracts the close date from an archived segment name
+func segmentDate(path string) (string, bool) {
+	name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".gz"), ".jsonl")
+	// <base>-YYYY-MM-DD-NNN
+	if len(name) < len("2006-01-02-000") {
+		return "", false
+	}
+	date := name[len(name)-len("2006-01-02-000") : len(name)-len("-000")]
+	if _, err := time.Parse(dateLayout, date); err != nil {
+		return "", false
+	}
+	return date, true
+}
+
+// shouldRotateLocked reports whether writing n more bytes at now should first
+// close the active segment
+func (o *Outbox) shouldRotateLocked(now time.Time, n int64) bool {
+	if o.activeSize == 0 {
+		return false
+	}
+	if o.segments.Daily && now.UTC().Format(dateLayout) != o.activeDate {
+		return true
+	}
+	return o.segments.MaxBytes > 0 && o.activeSize+n > o.segments.MaxBytes
+}
+
+// rotateLocked moves the active segment aside, compressing it if configured
+func (o *Outbox) rotateLocked(now time.Time) error {
+	date := o.activeDate
+	name, err := o.nextArchiveName(date)
+	if err != nil {
+		return err
+	}
+	if o.file != nil {
+		// Whatever the policy, a closed segment is flushed before it moves
+		if err := o.file.Sync(); err != nil {
+			return err
+		}
+		if err := o.file.Close(); err != nil {
+			return err
+		}
+		o.file = nil
+		o.unsynced = 0
+	}
+	if err := os.Rename(o.path, name); err != nil {
+		return err
+	}
+	size := o.activeSize
+	o.activeSize = 0
+	o.activeDate = now.UTC().Format(dateLayout)
+
+	if o.segments.Compress {
+		if err := gzipFile(name); err != nil {
+			// The plain segment is still readable; keep going
+			observ.Log("outbox_compress_failed", map[string]any{"segment": name, "error": err.Error()})
+		} else {
+			name += ".gz"
+		}
+	}
+
+	observ.IncCounter("outbox_rotations_total", nil)
+	observ.Log("outbox_rotated", map[string]any{
+		"segment": name,
+		"bytes":   size,
+		"date":    date,
+	})
+	return nil
+}
+
+// nextArchiveName picks the next free <base>-<date>-<seq>.jsonl name
+func (o *Outbox) nextArchiveName(date string) (string, error) {
+	prefix := fmt.Sprintf("%s-%s-", o.segmentBase(), date)
+	existing, err := filepath.Glob(prefix + "*.jsonl*")
+	if err != nil {
+		return "", err
+	}
+	seq := 1
+	for _, e := range existing {
+		var n int
+		rest := strings.TrimPrefix(e, prefix)
+		if _, err := fmt.Sscanf(rest, "%03d.jsonl", &n); err == nil && n >= seq {
+			seq = n + 1
+		}
+	}
+	return fmt.Sprintf("%s%03d.jsonl", prefix, seq), nil
+}
+
+// gzipFile compresses path to path.gz and removes the original. The
+// compressed file only appears under its final name once fully written.
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := path + ".gz.tmp"
+	dst, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path+".gz"); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	return durable.SyncDir(filepath.Dir(path))
+}
+
+// readSegment streams the records of one segment, decompressing .gz segments
+func readSegment(path string, fn func(Record) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	if err := scanRecords(r, fn); err != nil {
+		if errors.Is(err, ErrStop) {
+			return err
+		}
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	return nil
+}
+
+// scanRecords decodes one record per line, skipping malformed lines and
+// lines whose checksum does not match
+func scanRecords(r io.Reader, fn func(Record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordBytes)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if !validRecord(line) {
+			observ.IncCounter("outbox_corrupt_records_total", nil)
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			continue
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,112 @@
+package outbox
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutbox_RotatesAndIteratesAcrossSegments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	ob, err := NewWithOptions(path, 90, Options{Segments: SegmentConfig{MaxBytes: 600, Compress: true}})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, ob.WriteOrder(Order{ID: fmt.Sprintf("o%d", i), Symbol: "AAPL", Intent: "BUY_1X", IdempotencyKey: fmt.Sprintf("k%d", i)}))
+		require.NoError(t, ob.WriteFill(Fill{OrderID: fmt.Sprintf("o%d", i), Symbol: "AAPL", Quantity: 1, Price: 210, Side: "BUY"}))
+	}
+
+	segments, err := ob.Segments()
+	require.NoError(t, err)
+	require.Greater(t, len(segments), 2)
+	assert.Equal(t, path, segments[len(segments)-1], "active segment is last")
+	for _, s := range segments[:len(segments)-1] {
+		assert.True(t, strings.HasSuffix(s, ".jsonl.gz"), s)
+	}
+
+	var orders, fills []string
+	require.NoError(t, ob.Iterate(func(r Record) error {
+		switch r.Type {
+		case "order":
+			o, err := r.Order()
+			require.NoError(t, err)
+			orders = append(orders, o.ID)
+		case "fill":
+			f, err := r.Fill()
+			require.NoError(t, err)
+			fills = append(fills, f.OrderID)
+		}
+		return nil
+	}))
+	assert.Equal(t, []string{"o0", "o1", "o2", "o3", "o4", "o5", "o6", "o7", "o8", "o9"}, orders)
+	assert.Len(t, fills, 10)
+
+	var seen int
+	require.NoError(t, ob.Iterate(func(r Record) error {
+		seen++
+		return ErrStop
+	}))
+	assert.Equal(t, 1, seen)
+}
+
+func TestOutbox_IndexRebuiltOnStartup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	ob, err := NewWithOptions(path, 90, Options{Segments: SegmentConfig{MaxBytes: 300}})
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, ob.WriteOrder(Order{ID: fmt.Sprintf("o%d", i), IdempotencyKey: fmt.Sprintf("k%d", i), Timestamp: time.Now()}))
+	}
+	ok, err := ob.HasRecentOrder("k0")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	reopened, err := NewWithOptions(path, 90, Options{Segments: SegmentConfig{MaxBytes: 300}})
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		ok, err := reopened.HasRecentOrder(fmt.Sprintf("k%d", i))
+		require.NoError(t, err)
+		assert.True(t, ok, "k%d", i)
+	}
+	ok, err = reopened.HasRecentOrder("missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Nothing is recent with a zero dedupe window
+	expired, err := NewWithOptions(path, 0, Options{})
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	ok, err = expired.HasRecentOrder("k0")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestOutbox_SimulatedClockDrivesEventsAndDedupe(t *testing.T) {
+	start := time.Date(2025, 3, 3, 15, 0, 0, 0, time.UTC)
+	clk := clock.NewSim(start)
+	ob, err := NewWithOptions(filepath.Join(t.TempDir(), "outbox.jsonl"), 90, Options{Clock: clk})
+	require.NoError(t, err)
+	defer ob.Close()
+
+	require.NoError(t, ob.WriteOrder(Order{ID: "o1", Symbol: "AAPL", Intent: "BUY_1X", IdempotencyKey: "k1"}))
+	recent, err := ob.HasRecentOrder("k1")
+	require.NoError(t, err)
+	assert.True(t, recent)
+
+	// Two simulated minutes later the key is outside the dedupe window
+	clk.Advance(2 * time.Minute)
+	recent, err = ob.HasRecentOrder("k1")
+	require.NoError(t, err)
+	assert.False(t, recent)
+
+	require.NoError(t, ob.Iterate(func(r Record) error {
+		assert.Equal(t, start, r.Event)
+		return nil
+	}))
+}
@@ -0,0 +1,190 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/observ"
+)
+
+// Corporate actions the portfolio applies to held positions
+const (
+	ActionSplit        = "split"
+	ActionReverseSplit = "reverse_split"
+	ActionRename       = "rename"
+	ActionDelisting    = "delisting"
+	ActionAcquisition  = "acquisition"
+)
+
+// Position flags set by corporate actions
+const (
+	FlagDelisted = "delisted"
+	FlagAcquired = "acquired"
+)
+
+// CorporateAction is one action on a symbol. From old shares become To new
+// ones in a split or reverse split.
+type CorporateAction struct {
+	ID          string // unique per action; each is applied once
+	Type        string
+	Symbol      string
+	NewSymbol   string // renames
+	From        int
+	To          int
+	EffectiveAt time.Time
+}
+
+// ApplyCorporateAction adjusts the position in the action's symbol:
+//   - splits scale every lot's quantity by To/From and its price by From/To;
+//     the position's fractional share is paid out as cash in lieu at the
+//     adjusted mark
+//   - renames move the position, merging into any held under the new symbol
+//   - delistings and acquisitions flag the position for an operator
+//
+// It reports whether the action was applied; actions already applied and
+// kinds the portfolio does not handle are skipped.
+func (m *Manager) ApplyCorporateAction(a CorporateAction) (bool, error) {
+	if a.ID == "" || a.Symbol == "" {
+		return false, fmt.Errorf("corporate action needs an id and a symbol")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, done := m.state.ActionsApplied[a.ID]; done {
+		return false, nil
+	}
+
+	switch a.Type {
+	case ActionSplit, ActionReverseSplit:
+		if a.From <= 0 || a.To <= 0 {
+			return false, fmt.Errorf("split of %s needs a positive ratio, got %d:%d", a.Symbol, a.From, a.To)
+		}
+		m.splitUnsafe(a)
+	case ActionRename:
+		if a.NewSymbol == "" || a.NewSymbol == a.Symbol {
+			return false, fmt.Errorf("rename of %s needs a new symbol", a.Symbol)
+		}
+		m.renameUnsafe(a)
+	case ActionDelisting:
+		m.flagUnsafe(a.Symbol, FlagDelisted, a.EffectiveAt)
+	case ActionAcquisition:
+		m.flagUnsafe(a.Symbol, FlagAcquired, a.EffectiveAt)
+	default:
+		return false, nil
+	}
+
+	if m.state.ActionsApplied == nil {
+		m.state.ActionsApplied = make(map[string]string)
+	}
+	m.state.ActionsApplied[a.ID] = a.EffectiveAt.UTC().Format(time.RFC3339)
+	m.recalculateExposureUnsafe()
+	observ.IncCounter("portfolio_corporate_actions_total", map[string]string{"type": a.Type})
+	return true, m.saveUnsafe()
+}
+
+// splitUnsafe rescales the position's lots for a split or reverse split
+func (m *Manager) splitUnsafe(a CorporateAction) {
+	pos, exists := m.state.Positions[a.Symbol]
+	if !exists || pos.Quantity == 0 {
+		return
+	}
+	if len(pos.Lots) == 0 {
+		pos.Lots = []Lot{legacyLot(pos)}
+	}
+
+	ratio := float64(a.To) / float64(a.From)
+	mark := pos.LastPrice
+	if mark == 0 {
+		mark = pos.AvgEntryPrice
+	}
+	mark /= ratio
+
+	// Only the position as a whole is rounded to whole shares: each lot keeps
+	// the whole shares its running total reaches, so the one fractional
+	// remainder comes out of a single lot instead of out of every lot
+	var held, kept int
+	var costBefore, costAfter float64
+	lots := make([]Lot, 0, len(pos.Lots))
+	for _, lot := range pos.Lots {
+		shares := float64(lot.Quantity) * ratio
+		lot.Price /= ratio
+		held += lot.Quantity
+		lot.Quantity = held*a.To/a.From - kept // whole shares, toward zero
+		kept += lot.Quantity
+		costBefore += shares * lot.Price
+		costAfter += float64(lot.Quantity) * lot.Price
+		if lot.Quantity != 0 {
+			lots = append(lots, lot)
+		}
+	}
+
+	// What is left over is sold back at the adjusted mark
+	if frac := float64(held)*ratio - float64(kept); frac != 0 {
+		m.state.Cash += frac * mark
+		pnl := frac*mark - (costBefore - costAfter)
+		pos.RealizedPnLToday += pnl
+		m.state.DailyStats.PnLToday += pnl
+	}
+
+	pos.Lots = lots
+	pos.Quantity, pos.AvgEntryPrice = lotTotals(lots)
+	pos.EntryVWAP = pos.AvgEntryPrice
+	if pos.Quantity == 0 {
+		pos.Lots = nil
+	}
+	markUnsafe(&pos, mark, a.EffectiveAt)
+	m.state.Positions[a.Symbol] = pos
+}
+
+// renameUnsafe moves the position to its new symbol
+func (m *Manager) renameUnsafe(a CorporateAction) {
+	pos, exists := m.state.Positions[a.Symbol]
+	if !exists {
+		return
+	}
+	delete(m.state.Positions, a.Symbol)
+
+	if held, ok := m.state.Positions[a.NewSymbol]; ok && held.Quantity != 0 {
+		if len(held.Lots) == 0 {
+			held.Lots = []Lot{legacyLot(held)}
+		}
+		if len(pos.Lots) == 0 && pos.Quantity != 0 {
+			pos.Lots = []Lot{legacyLot(pos)}
+		}
+		held.Lots = append(held.Lots, pos.Lots...)
+		held.Quantity, held.AvgEntryPrice = lotTotals(held.Lots)
+		held.EntryVWAP = held.AvgEntryPrice
+		held.TradeCountToday += pos.TradeCountToday
+		held.RealizedPnLToday += pos.RealizedPnLToday
+		markUnsafe(&held, held.LastPrice, a.EffectiveAt)
+		pos = held
+	}
+	m.state.Positions[a.NewSymbol] = pos
+}
+
+// flagUnsafe marks a held position as needing an operator
+func (m *Manager) flagUnsafe(symbol, flag string, at time.Time) {
+	pos, exists := m.state.Positions[symbol]
+	if !exists || pos.Quantity == 0 {
+		return
+	}
+	pos.Flag = flag
+	pos.FlaggedAt = at.UTC().Format(time.RFC3339)
+	m.state.Positions[symbol] = pos
+	observ.SetGauge("position_flagged", 1, map[string]string{"symbol": symbol, "flag": flag})
+}
+
+// GetFlaggedPositions returns the positions flagged by corporate actions
+func (m *Manager) GetFlaggedPositions() map[string]Position {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	flagged := make(map[string]Position)
+	for symbol, pos := range m.state.Positions {
+		if pos.Flag != "" && pos.Quantity != 0 {
+			flagged[symbol] = pos
+		}
+	}
+	return flagged
+}
@@ -0,0 +1,152 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Lot matching methods: which open lots a closing execution consumes first
+const (
+	LotFIFO        = "fifo"
+	LotLIFO        = "lifo"
+	LotHighestCost = "highest_cost" // the lot that realizes the smallest gain
+)
+
+// DefaultMaxClosedLots is how many closed lots a snapshot keeps by default
+const DefaultMaxClosedLots = 1000
+
+// Entry prices the stop-loss can measure a position's loss from
+const (
+	StopEntryVWAP      = "vwap"       // all open lots, volume weighted
+	StopEntryLatestLot = "latest_lot" // the most recently opened lot
+)
+
+// Lot is one opening execution, or what is left of it after closes.
+// Quantity is signed like the position: negative for a short lot.
+type Lot struct {
+	Quantity int       `json:"quantity"`
+	Price    float64   `json:"price"`
+	OpenedAt time.Time `json:"opened_at"`
+	OrderID  string    `json:"order_id,omitempty"`
+}
+
+// ClosedLot is the part of a lot that one closing execution matched
+type ClosedLot struct {
+	Symbol       string    `json:"symbol"`
+	Quantity     int       `json:"quantity"` // signed like the lot
+	EntryPrice   float64   `json:"entry_price"`
+	ExitPrice    float64   `json:"exit_price"`
+	OpenedAt     time.Time `json:"opened_at"`
+	ClosedAt     time.Time `json:"closed_at"`
+	RealizedPnL  float64   `json:"realized_pnl"`
+	OpenOrderID  string    `json:"open_order_id,omitempty"`
+	CloseOrderID string    `json:"close_order_id,omitempty"`
+}
+
+// HoldingPeriod is how long the lot was held
+func (c ClosedLot) HoldingPeriod() time.Duration {
+	return c.ClosedAt.Sub(c.OpenedAt)
+}
+
+// LongTerm reports whether the lot was held for more than a year
+func (c ClosedLot) LongTerm() bool {
+	return c.ClosedAt.After(c.OpenedAt.AddDate(1, 0, 0))
+}
+
+// ValidateLotMethod accepts the lot methods above and returns the method in
+// effect; empty means FIFO
+func ValidateLotMethod(method string) (string, error) {
+	switch method {
+	case "":
+		return LotFIFO, nil
+	case LotFIFO, LotLIFO, LotHighestCost:
+		return method, nil
+	}
+	return "", fmt.Errorf("unknown lot method %q (want %s, %s or %s)", method, LotFIFO, LotLIFO, LotHighestCost)
+}
+
+// CloseLots matches a closing quantity, signed opposite to the lots, against
+// the open lots in method order. It returns the lots still open in the order
+// they were opened, the matched parts, and any quantity left over once every
+// lot is closed (which reverses the position).
+func CloseLots(symbol string, lots []Lot, quantity int, price float64, method string, at time.Time, orderID string) ([]Lot, []ClosedLot, int) {
+	// Work on a copy: positions handed out by the getters share the slice
+	lots = append([]Lot(nil), lots...)
+	order := make([]int, len(lots))
+	for i := range order {
+		order[i] = i
+	}
+	switch method {
+	case LotLIFO:
+		sort.SliceStable(order, func(a, b int) bool { return order[a] > order[b] })
+	case LotHighestCost:
+		// Longs give up their dearest lots first, shorts their cheapest
+		sort.SliceStable(order, func(a, b int) bool {
+			pa, pb := lots[order[a]].Price, lots[order[b]].Price
+			if lots[order[a]].Quantity < 0 {
+				return pa < pb
+			}
+			return pa > pb
+		})
+	}
+
+	var closed []ClosedLot
+	remaining := absInt(quantity)
+	for _, i := range order {
+		if remaining == 0 {
+			break
+		}
+		lot := &lots[i]
+		n := min(remaining, absInt(lot.Quantity))
+		signed := n
+		if lot.Quantity < 0 {
+			signed = -n
+		}
+		closed = append(closed, ClosedLot{
+			Symbol:       symbol,
+			Quantity:     signed,
+			EntryPrice:   lot.Price,
+			ExitPrice:    price,
+			OpenedAt:     lot.OpenedAt,
+			ClosedAt:     at,
+			RealizedPnL:  float64(signed) * (price - lot.Price),
+			OpenOrderID:  lot.OrderID,
+			CloseOrderID: orderID,
+		})
+		lot.Quantity -= signed
+		remaining -= n
+	}
+
+	open := lots[:0]
+	for _, lot := range lots {
+		if lot.Quantity != 0 {
+			open = append(open, lot)
+		}
+	}
+	if quantity < 0 {
+		remaining = -remaining
+	}
+	return open, closed, remaining
+}
+
+// lotTotals returns the signed quantity and volume-weighted price of lots
+func lotTotals(lots []Lot) (int, float64) {
+	qty := 0
+	cost := 0.0
+	for _, lot := range lots {
+		qty += lot.Quantity
+		cost += float64(lot.Quantity) * lot.Price
+	}
+	if qty == 0 {
+		return 0, 0
+	}
+	return qty, cost / float64(qty)
+}
+
+// legacyLot stands in for a position saved before lots were tracked: one lot
+// at the average entry, opened at the last trade
+func legacyLot(pos Position) Lot {
+	opened, _ := time.Parse(time.RFC3339, pos.LastTradeAt)
+	return Lot{Quantity: pos.Quantity, Price: pos.AvgEntryPrice, OpenedAt: opened}
+}
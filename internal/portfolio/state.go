@@ -6,6 +6,10 @@ import (
 	"os"
 	"sync"
 	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/clock"
+	"github.com/Rajchodisetti/trading-app/internal/durable"
+	"github.com/Rajchodisetti/trading-app/internal/observ"
 )
 
 // Position represents a trading position for a single symbol
@@ -13,11 +17,16 @@ type Position struct {
 	Quantity         int     `json:"quantity"`          // Current position size (shares/contracts)
 	AvgEntryPrice    float64 `json:"avg_entry_price"`   // Average entry price
 	EntryVWAP        float64 `json:"entry_vwap"`        // Volume-weighted entry price for stop-loss
-	CurrentNotional  float64 `json:"current_notional"`  // Current position value
+	CurrentNotional  float64 `json:"current_notional"`  // Signed market value at LastPrice
 	UnrealizedPnL    float64 `json:"unrealized_pnl"`    // Unrealized profit/loss
 	LastTradeAt      string  `json:"last_trade_at"`     // Timestamp of last trade
 	TradeCountToday  int     `json:"trade_count_today"` // Number of trades today
 	RealizedPnLToday float64 `json:"realized_pnl_today"` // Realized P&L today
+	LastPrice        float64 `json:"last_price,omitempty"` // Latest fill or mark price
+	MarkedAt         string  `json:"marked_at,omitempty"`  // When LastPrice was set
+	Lots             []Lot   `json:"lots,omitempty"`       // Open tax lots, oldest first
+	Flag             string  `json:"flag,omitempty"`       // Set by a delisting or acquisition
+	FlaggedAt        string  `json:"flagged_at,omitempty"` // When Flag was set
 }
 
 // DailyStats tracks daily portfolio statistics
@@ -27,7 +36,21 @@ type DailyStats struct {
 	ExposurePctCapital  float64 `json:"exposure_pct_capital"` // Exposure as % of capital
 	NewExposureToday    float64 `json:"new_exposure_today"`   // New exposure added today
 	TradesToday         int     `json:"trades_today"`         // Total trades today
-	PnLToday           float64 `json:"pnl_today"`           // Total P&L today
+	PnLToday           float64 `json:"pnl_today"`           // Total P&L today, net of costs
+	CostsToday          float64 `json:"costs_today"`          // Commissions, fees and borrow paid today
+}
+
+// Cost kinds debited from cash
+const (
+	CostCommission = "commission"
+	CostFees       = "fees"   // regulatory: SEC and FINRA TAF
+	CostBorrow     = "borrow" // stock borrow on shorts
+)
+
+// Costs are what one execution paid on top of its price
+type Costs struct {
+	Commission float64 `json:"commission"`
+	Fees       float64 `json:"fees"`
 }
 
 // State represents the complete portfolio state
@@ -37,13 +60,25 @@ type State struct {
 	Positions   map[string]Position  `json:"positions"`    // Positions by symbol
 	DailyStats  DailyStats          `json:"daily_stats"`  // Current day statistics
 	CapitalBase float64             `json:"capital_base"` // Total capital for calculations
+	Cash        float64             `json:"cash"`         // Cash balance: every fill and cost moves it
+	CashTracked bool                `json:"cash_tracked"` // false in snapshots written before the ledger existed
+	CostsPaid   map[string]float64  `json:"costs_paid,omitempty"` // Lifetime costs by kind
+	ClosedLots  []ClosedLot         `json:"closed_lots,omitempty"` // Most recent lots closed, in close order
+	ActionsApplied map[string]string `json:"actions_applied,omitempty"` // Corporate action id -> effective time
+	BorrowAccruedOn string           `json:"borrow_accrued_on,omitempty"` // UTC date borrow was last charged through
+	Checksum    string              `json:"checksum,omitempty"` // CRC-32C of the snapshot with this field empty
 }
 
 // Manager handles portfolio state persistence and calculations
 type Manager struct {
-	filePath string
-	state    State
-	mu       sync.RWMutex
+	filePath  string
+	state     State
+	mu        sync.RWMutex
+	orders    map[string]bool // order ids already counted as a trade today
+	noSync    bool            // skip fsync on save (durable.SyncNone)
+	lotMethod string          // which lots closes consume first
+	maxClosed int             // closed lots kept in the snapshot
+	clock     clock.Clock
 }
 
 // NewManager creates a new portfolio manager with the given state file path
@@ -53,34 +88,122 @@ func NewManager(filePath string, capitalBase float64) *Manager {
 		state: State{
 			Positions:   make(map[string]Position),
 			CapitalBase: capitalBase,
+			Cash:        capitalBase,
+			CashTracked: true,
 			DailyStats: DailyStats{
 				Date: time.Now().UTC().Format("2006-01-02"),
 			},
 		},
+		clock:     clock.Real{},
+		lotMethod: LotFIFO,
+		maxClosed: DefaultMaxClosedLots,
+	}
+}
+
+// SetLotMethod sets how closes match open lots: LotFIFO (the default),
+// LotLIFO or LotHighestCost
+func (m *Manager) SetLotMethod(method string) error {
+	method, err := ValidateLotMethod(method)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lotMethod = method
+	return nil
+}
+
+// SetMaxClosedLots sets how many closed lots the snapshot keeps; older ones
+// are dropped as new ones close so the snapshot stays a bounded size
+func (m *Manager) SetMaxClosedLots(n int) error {
+	if n < 1 {
+		return fmt.Errorf("max closed lots must be positive, got %d", n)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxClosed = n
+	m.trimClosedLotsUnsafe()
+	return nil
+}
+
+// trimClosedLotsUnsafe drops the oldest closed lots past the limit. The kept
+// ones are copied so the dropped ones don't stay reachable.
+func (m *Manager) trimClosedLotsUnsafe() {
+	if over := len(m.state.ClosedLots) - m.maxClosed; over > 0 {
+		m.state.ClosedLots = append([]ClosedLot(nil), m.state.ClosedLots[over:]...)
 	}
 }
 
-// Load loads portfolio state from disk, creating default state if file doesn't exist
+// SetClock sets the time source for daily resets, trade cooldowns and
+// snapshot timestamps
+func (m *Manager) SetClock(c clock.Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = clock.Or(c)
+}
+
+// SetSyncPolicy controls whether snapshots are fsynced. Snapshots are always
+// replaced atomically; only SyncNone skips the fsync.
+func (m *Manager) SetSyncPolicy(policy durable.SyncPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.noSync = policy == durable.SyncNone
+}
+
+// Load loads portfolio state from disk, creating default state if file doesn't exist.
+// A snapshot that fails its checksum is replaced by the previous snapshot
+// (<path>.bak); if both are damaged Load fails rather than trade on bad state.
 func (m *Manager) Load() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	data, err := os.ReadFile(m.filePath)
+	// A leftover temp file is a save that never completed
+	os.Remove(m.filePath + ".tmp")
+
+	state, err := readSnapshot(m.filePath)
 	if err != nil {
-		if os.IsNotExist(err) {
+		_, bakErr := os.Stat(m.filePath + ".bak")
+		if os.IsNotExist(err) && os.IsNotExist(bakErr) {
 			// File doesn't exist, use default state
-			m.state.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+			m.state.UpdatedAt = m.clock.Now().UTC().Format(time.RFC3339)
 			return m.saveUnsafe()
 		}
-		return fmt.Errorf("failed to read portfolio state: %w", err)
+		backup, bakErr := readSnapshot(m.filePath + ".bak")
+		if bakErr != nil {
+			return fmt.Errorf("portfolio state %s is damaged (%v) and no usable backup: %w", m.filePath, err, bakErr)
+		}
+		observ.IncCounter("portfolio_state_recovered_total", nil)
+		observ.Log("portfolio_state_recovered", map[string]any{
+			"path":    m.filePath,
+			"error":   err.Error(),
+			"version": backup.Version,
+		})
+		state = backup
 	}
-
-	if err := json.Unmarshal(data, &m.state); err != nil {
-		return fmt.Errorf("failed to unmarshal portfolio state: %w", err)
+	m.state = state
+	if m.state.Positions == nil {
+		m.state.Positions = make(map[string]Position)
+	}
+	m.trimClosedLotsUnsafe()
+	if !m.state.CashTracked {
+		// Older snapshots have no ledger: rebuild cash from capital, today's
+		// realized P&L and what the open positions cost
+		m.state.Cash = m.state.CapitalBase + m.state.DailyStats.PnLToday
+		for _, pos := range m.state.Positions {
+			m.state.Cash -= float64(pos.Quantity) * pos.AvgEntryPrice
+		}
+		m.state.CashTracked = true
+	}
+	for symbol, pos := range m.state.Positions {
+		if pos.Quantity != 0 && len(pos.Lots) == 0 {
+			// Older snapshots have no lots: carry the position as one lot
+			pos.Lots = []Lot{legacyLot(pos)}
+			m.state.Positions[symbol] = pos
+		}
 	}
 
 	// Reset daily stats if it's a new day
-	today := time.Now().UTC().Format("2006-01-02")
+	today := m.clock.Now().UTC().Format("2006-01-02")
 	if m.state.DailyStats.Date != today {
 		m.resetDailyStats(today)
 	}
@@ -88,6 +211,33 @@ func (m *Manager) Load() error {
 	return nil
 }
 
+// readSnapshot reads and verifies one portfolio snapshot. Snapshots written
+// before checksums existed carry none and are accepted.
+func readSnapshot(path string) (State, error) {
+	var state State
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to unmarshal portfolio state: %w", err)
+	}
+	if state.Checksum == "" {
+		return state, nil
+	}
+	want := state.Checksum
+	state.Checksum = ""
+	body, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return state, err
+	}
+	if got := durable.Checksum(body); got != want {
+		return state, fmt.Errorf("portfolio state checksum mismatch: got %s, want %s", got, want)
+	}
+	state.Checksum = want
+	return state, nil
+}
+
 // Save atomically saves the portfolio state to disk
 func (m *Manager) Save() error {
 	m.mu.Lock()
@@ -95,25 +245,35 @@ func (m *Manager) Save() error {
 	return m.saveUnsafe()
 }
 
-// saveUnsafe saves without acquiring lock (internal use only)
+// saveUnsafe saves without acquiring lock (internal use only). The previous
+// snapshot is kept as <path>.bak for Load to fall back on.
 func (m *Manager) saveUnsafe() error {
 	m.state.Version++
-	m.state.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	m.state.UpdatedAt = m.clock.Now().UTC().Format(time.RFC3339)
 
+	m.state.Checksum = ""
+	body, err := json.MarshalIndent(m.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal portfolio state: %w", err)
+	}
+	m.state.Checksum = durable.Checksum(body)
 	data, err := json.MarshalIndent(m.state, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal portfolio state: %w", err)
 	}
 
-	// Atomic write using temp file + rename
-	tempPath := m.filePath + ".tmp"
-	if err := os.WriteFile(tempPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write temp portfolio state: %w", err)
+	// Hard-link the current snapshot to .bak so the primary path never goes missing
+	backupTmp := m.filePath + ".bak.tmp"
+	os.Remove(backupTmp)
+	if err := os.Link(m.filePath, backupTmp); err == nil {
+		if err := os.Rename(backupTmp, m.filePath+".bak"); err != nil {
+			return fmt.Errorf("failed to back up portfolio state: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to back up portfolio state: %w", err)
 	}
-
-	if err := os.Rename(tempPath, m.filePath); err != nil {
-		os.Remove(tempPath) // Clean up temp file
-		return fmt.Errorf("failed to rename portfolio state: %w", err)
+	if err := durable.WriteFileAtomic(m.filePath, data, 0644, !m.noSync); err != nil {
+		return fmt.Errorf("failed to write portfolio state: %w", err)
 	}
 
 	return nil
@@ -151,70 +311,142 @@ func (m *Manager) UpdatePosition(symbol string, quantity int, price float64, tim
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Reset daily stats if it's a new day
+	m.rollDayUnsafe(timestamp)
+	return m.updatePositionUnsafe("", symbol, quantity, price, Costs{}, timestamp, true)
+}
+
+// ApplyFill applies one (possibly partial) execution of an order. Quantity is
+// signed: negative for sells. Cash moves by the fill's value and its costs.
+// Only the first fill of each order counts as a trade, so an order filled in
+// slices uses one trade of the daily caps.
+func (m *Manager) ApplyFill(orderID, symbol string, quantity int, price float64, costs Costs, timestamp time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rollDayUnsafe(timestamp)
+	if m.orders == nil {
+		m.orders = make(map[string]bool)
+	}
+	newTrade := orderID == "" || !m.orders[orderID]
+	if orderID != "" {
+		m.orders[orderID] = true
+	}
+	return m.updatePositionUnsafe(orderID, symbol, quantity, price, costs, timestamp, newTrade)
+}
+
+// AccrueBorrow charges stock borrow on every open short for each UTC day
+// since the last accrual and returns the total charged. fee prices days of
+// borrow on the position's current market value. The first call starts the
+// accrual without charging.
+func (m *Manager) AccrueBorrow(at time.Time, fee func(symbol string, marketValue float64, days int) float64) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	today := at.UTC().Format("2006-01-02")
+	last := m.state.BorrowAccruedOn
+	if last >= today {
+		return 0, nil
+	}
+	m.state.BorrowAccruedOn = today
+	from, err := time.Parse("2006-01-02", last)
+	if err != nil {
+		return 0, m.saveUnsafe()
+	}
+	to, _ := time.Parse("2006-01-02", today)
+	days := int(to.Sub(from).Hours() / 24)
+
+	m.rollDayUnsafe(at)
+	charged := 0.0
+	for symbol, pos := range m.state.Positions {
+		if pos.Quantity >= 0 {
+			continue
+		}
+		amount := fee(symbol, pos.CurrentNotional, days)
+		m.chargeUnsafe(CostBorrow, amount)
+		charged += amount
+	}
+	return charged, m.saveUnsafe()
+}
+
+// chargeUnsafe debits cash and books the cost against today's P&L
+func (m *Manager) chargeUnsafe(kind string, amount float64) {
+	if amount == 0 {
+		return
+	}
+	if m.state.CostsPaid == nil {
+		m.state.CostsPaid = make(map[string]float64)
+	}
+	m.state.Cash -= amount
+	m.state.CostsPaid[kind] += amount
+	m.state.DailyStats.CostsToday += amount
+	m.state.DailyStats.PnLToday -= amount
+	observ.IncCounter("portfolio_costs_total", map[string]string{"kind": kind})
+}
+
+// rollDayUnsafe resets daily stats if timestamp falls on a new day
+func (m *Manager) rollDayUnsafe(timestamp time.Time) {
 	today := timestamp.Format("2006-01-02")
 	if m.state.DailyStats.Date != today {
 		m.resetDailyStats(today)
 	}
+}
+
+func (m *Manager) updatePositionUnsafe(orderID, symbol string, quantity int, price float64, costs Costs, timestamp time.Time, newTrade bool) error {
 
 	pos := m.state.Positions[symbol]
+
+	// Buys spend cash, sells raise it; costs are paid either way
+	m.state.Cash -= float64(quantity) * price
+	m.chargeUnsafe(CostCommission, costs.Commission)
+	m.chargeUnsafe(CostFees, costs.Fees)
 	
-	// Update position
-	if pos.Quantity == 0 {
-		// New position
-		pos.Quantity = quantity
-		pos.AvgEntryPrice = price
-		pos.EntryVWAP = price // Initialize VWAP with first trade price
-		pos.CurrentNotional = float64(quantity) * price
+	if pos.Quantity != 0 && len(pos.Lots) == 0 {
+		pos.Lots = []Lot{legacyLot(pos)}
+	}
+
+	// Update position: opens and adds start a lot, closes consume lots
+	// in the configured order and realize P&L lot by lot
+	if pos.Quantity == 0 || (pos.Quantity > 0) == (quantity > 0) {
+		pos.Lots = append(pos.Lots, Lot{Quantity: quantity, Price: price, OpenedAt: timestamp.UTC(), OrderID: orderID})
 	} else {
-		// Existing position
-		if (pos.Quantity > 0 && quantity > 0) || (pos.Quantity < 0 && quantity < 0) {
-			// Adding to position
-			totalCost := pos.AvgEntryPrice * float64(pos.Quantity) + price * float64(quantity)
-			totalQuantity := pos.Quantity + quantity
-			
-			// Update VWAP for stop-loss calculations
-			pos.EntryVWAP = totalCost / float64(totalQuantity)
-			
-			pos.Quantity = totalQuantity
-			pos.AvgEntryPrice = totalCost / float64(pos.Quantity)
-			pos.CurrentNotional = float64(pos.Quantity) * pos.AvgEntryPrice
-		} else {
-			// Reducing or closing position
-			if absInt(quantity) >= absInt(pos.Quantity) {
-				// Closing or reversing position - realize P&L
-				realizedPnL := float64(pos.Quantity) * (price - pos.AvgEntryPrice)
-				pos.RealizedPnLToday += realizedPnL
-				m.state.DailyStats.PnLToday += realizedPnL
-				
-				// Set new position if reversing
-				pos.Quantity += quantity
-				if pos.Quantity != 0 {
-					pos.AvgEntryPrice = price
-					pos.CurrentNotional = float64(pos.Quantity) * price
-				} else {
-					pos.CurrentNotional = 0
-				}
-			} else {
-				// Partial close - realize partial P&L
-				realizedPnL := float64(quantity) * (price - pos.AvgEntryPrice)
-				pos.RealizedPnLToday += realizedPnL
-				m.state.DailyStats.PnLToday += realizedPnL
-				pos.Quantity += quantity
-				pos.CurrentNotional = float64(pos.Quantity) * pos.AvgEntryPrice
-			}
+		lots, closed, reversed := CloseLots(symbol, pos.Lots, quantity, price, m.lotMethod, timestamp.UTC(), orderID)
+		for _, c := range closed {
+			pos.RealizedPnLToday += c.RealizedPnL
+			m.state.DailyStats.PnLToday += c.RealizedPnL
+		}
+		m.state.ClosedLots = append(m.state.ClosedLots, closed...)
+		m.trimClosedLotsUnsafe()
+		if reversed != 0 {
+			lots = append(lots, Lot{Quantity: reversed, Price: price, OpenedAt: timestamp.UTC(), OrderID: orderID})
 		}
+		pos.Lots = lots
 	}
 
+	if qty, avg := lotTotals(pos.Lots); qty != 0 {
+		pos.Quantity = qty
+		pos.AvgEntryPrice = avg
+		pos.EntryVWAP = avg // Volume-weighted over open lots for stop-loss calculations
+	} else {
+		pos.Quantity = 0
+		pos.Lots = nil
+	}
+
+	// A fill is a print: mark the position at it
+	markUnsafe(&pos, price, timestamp)
+
 	// Update trade tracking
 	pos.LastTradeAt = timestamp.Format(time.RFC3339)
-	pos.TradeCountToday++
+	if newTrade {
+		pos.TradeCountToday++
+	}
 	
 	// Store updated position
 	m.state.Positions[symbol] = pos
 	
 	// Update daily statistics
-	m.state.DailyStats.TradesToday++
+	if newTrade {
+		m.state.DailyStats.TradesToday++
+	}
 	
 	// Recalculate portfolio exposure
 	m.recalculateExposureUnsafe()
@@ -224,21 +456,42 @@ func (m *Manager) UpdatePosition(symbol string, quantity int, price float64, tim
 
 // UpdateUnrealizedPnL updates unrealized P&L for a position based on current market price
 func (m *Manager) UpdateUnrealizedPnL(symbol string, currentPrice float64) error {
+	return m.MarkPositions(map[string]float64{symbol: currentPrice}, m.clock.Now())
+}
+
+// MarkPositions marks open positions at market prices by symbol: unrealized
+// P&L, market value and exposure all move to the new prices. Symbols without
+// an open position are ignored. The state is saved once.
+func (m *Manager) MarkPositions(prices map[string]float64, at time.Time) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	pos, exists := m.state.Positions[symbol]
-	if !exists || pos.Quantity == 0 {
+	marked := false
+	for symbol, price := range prices {
+		pos, exists := m.state.Positions[symbol]
+		if !exists || pos.Quantity == 0 || price <= 0 {
+			continue
+		}
+		markUnsafe(&pos, price, at)
+		m.state.Positions[symbol] = pos
+		marked = true
+	}
+	if !marked {
 		return nil
 	}
 
-	pos.UnrealizedPnL = float64(pos.Quantity) * (currentPrice - pos.AvgEntryPrice)
-	pos.CurrentNotional = float64(pos.Quantity) * currentPrice
-	m.state.Positions[symbol] = pos
-
+	m.recalculateExposureUnsafe()
 	return m.saveUnsafe()
 }
 
+// markUnsafe prices a position at a fill or quote
+func markUnsafe(pos *Position, price float64, at time.Time) {
+	pos.LastPrice = price
+	pos.MarkedAt = at.UTC().Format(time.RFC3339)
+	pos.CurrentNotional = float64(pos.Quantity) * price
+	pos.UnrealizedPnL = float64(pos.Quantity) * (price - pos.AvgEntryPrice)
+}
+
 // CanTrade checks if a symbol can be traded based on cooldown period
 func (m *Manager) CanTrade(symbol string, cooldownMinutes int) bool {
 	m.mu.RLock()
@@ -254,16 +507,28 @@ func (m *Manager) CanTrade(symbol string, cooldownMinutes int) bool {
 		return true // If can't parse, allow trade
 	}
 
-	return time.Since(lastTrade) >= time.Duration(cooldownMinutes)*time.Minute
+	return m.clock.Now().Sub(lastTrade) >= time.Duration(cooldownMinutes)*time.Minute
 }
 
-// GetExposureUSD returns the total portfolio exposure in USD
+// GetExposureUSD returns the gross portfolio exposure in USD: longs plus shorts
 func (m *Manager) GetExposureUSD() float64 {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return m.state.DailyStats.TotalExposureUSD
 }
 
+// GetNetExposureUSD returns long minus short notional
+func (m *Manager) GetNetExposureUSD() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	net := 0.0
+	for _, pos := range m.state.Positions {
+		net += pos.CurrentNotional
+	}
+	return net
+}
+
 // GetExposurePercent returns the portfolio exposure as percentage of capital
 func (m *Manager) GetExposurePercent() float64 {
 	m.mu.RLock()
@@ -292,6 +557,8 @@ func (m *Manager) resetDailyStats(date string) {
 		m.state.Positions[symbol] = pos
 	}
 	
+	m.orders = nil
+	
 	// Reset daily stats
 	m.state.DailyStats = DailyStats{
 		Date:                date,
@@ -300,6 +567,7 @@ func (m *Manager) resetDailyStats(date string) {
 		NewExposureToday:    0,
 		TradesToday:         0,
 		PnLToday:           0,
+		CostsToday:          0,
 	}
 }
 
@@ -332,23 +600,51 @@ func absInt(x int) int {
 	return x
 }
 
-// GetNAV calculates current Net Asset Value (capital + realized + unrealized P&L)
+// GetNAV returns Net Asset Value: cash plus the market value of all positions
 func (m *Manager) GetNAV() float64 {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	
-	// Start with capital base
-	nav := m.state.CapitalBase
-	
-	// Add realized P&L from today
-	nav += m.state.DailyStats.PnLToday
-	
-	// Add unrealized P&L from all positions
+	return m.state.Cash + m.marketValueUnsafe()
+}
+
+// GetCash returns the cash balance
+func (m *Manager) GetCash() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.Cash
+}
+
+// GetMarketValue returns the signed value of all positions at their latest price
+func (m *Manager) GetMarketValue() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.marketValueUnsafe()
+}
+
+// GetCostsPaid returns lifetime costs by kind
+func (m *Manager) GetCostsPaid() map[string]float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	costs := make(map[string]float64, len(m.state.CostsPaid))
+	for kind, amount := range m.state.CostsPaid {
+		costs[kind] = amount
+	}
+	return costs
+}
+
+// marketValueUnsafe prices each position at its latest fill or mark, falling
+// back to its entry price
+func (m *Manager) marketValueUnsafe() float64 {
+	value := 0.0
 	for _, pos := range m.state.Positions {
-		nav += pos.UnrealizedPnL
+		price := pos.LastPrice
+		if price == 0 {
+			price = pos.AvgEntryPrice
+		}
+		value += float64(pos.Quantity) * price
 	}
-	
-	return nav
+	return value
 }
 
 // GetPositionNotionals returns map of symbol to current notional value for sector exposure calculation
@@ -363,6 +659,43 @@ func (m *Manager) GetPositionNotionals() map[string]float64 {
 	return notionals
 }
 
+// GetLots returns the open lots of a symbol, oldest first
+func (m *Manager) GetLots(symbol string) []Lot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Lot(nil), m.state.Positions[symbol].Lots...)
+}
+
+// GetClosedLots returns the kept lots closed at or after since, in close order
+func (m *Manager) GetClosedLots(since time.Time) []ClosedLot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var out []ClosedLot
+	for _, c := range m.state.ClosedLots {
+		if !c.ClosedAt.Before(since) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// GetStopEntry returns the entry price the stop-loss measures from:
+// StopEntryLatestLot uses the most recently opened lot, anything else the VWAP
+func (m *Manager) GetStopEntry(symbol, reference string) (float64, bool) {
+	if reference != StopEntryLatestLot {
+		return m.GetEntryVWAP(symbol)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pos, exists := m.state.Positions[symbol]
+	if !exists || pos.Quantity == 0 || len(pos.Lots) == 0 {
+		return 0, false
+	}
+	return pos.Lots[len(pos.Lots)-1].Price, true
+}
+
 // GetEntryVWAP returns the entry VWAP for a symbol (for stop-loss calculations)
 func (m *Manager) GetEntryVWAP(symbol string) (float64, bool) {
 	m.mu.RLock()
@@ -0,0 +1,321 @@
+package portfolio
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestManager_CashLedger(t *testing.T) {
+	m := NewManager(filepath.Join(t.TempDir(), "portfolio.json"), 10000)
+	now := time.Now()
+
+	if err := m.ApplyFill("o1", "AAPL", 10, 100, Costs{Commission: 1}, now); err != nil {
+		t.Fatal(err)
+	}
+	if !approx(m.GetCash(), 8999) || !approx(m.GetNAV(), 9999) {
+		t.Fatalf("want cash 8999 and NAV 9999 after the buy, got %v and %v", m.GetCash(), m.GetNAV())
+	}
+
+	// Marks move market value, not cash
+	if err := m.UpdateUnrealizedPnL("AAPL", 110); err != nil {
+		t.Fatal(err)
+	}
+	if !approx(m.GetMarketValue(), 1100) || !approx(m.GetNAV(), 10099) {
+		t.Fatalf("want market value 1100 and NAV 10099, got %v and %v", m.GetMarketValue(), m.GetNAV())
+	}
+
+	if err := m.ApplyFill("o2", "AAPL", -10, 110, Costs{Commission: 1, Fees: 0.05}, now); err != nil {
+		t.Fatal(err)
+	}
+	if !approx(m.GetCash(), 10097.95) || !approx(m.GetNAV(), m.GetCash()) {
+		t.Fatalf("want cash 10097.95 and a flat book, got cash %v NAV %v", m.GetCash(), m.GetNAV())
+	}
+	stats := m.GetDailyStats()
+	if !approx(stats.CostsToday, 2.05) || !approx(stats.PnLToday, 97.95) {
+		t.Fatalf("want 2.05 of costs against 100 realized, got %+v", stats)
+	}
+	if paid := m.GetCostsPaid(); !approx(paid[CostCommission], 2) || !approx(paid[CostFees], 0.05) {
+		t.Fatalf("unexpected costs by kind: %v", paid)
+	}
+}
+
+func TestManager_AccrueBorrow(t *testing.T) {
+	m := NewManager(filepath.Join(t.TempDir(), "portfolio.json"), 10000)
+	day1 := time.Date(2025, 8, 25, 15, 0, 0, 0, time.UTC)
+	m.ApplyFill("o1", "TSLA", -100, 50, Costs{}, day1)
+	m.ApplyFill("o2", "AAPL", 10, 200, Costs{}, day1)
+	fee := func(symbol string, marketValue float64, days int) float64 {
+		return math.Abs(marketValue) * 0.0001 * float64(days)
+	}
+
+	// The first accrual only starts the clock
+	if charged, err := m.AccrueBorrow(day1, fee); err != nil || charged != 0 {
+		t.Fatalf("want nothing charged on the first accrual, got %v %v", charged, err)
+	}
+	cash := m.GetCash()
+
+	// One night short: only the short pays, once per day
+	if charged, _ := m.AccrueBorrow(day1.Add(20*time.Hour), fee); !approx(charged, 0.5) {
+		t.Fatalf("want 0.50 of borrow for one day, got %v", charged)
+	}
+	if charged, _ := m.AccrueBorrow(day1.Add(22*time.Hour), fee); charged != 0 {
+		t.Fatalf("borrow charged twice in a day: %v", charged)
+	}
+
+	// A gap charges every day missed
+	if charged, _ := m.AccrueBorrow(day1.Add(3*24*time.Hour), fee); !approx(charged, 1.0) {
+		t.Fatalf("want 1.00 of borrow for two days, got %v", charged)
+	}
+	if !approx(cash-m.GetCash(), 1.5) || !approx(m.GetCostsPaid()[CostBorrow], 1.5) {
+		t.Fatalf("want 1.50 of borrow debited, got cash %v costs %v", cash-m.GetCash(), m.GetCostsPaid())
+	}
+}
+
+func TestManager_MarkPositions(t *testing.T) {
+	m := NewManager(filepath.Join(t.TempDir(), "portfolio.json"), 10000)
+	now := time.Now()
+
+	m.ApplyFill("o1", "AAPL", 10, 100, Costs{}, now)
+	m.ApplyFill("o2", "AAPL", 10, 120, Costs{}, now)
+	// Exposure is at the latest print, not the 110 average cost
+	if !approx(m.GetExposureUSD(), 2400) {
+		t.Fatalf("want exposure 2400 at market, got %v", m.GetExposureUSD())
+	}
+
+	if err := m.MarkPositions(map[string]float64{"AAPL": 90, "NVDA": 50}, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	pos, _ := m.GetPosition("AAPL")
+	if !approx(pos.UnrealizedPnL, -400) || !approx(pos.CurrentNotional, 1800) || pos.MarkedAt == "" {
+		t.Fatalf("unexpected mark %+v", pos)
+	}
+	if !approx(m.GetExposurePercent(), 18) {
+		t.Fatalf("want exposure 18%% of capital, got %v", m.GetExposurePercent())
+	}
+	if _, ok := m.GetPosition("NVDA"); ok {
+		t.Fatal("marking a symbol without a position opened one")
+	}
+}
+
+func TestManager_LoadMigratesLegacyCash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "portfolio.json")
+	legacy := map[string]any{
+		"capital_base": 10000,
+		"daily_stats":  map[string]any{"date": time.Now().UTC().Format("2006-01-02"), "pnl_today": 50},
+		"positions":    map[string]any{"AAPL": map[string]any{"quantity": 10, "avg_entry_price": 100}},
+	}
+	data, _ := json.Marshal(legacy)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager(path, 10000)
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if !approx(m.GetCash(), 9050) || !approx(m.GetNAV(), 10050) {
+		t.Fatalf("want cash 9050 and NAV 10050, got %v and %v", m.GetCash(), m.GetNAV())
+	}
+	if lots := m.GetLots("AAPL"); len(lots) != 1 || lots[0].Quantity != 10 || lots[0].Price != 100 {
+		t.Fatalf("want the legacy position carried as one lot, got %+v", lots)
+	}
+}
+
+func TestManager_LotMatching(t *testing.T) {
+	t0 := time.Date(2025, 8, 25, 14, 0, 0, 0, time.UTC)
+	cases := []struct {
+		method    string
+		wantPnL   float64 // selling 15 at 120 out of 10@100 then 10@110 then 10@90
+		wantEntry float64 // of the first lot closed
+	}{
+		{LotFIFO, 10*20 + 5*10, 100},
+		{LotLIFO, 10*30 + 5*10, 90},
+		{LotHighestCost, 10*10 + 5*20, 110},
+	}
+	for _, tc := range cases {
+		t.Run(tc.method, func(t *testing.T) {
+			m := NewManager(filepath.Join(t.TempDir(), "portfolio.json"), 100000)
+			if err := m.SetLotMethod(tc.method); err != nil {
+				t.Fatal(err)
+			}
+			for i, price := range []float64{100, 110, 90} {
+				if err := m.ApplyFill("buy"+string(rune('a'+i)), "AAPL", 10, price, Costs{}, t0.Add(time.Duration(i)*time.Hour)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			closedAt := t0.Add(48 * time.Hour)
+			if err := m.ApplyFill("sell", "AAPL", -15, 120, Costs{}, closedAt); err != nil {
+				t.Fatal(err)
+			}
+
+			closed := m.GetClosedLots(time.Time{})
+			if len(closed) != 2 || closed[0].EntryPrice != tc.wantEntry || closed[0].CloseOrderID != "sell" {
+				t.Fatalf("unexpected closed lots: %+v", closed)
+			}
+			if closed[0].HoldingPeriod() < 46*time.Hour || closed[0].LongTerm() {
+				t.Fatalf("unexpected holding period %v", closed[0].HoldingPeriod())
+			}
+			if got := m.GetDailyStats().PnLToday; !approx(got, tc.wantPnL) {
+				t.Fatalf("want realized %v, got %v", tc.wantPnL, got)
+			}
+
+			// What is left is priced from the remaining lots
+			pos, _ := m.GetPosition("AAPL")
+			var cost float64
+			for _, l := range m.GetLots("AAPL") {
+				cost += float64(l.Quantity) * l.Price
+			}
+			if pos.Quantity != 15 || !approx(pos.AvgEntryPrice, cost/15) {
+				t.Fatalf("unexpected position %+v", pos)
+			}
+		})
+	}
+
+	if err := NewManager("", 0).SetLotMethod("average"); err == nil {
+		t.Fatal("want an error for an unknown lot method")
+	}
+}
+
+func TestManager_KeepsNewestClosedLots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "portfolio.json")
+	m := NewManager(path, 100000)
+	if err := m.SetMaxClosedLots(2); err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Date(2025, 8, 25, 14, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		at := t0.Add(time.Duration(i) * time.Hour)
+		id := string(rune('a' + i))
+		if err := m.ApplyFill("buy"+id, "AAPL", 10, 100, Costs{}, at); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.ApplyFill("sell"+id, "AAPL", -10, 101, Costs{}, at.Add(time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	closed := m.GetClosedLots(time.Time{})
+	if len(closed) != 2 || closed[0].CloseOrderID != "sellb" || closed[1].CloseOrderID != "sellc" {
+		t.Fatalf("want the two newest closed lots, got %+v", closed)
+	}
+
+	reloaded := NewManager(path, 100000)
+	if err := reloaded.SetMaxClosedLots(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if closed := reloaded.GetClosedLots(time.Time{}); len(closed) != 1 || closed[0].CloseOrderID != "sellc" {
+		t.Fatalf("want a larger snapshot trimmed on load, got %+v", closed)
+	}
+	if err := reloaded.SetMaxClosedLots(0); err == nil {
+		t.Fatal("want a non-positive limit rejected")
+	}
+}
+
+func TestManager_LotReversalAndStopEntry(t *testing.T) {
+	m := NewManager(filepath.Join(t.TempDir(), "portfolio.json"), 100000)
+	now := time.Now()
+	m.ApplyFill("b1", "AAPL", 10, 100, Costs{}, now)
+	m.ApplyFill("b2", "AAPL", 10, 90, Costs{}, now.Add(time.Minute))
+
+	if entry, _ := m.GetStopEntry("AAPL", StopEntryVWAP); !approx(entry, 95) {
+		t.Fatalf("want the vwap entry 95, got %v", entry)
+	}
+	if entry, _ := m.GetStopEntry("AAPL", StopEntryLatestLot); entry != 90 {
+		t.Fatalf("want the latest lot's entry 90, got %v", entry)
+	}
+
+	// Selling through the position opens a short lot with the remainder
+	m.ApplyFill("s1", "AAPL", -25, 80, Costs{}, now.Add(2*time.Minute))
+	lots := m.GetLots("AAPL")
+	if len(lots) != 1 || lots[0].Quantity != -5 || lots[0].Price != 80 || lots[0].OrderID != "s1" {
+		t.Fatalf("want one short lot of 5 at 80, got %+v", lots)
+	}
+	if got := m.GetDailyStats().PnLToday; !approx(got, -300) {
+		t.Fatalf("want -300 realized, got %v", got)
+	}
+	if _, ok := m.GetStopEntry("MSFT", StopEntryLatestLot); ok {
+		t.Fatal("want no entry without a position")
+	}
+}
+
+func TestManager_CorporateActions(t *testing.T) {
+	m := NewManager(filepath.Join(t.TempDir(), "portfolio.json"), 100000)
+	now := time.Date(2025, 8, 25, 14, 0, 0, 0, time.UTC)
+	m.ApplyFill("o1", "NVDA", 10, 400, Costs{}, now)
+	m.ApplyFill("o2", "NVDA", 5, 460, Costs{}, now)
+	m.ApplyFill("o3", "ABC", 25, 2, Costs{}, now)
+	m.ApplyFill("o4", "FB", 3, 300, Costs{}, now)
+	m.ApplyFill("o5", "BIOX", 100, 5, Costs{}, now)
+	navBefore := m.GetNAV()
+
+	// 4:1 split: four times the shares at a quarter of the price, same value
+	split := CorporateAction{ID: "nvda-split", Type: ActionSplit, Symbol: "NVDA", From: 1, To: 4, EffectiveAt: now.Add(time.Hour)}
+	if ok, err := m.ApplyCorporateAction(split); !ok || err != nil {
+		t.Fatalf("split not applied: %v %v", ok, err)
+	}
+	pos, _ := m.GetPosition("NVDA")
+	if pos.Quantity != 60 || !approx(pos.EntryVWAP, 105) || !approx(pos.LastPrice, 115) {
+		t.Fatalf("unexpected split position %+v", pos)
+	}
+	if entry, _ := m.GetStopEntry("NVDA", StopEntryLatestLot); !approx(entry, 115) {
+		t.Fatalf("want the latest lot at 115 after the split, got %v", entry)
+	}
+	if ok, _ := m.ApplyCorporateAction(split); ok {
+		t.Fatal("split applied twice")
+	}
+
+	// 1:10 reverse split: 25 shares become 2, the half share is paid in cash
+	cash := m.GetCash()
+	m.ApplyCorporateAction(CorporateAction{ID: "abc-rs", Type: ActionReverseSplit, Symbol: "ABC", From: 10, To: 1, EffectiveAt: now})
+	pos, _ = m.GetPosition("ABC")
+	if pos.Quantity != 2 || !approx(pos.AvgEntryPrice, 20) || !approx(m.GetCash()-cash, 10) {
+		t.Fatalf("unexpected reverse split %+v, cash in lieu %v", pos, m.GetCash()-cash)
+	}
+	if !approx(m.GetNAV(), navBefore) {
+		t.Fatalf("splits changed NAV from %v to %v", navBefore, m.GetNAV())
+	}
+
+	m.ApplyCorporateAction(CorporateAction{ID: "fb-meta", Type: ActionRename, Symbol: "FB", NewSymbol: "META", EffectiveAt: now})
+	if _, ok := m.GetPosition("FB"); ok {
+		t.Fatal("renamed position left behind")
+	}
+	if pos, _ := m.GetPosition("META"); pos.Quantity != 3 || !approx(pos.AvgEntryPrice, 300) {
+		t.Fatalf("unexpected renamed position %+v", pos)
+	}
+
+	m.ApplyCorporateAction(CorporateAction{ID: "biox-delist", Type: ActionDelisting, Symbol: "BIOX", EffectiveAt: now})
+	if flagged := m.GetFlaggedPositions(); len(flagged) != 1 || flagged["BIOX"].Flag != FlagDelisted {
+		t.Fatalf("want BIOX flagged delisted, got %+v", flagged)
+	}
+}
+
+func TestManager_ReverseSplitAcrossLots(t *testing.T) {
+	m := NewManager(filepath.Join(t.TempDir(), "portfolio.json"), 100000)
+	now := time.Date(2025, 8, 25, 14, 0, 0, 0, time.UTC)
+	m.ApplyFill("o1", "XYZ", 1, 10, Costs{}, now)
+	m.ApplyFill("o2", "XYZ", 1, 20, Costs{}, now)
+	m.ApplyFill("o3", "XYZ", 1, 30, Costs{}, now)
+	navBefore, cash := m.GetNAV(), m.GetCash()
+
+	// 1:2 reverse split: three shares become one and a half, not three halves
+	// truncated to nothing
+	m.ApplyCorporateAction(CorporateAction{ID: "xyz-rs", Type: ActionReverseSplit, Symbol: "XYZ", From: 2, To: 1, EffectiveAt: now})
+	pos, _ := m.GetPosition("XYZ")
+	if pos.Quantity != 1 || len(pos.Lots) != 1 || !approx(pos.Lots[0].Price, 40) {
+		t.Fatalf("want one share left in one lot at 40, got %+v", pos)
+	}
+	if !approx(m.GetCash()-cash, 30) {
+		t.Fatalf("want the half share paid at the adjusted mark of 60, got %v", m.GetCash()-cash)
+	}
+	if !approx(m.GetNAV(), navBefore) {
+		t.Fatalf("reverse split changed NAV from %v to %v", navBefore, m.GetNAV())
+	}
+}
@@ -0,0 +1,163 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/ingest"
+	"github.com/Rajchodisetti/trading-app/internal/transport"
+)
+
+// ReadEvents decodes a recorded event log: one wire envelope per line, in
+// the order the events were received. Blank lines are skipped.
+func ReadEvents(r io.Reader) ([]transport.EventEnvelope, error) {
+	var envs []transport.EventEnvelope
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var env transport.EventEnvelope
+		if err := json.Unmarshal([]byte(text), &env); err != nil {
+			return nil, fmt.Errorf("event log line %d: %w", line, err)
+		}
+		envs = append(envs, env)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return envs, nil
+}
+
+// LoadEvents reads a recorded event log from disk
+func LoadEvents(path string) ([]transport.EventEnvelope, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadEvents(f)
+}
+
+// FixtureEvents turns the static fixture files into an event log in the
+// order fixture mode applies them: halts, ticks, news, then earnings.
+// A missing earnings path is treated as an empty calendar.
+func FixtureEvents(dir, earningsPath string) ([]transport.EventEnvelope, error) {
+	var hf struct {
+		Halts []ingest.Halt `json:"halts"`
+	}
+	var tf struct {
+		Ticks []ingest.Tick `json:"ticks"`
+	}
+	var nf struct {
+		News []ingest.NewsItem `json:"news"`
+	}
+	var ef struct {
+		Earnings []ingest.Earnings `json:"earnings"`
+	}
+	for name, v := range map[string]any{"halts.json": &hf, "ticks.json": &tf, "news.json": &nf} {
+		if err := readJSON(filepath.Join(dir, name), v); err != nil {
+			return nil, err
+		}
+	}
+	if earningsPath != "" {
+		if err := readJSON(earningsPath, &ef); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	var envs []transport.EventEnvelope
+	add := func(typ string, payload any, ts string) error {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		env := transport.EventEnvelope{
+			V:       1,
+			Type:    typ,
+			ID:      fmt.Sprintf("fixture-%d", len(envs)+1),
+			Payload: raw,
+		}
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			env.TS = t.UTC()
+		}
+		envs = append(envs, env)
+		return nil
+	}
+	for _, h := range hf.Halts {
+		if err := add("halt", h, ""); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range tf.Ticks {
+		if err := add("tick", t, t.TsUTC); err != nil {
+			return nil, err
+		}
+	}
+	for _, n := range nf.News {
+		if err := add("news", n, n.PublishedAtUTC); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range ef.Earnings {
+		if err := add("earnings", e, ""); err != nil {
+			return nil, err
+		}
+	}
+	return envs, nil
+}
+
+// eventTime is when an event happened on the market clock: the envelope
+// timestamp, else the payload's own timestamp, else zero
+func eventTime(env transport.EventEnvelope, ev ingest.Event) time.Time {
+	if !env.TS.IsZero() {
+		return env.TS.UTC()
+	}
+	var ts string
+	switch {
+	case ev.Tick != nil:
+		ts = ev.Tick.TsUTC
+	case ev.News != nil:
+		ts = ev.News.PublishedAtUTC
+	}
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
+
+// firstEventTime is the time of the first event that carries one
+func firstEventTime(envs []transport.EventEnvelope) time.Time {
+	for _, env := range envs {
+		ev, err := ingest.ParseEnvelope(env)
+		if err != nil {
+			continue
+		}
+		if t := eventTime(env, ev); !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func readJSON(path string, v any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("json %s: %w", path, err)
+	}
+	return nil
+}
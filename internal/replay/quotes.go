@@ -0,0 +1,59 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Rajchodisetti/trading-app/internal/adapters"
+	"github.com/Rajchodisetti/trading-app/internal/clock"
+	"github.com/Rajchodisetti/trading-app/internal/ingest"
+)
+
+// worldQuotes serves quotes from the replayed world state so the paper
+// broker fills against the recorded book instead of a live feed
+type worldQuotes struct {
+	world *ingest.World
+	clock clock.Clock
+}
+
+func (q *worldQuotes) GetQuote(ctx context.Context, symbol string) (*adapters.Quote, error) {
+	sym := strings.ToUpper(symbol)
+	if !q.world.HasFeatures(sym) {
+		return nil, fmt.Errorf("no replayed tick for %s", sym)
+	}
+	feat := q.world.Features(sym)
+	session := "RTH"
+	switch {
+	case feat.Premarket:
+		session = "PRE"
+	case feat.Postmarket:
+		session = "POST"
+	}
+	return &adapters.Quote{
+		Symbol:    sym,
+		Bid:       feat.Bid,
+		Ask:       feat.Ask,
+		Last:      feat.Last,
+		Timestamp: q.clock.Now().UTC(),
+		Session:   session,
+		Halted:    feat.Halted,
+		Source:    "replay",
+	}, nil
+}
+
+func (q *worldQuotes) GetQuotes(ctx context.Context, symbols []string) (map[string]*adapters.Quote, error) {
+	out := make(map[string]*adapters.Quote, len(symbols))
+	for _, sym := range symbols {
+		quote, err := q.GetQuote(ctx, sym)
+		if err != nil {
+			return nil, err
+		}
+		out[quote.Symbol] = quote
+	}
+	return out, nil
+}
+
+func (q *worldQuotes) HealthCheck(ctx context.Context) error { return nil }
+
+func (q *worldQuotes) Close() error { return nil }
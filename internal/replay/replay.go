@@ -0,0 +1,701 @@
+// Package replay drives a recorded event log through the decision, sizing
+// and paper execution pipeline on a simulated clock and writes a decision
+// journal. The same inputs, config and seed always produce the same journal
+// byte for byte, which makes it the regression net for config changes.
+//
+// The risk manager, caps, cooldowns and outbox guard run on the simulated
+// clock too. Instead of the risk manager's background loops, replay updates
+// the NAV tracker and circuit breaker after every event.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/broker"
+	"github.com/Rajchodisetti/trading-app/internal/clock"
+	"github.com/Rajchodisetti/trading-app/internal/config"
+	"github.com/Rajchodisetti/trading-app/internal/decision"
+	"github.com/Rajchodisetti/trading-app/internal/durable"
+	"github.com/Rajchodisetti/trading-app/internal/ingest"
+	"github.com/Rajchodisetti/trading-app/internal/observ"
+	"github.com/Rajchodisetti/trading-app/internal/outbox"
+	"github.com/Rajchodisetti/trading-app/internal/portfolio"
+	"github.com/Rajchodisetti/trading-app/internal/risk"
+	"github.com/Rajchodisetti/trading-app/internal/strategy"
+	"github.com/Rajchodisetti/trading-app/internal/transport"
+)
+
+// Options configure a replay run
+type Options struct {
+	Config config.Root
+	Caps   config.CapsCooldownFile // caps, cooldowns, guard limits and gate priorities
+
+	Seed         int64     // fill latency and slippage draws; 0 uses 1
+	Dir          string    // portfolio state and outbox; must not hold a previous run
+	Start        time.Time // market time before the first timestamped event
+	StepMs       int       // clock step while orders are working between events (default 100)
+	DrainSeconds int       // how long working orders may still fill after the last event (default 300)
+}
+
+// Entry is one line of the decision journal
+type Entry struct {
+	Seq      int                `json:"seq"`
+	TS       time.Time          `json:"ts_utc"`
+	Type     string             `json:"type"` // decision | order | fill | borrow
+	EventID  string             `json:"event_id,omitempty"`
+	Symbol   string             `json:"symbol"`
+	Intent   string             `json:"intent,omitempty"`
+	Notional float64            `json:"notional,omitempty"`
+	Price    float64            `json:"price,omitempty"`   // last trade the decision saw
+	Sources  []string           `json:"sources,omitempty"` // news behind a decision: pr, editorial
+	Reason   json.RawMessage    `json:"reason,omitempty"`
+	Order    *broker.OrderState `json:"order,omitempty"`
+	Fill     *outbox.Fill       `json:"fill,omitempty"`
+	Amount   float64            `json:"amount,omitempty"` // borrow charged on open shorts
+}
+
+// Summary counts what a run did
+type Summary struct {
+	Events    int       `json:"events"`
+	Skipped   int       `json:"skipped"`
+	Decisions int       `json:"decisions"`
+	Orders    int       `json:"orders"`
+	Fills     int       `json:"fills"`
+	Start     time.Time `json:"start_utc"`
+	End       time.Time `json:"end_utc"`
+	NAV       float64   `json:"nav"`
+}
+
+// ReadJournal decodes a decision journal written by Run
+func ReadJournal(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	dec := json.NewDecoder(r)
+	for {
+		var e Entry
+		if err := dec.Decode(&e); err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("journal entry %d: %w", len(entries)+1, err)
+		}
+		entries = append(entries, e)
+	}
+}
+
+// runner holds one run's pipeline; it is single-threaded by construction
+type runner struct {
+	opts       Options
+	clock      *clock.Sim
+	world      *ingest.World
+	strategies []strategy.Strategy
+	engine     *decision.Engine
+	riskState  decision.RiskState
+
+	portfolio *portfolio.Manager
+	stopLoss  *risk.StopLossManager
+	drawdown  *risk.DrawdownManager
+	marker    *risk.MarkToMarket
+	riskMgr   *risk.RiskManager
+	caps      *risk.PositionCapsManager
+	cooldown  *risk.CooldownManager
+	guard     *risk.OutboxGuard
+	ob        *outbox.Outbox
+	broker    *broker.PaperBroker
+	sizer     *risk.Sizer
+	pricing   broker.PricingConfig
+	fees      broker.FeeSchedule
+
+	journal *json.Encoder
+	seq     int
+	eventID string
+	working map[string]bool // order id -> still working
+	summary Summary
+}
+
+// Run replays events in order and writes the decision journal to w
+func Run(ctx context.Context, opts Options, events []transport.EventEnvelope, w io.Writer) (Summary, error) {
+	if opts.Dir == "" {
+		return Summary{}, fmt.Errorf("replay needs a working directory")
+	}
+	if opts.Seed == 0 {
+		opts.Seed = 1
+	}
+	if opts.StepMs <= 0 {
+		opts.StepMs = 100
+	}
+	if opts.DrainSeconds <= 0 {
+		opts.DrainSeconds = 300
+	}
+
+	r, err := newRunner(opts, events, w)
+	if err != nil {
+		return Summary{}, err
+	}
+	defer r.close()
+
+	for _, env := range events {
+		if err := ctx.Err(); err != nil {
+			return r.summary, err
+		}
+		if err := r.apply(ctx, env); err != nil {
+			return r.summary, err
+		}
+	}
+
+	// Let working orders finish against the last known book
+	r.eventID = ""
+	if err := r.advance(ctx, r.clock.Now().Add(time.Duration(opts.DrainSeconds)*time.Second)); err != nil {
+		return r.summary, err
+	}
+
+	r.summary.End = r.clock.Now().UTC()
+	r.summary.NAV = r.portfolio.GetNAV()
+	return r.summary, nil
+}
+
+func newRunner(opts Options, events []transport.EventEnvelope, w io.Writer) (*runner, error) {
+	cfg := opts.Config
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(filepath.Join(opts.Dir, "outbox.jsonl")); err == nil {
+		return nil, fmt.Errorf("replay directory %s holds a previous run", opts.Dir)
+	}
+
+	start := opts.Start
+	if start.IsZero() {
+		start = firstEventTime(events)
+	}
+	clk := clock.NewSim(start.UTC())
+
+	portfolioMgr := portfolio.NewManager(filepath.Join(opts.Dir, "portfolio_state.json"), cfg.BaseUSD)
+	portfolioMgr.SetSyncPolicy(durable.SyncNone)
+	portfolioMgr.SetClock(clk)
+	if err := portfolioMgr.SetLotMethod(cfg.Portfolio.LotMethod); err != nil {
+		return nil, err
+	}
+	if err := portfolioMgr.SetMaxClosedLots(cfg.Portfolio.MaxClosedLots); err != nil {
+		return nil, err
+	}
+
+	ob, err := outbox.NewWithOptions(filepath.Join(opts.Dir, "outbox.jsonl"), cfg.Paper.DedupeWindowSecs, outbox.Options{
+		Sync:  durable.SyncConfig{Policy: durable.SyncNone},
+		Clock: clk,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open replay outbox: %w", err)
+	}
+
+	r := &runner{
+		opts:      opts,
+		clock:     clk,
+		world:     ingest.NewWorld(),
+		portfolio: portfolioMgr,
+		ob:        ob,
+		journal:   json.NewEncoder(w),
+		working:   make(map[string]bool),
+		riskState: decision.RiskState{
+			GlobalPause:     cfg.GlobalPause,
+			BlockPremarket:  cfg.Session.BlockPremarket,
+			BlockPostmarket: cfg.Session.BlockPostmarket,
+			MaxSpreadBps:    cfg.Liquidity.MaxSpreadBps,
+		},
+		pricing: broker.PricingConfig{
+			OrderType:      cfg.Execution.OrderType,
+			LimitOffsetBps: cfg.Execution.LimitOffsetBps,
+			Protection: broker.PriceProtection{
+				AfterHours:     cfg.Execution.Protection.AfterHours,
+				SpreadBps:      cfg.Execution.Protection.SpreadBps,
+				MaxSlippageBps: cfg.Execution.Protection.MaxSlippageBps,
+			},
+		},
+		summary: Summary{Start: clk.Now().UTC()},
+	}
+
+	var sectorMgr *risk.SectorExposureManager
+	if cfg.RiskControls.StopLoss.Enabled {
+		r.stopLoss = risk.NewStopLossManager()
+	}
+	if cfg.RiskControls.SectorLimits.Enabled {
+		sectorMgr = risk.NewSectorExposureManager(cfg.RiskControls.SectorLimits.SectorMap)
+	}
+	if cfg.RiskControls.Drawdown.Enabled {
+		r.drawdown = risk.NewDrawdownManager()
+	}
+
+	var borrowList *risk.BorrowList
+	if cfg.Short.Enabled {
+		if borrowList, err = risk.LoadBorrowList(cfg.Short.ETBListPath); err != nil {
+			ob.Close()
+			return nil, err
+		}
+	}
+
+	// The engine only sees the portfolio when the live pipeline would
+	var enginePortfolio *portfolio.Manager
+	if cfg.Portfolio.Enabled {
+		enginePortfolio = portfolioMgr
+	}
+	engineCfg := decision.ConfigFrom(cfg, opts.Caps.Integration)
+	r.engine, err = decision.NewEngine(engineCfg,
+		decision.WithPortfolio(enginePortfolio),
+		decision.WithStopLoss(r.stopLoss),
+		decision.WithSectorExposure(sectorMgr),
+		decision.WithDrawdown(r.drawdown),
+		decision.WithBorrowList(borrowList),
+		decision.WithClock(clk),
+	)
+	if err != nil {
+		ob.Close()
+		return nil, fmt.Errorf("invalid engine config: %w", err)
+	}
+	if !broker.ValidOrderType(cfg.Execution.OrderType) {
+		ob.Close()
+		return nil, fmt.Errorf("unknown execution order type %q", cfg.Execution.OrderType)
+	}
+
+	r.strategies, err = strategy.DefaultRegistry().Build(cfg.Strategies)
+	if err != nil {
+		ob.Close()
+		return nil, fmt.Errorf("build strategies: %w", err)
+	}
+
+	// As live, the breaker's multiplier reaches orders through the risk
+	// manager's verdict, not the sizer
+	r.sizer = risk.NewSizer(risk.SizingConfig{
+		LotSize:        cfg.Sizing.LotSize,
+		MinNotionalUSD: cfg.Sizing.MinNotionalUSD,
+	}, r.drawdown, nil)
+	r.fees = broker.FeeSchedule(cfg.Paper.Fees)
+	r.broker = broker.NewPaperBroker(broker.PaperConfig{
+		LatencyMsMin:      cfg.Paper.LatencyMsMin,
+		LatencyMsMax:      cfg.Paper.LatencyMsMax,
+		SlippageBpsMin:    cfg.Paper.SlippageBpsMin,
+		SlippageBpsMax:    cfg.Paper.SlippageBpsMax,
+		ParticipationRate: cfg.Paper.ParticipationRate,
+		PartialMinQty:     cfg.Paper.PartialMinQty,
+		PartialIntervalMs: cfg.Paper.PartialIntervalMs,
+		Clock:             clk,
+		Seed:              opts.Seed,
+		Fees:              r.fees,
+	}, &worldQuotes{world: r.world, clock: clk})
+	if cfg.MarkToMarket.Enabled {
+		r.marker = risk.NewMarkToMarket(portfolioMgr, &worldQuotes{world: r.world, clock: clk}, risk.MarkConfig{
+			PriceSource:  cfg.MarkToMarket.PriceSource,
+			StaleAfterMs: cfg.MarkToMarket.StaleAfterMs,
+			MaxSpreadBps: cfg.MarkToMarket.MaxSpreadBps,
+		})
+		r.marker.SetClock(clk)
+	}
+
+	// Caps, cooldowns and the guard keep their state in the run directory
+	quotes := &worldQuotes{world: r.world, clock: clk}
+	capsCfg := opts.Caps.CapsCooldown
+	r.caps = risk.NewPositionCapsManager(portfolioMgr, quotes, risk.CapsConfig{
+		Enforce:              capsCfg.Enforce,
+		DefaultSymbolCapUSD:  capsCfg.DefaultSymbolCapUSD,
+		MaxSingleSymbolPct:   capsCfg.MaxSingleSymbolPct,
+		DailyTradeLimit:      capsCfg.DailyTradeLimit,
+		SymbolSpecificCaps:   capsCfg.SymbolSpecificCaps,
+		PortfolioCapsEnabled: capsCfg.PortfolioCapsEnabled,
+		RTHOpenHour:          capsCfg.RTHOpenHour,
+		RTHOpenMinute:        capsCfg.RTHOpenMinute,
+		PersistPath:          filepath.Join(opts.Dir, "caps_state.json"),
+	})
+	r.cooldown = risk.NewCooldownManager(risk.CooldownConfig{
+		Enforce:                 capsCfg.Cooldown.Enforce,
+		DefaultCooldownSec:      capsCfg.Cooldown.DefaultCooldownSec,
+		GlobalCooldownSec:       capsCfg.Cooldown.GlobalCooldownSec,
+		SameSideCooldownSec:     capsCfg.Cooldown.SameSideCooldownSec,
+		IntentSpecificCooldowns: capsCfg.Cooldown.IntentCooldowns,
+		SymbolCooldowns:         capsCfg.Cooldown.SymbolCooldowns,
+		OppositeTradesAllowed:   capsCfg.Cooldown.OppositeTradesAllowed,
+		VolatilityAdjustments:   capsCfg.Cooldown.VolatilityAdjustments,
+		PersistPath:             filepath.Join(opts.Dir, "cooldown_state.json"),
+	})
+	r.caps.SetClock(clk)
+	r.cooldown.SetClock(clk)
+
+	if cfg.RiskManager.Enabled {
+		r.riskMgr = risk.NewRiskManager(portfolioMgr, quotes, risk.RiskManagerConfig{
+			NAVTracker: risk.NAVTrackerConfig{
+				UpdateIntervalSeconds:     cfg.RiskManager.UpdateIntervalSeconds,
+				QuoteStalenessThresholdMs: cfg.RiskManager.QuoteStalenessThresholdMs,
+				UseMidPrice:               true,
+				PersistPath:               filepath.Join(opts.Dir, "nav_state.json"),
+			},
+			EventLogPath:          filepath.Join(opts.Dir, "risk_events.jsonl"),
+			BreakerLogPath:        filepath.Join(opts.Dir, "circuit_breaker_events.jsonl"),
+			UpdateIntervalSeconds: cfg.RiskManager.UpdateIntervalSeconds,
+		})
+		r.riskMgr.SetClock(clk)
+		r.riskMgr.SetPositionControls(r.caps, r.cooldown)
+		if r.marker != nil {
+			r.riskMgr.SetMarker(r.marker)
+		}
+		r.riskMgr.SetGateClasses(engineCfg.Gates.Hard, engineCfg.Gates.Soft)
+	}
+
+	r.guard = risk.NewOutboxGuardWithConfig(r.caps, quotes, nil, risk.OutboxGuardConfig{
+		MaxPriceDriftPct:        opts.Caps.RiskMitigation.MaxPriceDriftPct,
+		MaxDecisionStalenessSec: opts.Caps.RiskMitigation.MaxDecisionStalenessSec,
+	})
+	r.guard.SetClock(clk)
+	return r, nil
+}
+
+// close releases the broker and outbox; the broker was never started
+func (r *runner) close() {
+	r.broker.Close()
+	r.ob.Close()
+}
+
+// apply moves the clock to the event, lets due orders execute, then
+// re-evaluates every symbol the event touched
+func (r *runner) apply(ctx context.Context, env transport.EventEnvelope) error {
+	ev, err := ingest.ParseEnvelope(env)
+	if err != nil {
+		r.summary.Skipped++
+		observ.IncCounter("replay_events_invalid_total", map[string]string{"type": env.Type})
+		return nil
+	}
+	r.summary.Events++
+
+	if t := eventTime(env, ev); !t.IsZero() {
+		if err := r.advance(ctx, t); err != nil {
+			return err
+		}
+	}
+	r.eventID = env.ID
+	charged, err := r.portfolio.AccrueBorrow(r.clock.Now(), r.fees.BorrowFee)
+	if err != nil {
+		return fmt.Errorf("accrue borrow: %w", err)
+	}
+	if charged > 0 {
+		if err := r.write(Entry{Type: "borrow", Amount: charged}); err != nil {
+			return err
+		}
+	}
+
+	touched := r.world.Apply(ev)
+	if len(touched) > 0 {
+		// Mark the book at the new prices before anything is decided on it;
+		// the NAV tracker marks it when the risk manager runs
+		if r.riskMgr != nil {
+			if err := r.riskMgr.Update(ctx); err != nil {
+				return fmt.Errorf("update risk manager: %w", err)
+			}
+		} else if r.marker != nil {
+			r.marker.Mark(ctx)
+		}
+	}
+	for _, sym := range touched {
+		if err := r.evaluate(ctx, sym); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advance steps the clock to t, executing working orders at each step.
+// With nothing working the clock jumps straight there.
+func (r *runner) advance(ctx context.Context, t time.Time) error {
+	step := time.Duration(r.opts.StepMs) * time.Millisecond
+	for len(r.working) > 0 && r.clock.Now().Before(t) {
+		next := r.clock.Now().Add(step)
+		if next.After(t) {
+			next = t
+		}
+		r.clock.Set(next)
+		r.broker.Process(ctx)
+		if err := r.drain(); err != nil {
+			return err
+		}
+	}
+	r.clock.Set(t)
+	return nil
+}
+
+// evaluate mirrors the live pipeline for one symbol: advice, decision,
+// drawdown and stop-loss bookkeeping, then sizing and submission
+func (r *runner) evaluate(ctx context.Context, sym string) error {
+	feat := r.world.Features(sym)
+	now := r.clock.Now()
+	advs := strategy.ScoreAll(r.strategies, strategy.Inputs{
+		Symbol:   sym,
+		News:     r.world.News(sym),
+		Ticks:    r.world.Ticks(sym),
+		Features: feat,
+		Now:      now,
+	})
+	act, err := r.engine.Evaluate(ctx, sym, decision.Inputs{
+		Advice:   advs,
+		Features: feat,
+		Risk:     r.riskState,
+		Earnings: r.world.Earnings(),
+	})
+	if err != nil {
+		return err
+	}
+	if r.riskMgr != nil && broker.SideForIntent(act.Intent) != "" {
+		act = decision.ApplyRiskResult(act, r.riskMgr.EvaluateDecision(riskContext(act, feat, now)))
+	}
+	r.summary.Decisions++
+	if err := r.write(Entry{
+		Type:     "decision",
+		Symbol:   sym,
+		Intent:   act.Intent,
+		Notional: act.ScaledNotional,
+		Price:    feat.Last,
+		Sources:  newsSources(advs),
+		Reason:   json.RawMessage(act.ReasonJSON),
+	}); err != nil {
+		return err
+	}
+
+	engineCfg := r.engine.Config()
+	if r.drawdown != nil {
+		r.drawdown.UpdateNAV(r.portfolio.GetNAV(), now, engineCfg.RiskControls.Drawdown)
+	}
+	if r.stopLoss != nil && feat.Last > 0 {
+		if entryVWAP, ok := r.portfolio.GetStopEntry(sym, engineCfg.RiskControls.StopLoss.EntryReference); ok {
+			pos, _ := r.portfolio.GetPosition(sym)
+			isAfterHours := feat.Premarket || feat.Postmarket
+			triggered, err := r.stopLoss.CheckStopLoss(sym, feat.Last, entryVWAP, pos.Quantity < 0, engineCfg.RiskControls.StopLoss, isAfterHours, now)
+			if err != nil {
+				return fmt.Errorf("stop-loss check for %s: %w", sym, err)
+			}
+			if triggered {
+				// The stop exit replaces the decision's own order
+				return r.submit(ctx, decision.StopLossAction(sym, pos.Quantity, feat.Last), feat)
+			}
+		}
+	}
+
+	return r.submit(ctx, act, feat)
+}
+
+// riskContext describes an actionable decision to the risk manager, as the
+// live pipeline does
+func riskContext(act decision.ProposedAction, feat decision.Features, decidedAt time.Time) risk.DecisionContext {
+	var reason struct {
+		FusedScore float64 `json:"fused_score"`
+	}
+	_ = json.Unmarshal([]byte(act.ReasonJSON), &reason)
+
+	qty := 0
+	if feat.Last > 0 {
+		qty = int(act.ScaledNotional / feat.Last)
+	}
+	return risk.DecisionContext{
+		Symbol:   act.Symbol,
+		Intent:   act.Intent,
+		Quantity: qty,
+		Price:    feat.Last,
+		Strategy: "fusion",
+		Score:    reason.FusedScore,
+		Features: map[string]interface{}{
+			"spread_bps": feat.SpreadBps,
+			"rel_volume": feat.RelVolume,
+			"vwap_5m":    feat.VWAP5m,
+		},
+		CorrelationID: fmt.Sprintf("%s_%d", act.Symbol, decidedAt.UnixNano()),
+		Timestamp:     decidedAt,
+	}
+}
+
+// newsSources lists the kinds of news behind the advice, sorted; advice
+// without a provider (price-only strategies) is not news
+func newsSources(advs []decision.Advice) []string {
+	seen := map[string]bool{}
+	for _, a := range advs {
+		switch {
+		case a.Provider == "":
+		case a.IsPR:
+			seen["pr"] = true
+		default:
+			seen["editorial"] = true
+		}
+	}
+	var out []string
+	for _, src := range []string{"editorial", "pr"} {
+		if seen[src] {
+			out = append(out, src)
+		}
+	}
+	return out
+}
+
+// submit sizes and prices an actionable decision, rechecks it through the
+// outbox guard and sends it to the paper broker
+func (r *runner) submit(ctx context.Context, act decision.ProposedAction, feat decision.Features) error {
+	side := broker.SideForIntent(act.Intent)
+	if side == "" {
+		return nil
+	}
+	now := r.clock.Now().UTC()
+
+	var reason struct {
+		FusedScore float64 `json:"fused_score"`
+	}
+	if err := json.Unmarshal([]byte(act.ReasonJSON), &reason); err != nil {
+		return fmt.Errorf("parse reason for idempotency: %w", err)
+	}
+	idempotencyKey := outbox.GenerateIdempotencyKey(act.Symbol, act.Intent, now, reason.FusedScore)
+	if recent, err := r.ob.HasRecentOrder(idempotencyKey); err != nil {
+		return fmt.Errorf("check recent orders: %w", err)
+	} else if recent {
+		return nil
+	}
+
+	positionQty := 0
+	if pos, ok := r.portfolio.GetPosition(act.Symbol); ok {
+		positionQty = pos.Quantity
+	}
+	size := r.sizer.Size(risk.SizeRequest{
+		Symbol:      act.Symbol,
+		Intent:      act.Intent,
+		NotionalUSD: act.ScaledNotional,
+		Price:       feat.Last,
+		PositionQty: positionQty,
+	})
+	if size.Quantity == 0 {
+		return nil
+	}
+
+	market := broker.Market{
+		Bid:        feat.Bid,
+		Ask:        feat.Ask,
+		Last:       feat.Last,
+		AfterHours: feat.Premarket || feat.Postmarket,
+	}
+	req, _ := r.pricing.Price(broker.OrderRequest{
+		ClientOrderID: idempotencyKey,
+		Symbol:        act.Symbol,
+		Side:          side,
+		Quantity:      size.Quantity,
+		Intent:        act.Intent,
+	}, market)
+
+	// Decisions are submitted at the time they are made, so only caps and
+	// price drift can stop an order here
+	guardReq := risk.CreateOrderRequest(outbox.Order{
+		ID:             idempotencyKey,
+		Symbol:         act.Symbol,
+		Intent:         act.Intent,
+		Timestamp:      now,
+		Status:         string(broker.StatusNew),
+		IdempotencyKey: idempotencyKey,
+		Side:           side,
+		Quantity:       req.Quantity,
+		NotionalUSD:    size.NotionalUSD,
+		OrderType:      req.Type,
+		LimitPrice:     req.LimitPrice,
+		TimeInForce:    broker.TimeInForce(req.Type),
+	}, now, risk.DecisionContext{
+		Symbol:        act.Symbol,
+		Intent:        act.Intent,
+		Quantity:      req.Quantity,
+		Price:         market.Mid(),
+		Score:         reason.FusedScore,
+		CorrelationID: idempotencyKey,
+		Timestamp:     now,
+	}, &risk.ExposureInfo{MidPrice: market.Mid()}, nil)
+	result, err := r.guard.Validate(guardReq)
+	if err != nil {
+		return err
+	}
+	if !result.Approved {
+		return r.guard.WriteCancellation(r.ob, guardReq, result)
+	}
+
+	if _, err := r.broker.Submit(ctx, req); err != nil {
+		return fmt.Errorf("submit order: %w", err)
+	}
+	r.caps.RecordTrade(act.Symbol, side, size.NotionalUSD)
+	r.cooldown.RecordTrade(act.Symbol, act.Intent, now)
+	r.summary.Orders++
+	return r.drain()
+}
+
+// drain records everything the broker published since the last call.
+// The broker is never started, so its streams only fill inside Submit and
+// Process. Fills are taken before status updates so the journal order does
+// not depend on which channel select picks.
+func (r *runner) drain() error {
+	for {
+		select {
+		case fill := <-r.broker.Fills():
+			if err := r.recordFill(fill); err != nil {
+				return err
+			}
+			continue
+		default:
+		}
+		select {
+		case state := <-r.broker.Updates():
+			if err := r.recordOrder(state); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+func (r *runner) recordOrder(state broker.OrderState) error {
+	if state.Status.Terminal() {
+		delete(r.working, state.ID)
+	} else {
+		r.working[state.ID] = true
+	}
+	order := outbox.Order{
+		ID:             state.ID,
+		Symbol:         state.Symbol,
+		Intent:         state.Intent,
+		Timestamp:      state.UpdatedAt,
+		Status:         string(state.Status),
+		IdempotencyKey: state.ClientOrderID,
+		Side:           state.Side,
+		Quantity:       state.Quantity,
+		OrderType:      state.Type,
+		LimitPrice:     state.LimitPrice,
+		TimeInForce:    broker.TimeInForce(state.Type),
+		FilledQty:      state.FilledQty,
+	}
+	if err := r.ob.WriteOrder(order); err != nil {
+		return fmt.Errorf("write order: %w", err)
+	}
+	return r.write(Entry{Type: "order", Symbol: state.Symbol, Intent: state.Intent, Order: &state})
+}
+
+func (r *runner) recordFill(fill outbox.Fill) error {
+	if err := r.ob.WriteFill(fill); err != nil {
+		return fmt.Errorf("write fill: %w", err)
+	}
+	qty := int(fill.Quantity)
+	if fill.Side == "SELL" {
+		qty = -qty
+	}
+	if err := r.portfolio.ApplyFill(fill.OrderID, fill.Symbol, qty, fill.Price, portfolio.Costs{Commission: fill.Commission, Fees: fill.Fees}, fill.Timestamp); err != nil {
+		// The live pipeline logs and carries on; so does replay
+		observ.Log("replay_fill_not_applied", map[string]any{"order_id": fill.OrderID, "error": err.Error()})
+	}
+	r.summary.Fills++
+	return r.write(Entry{Type: "fill", Symbol: fill.Symbol, Fill: &fill})
+}
+
+// write stamps an entry with the next sequence number and the market clock
+func (r *runner) write(e Entry) error {
+	r.seq++
+	e.Seq = r.seq
+	e.TS = r.clock.Now().UTC()
+	e.EventID = r.eventID
+	return r.journal.Encode(e)
+}
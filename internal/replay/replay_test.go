@@ -0,0 +1,264 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/config"
+	"github.com/Rajchodisetti/trading-app/internal/transport"
+)
+
+const testConfig = `
+trading_mode: paper
+global_pause: false
+base_usd: 2000
+thresholds: {positive: 0.35, very_positive: 0.65, negative: -0.35, very_negative: -0.65}
+liquidity: {max_spread_bps: 50}
+paper: {latency_ms_min: 100, latency_ms_max: 900, slippage_bps_min: 1, slippage_bps_max: 9, dedupe_window_seconds: 90}
+execution: {order_type: MKT}
+`
+
+func loadTestConfig(t *testing.T) config.Root {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(testConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func envelope(t *testing.T, typ, id, ts string, payload any) transport.EventEnvelope {
+	t.Helper()
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	when, _ := time.Parse(time.RFC3339, ts)
+	return transport.EventEnvelope{V: 1, Type: typ, ID: id, TS: when, Payload: raw}
+}
+
+func testEvents(t *testing.T) []transport.EventEnvelope {
+	tick := func(id, ts string, last float64) transport.EventEnvelope {
+		return envelope(t, "tick", id, ts, map[string]any{
+			"ts_utc": ts, "symbol": "AAPL", "last": last, "vwap_5m": last - 1, "rel_volume": 2,
+			"bid": last - 0.05, "ask": last + 0.05,
+		})
+	}
+	return []transport.EventEnvelope{
+		tick("1", "2025-08-25T14:00:00Z", 200),
+		envelope(t, "news", "2", "2025-08-25T14:00:05Z", map[string]any{
+			"id": "n1", "provider": "reuters", "tickers": []string{"AAPL"}, "headline_hash": "h1",
+			"published_at_utc": "2025-08-25T14:00:05Z",
+		}),
+		tick("3", "2025-08-25T14:05:00Z", 201),
+		envelope(t, "bogus", "4", "", map[string]any{}),
+		tick("5", "2025-08-25T15:00:00Z", 203),
+	}
+}
+
+func TestRun_SameSeedProducesIdenticalJournal(t *testing.T) {
+	cfg := loadTestConfig(t)
+	events := testEvents(t)
+
+	run := func(seed int64) ([]byte, Summary) {
+		var buf bytes.Buffer
+		summary, err := Run(context.Background(), Options{Config: cfg, Seed: seed, Dir: t.TempDir()}, events, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes(), summary
+	}
+
+	first, summary := run(7)
+	second, _ := run(7)
+	if !bytes.Equal(first, second) {
+		t.Fatalf("journals differ between runs with the same seed:\n%s\n---\n%s", first, second)
+	}
+	if summary.Events != 4 || summary.Skipped != 1 {
+		t.Fatalf("want 4 events and 1 skipped, got %+v", summary)
+	}
+	if summary.Orders == 0 || summary.Fills == 0 {
+		t.Fatalf("want the positive news to trade, got %+v\n%s", summary, first)
+	}
+
+	// Every line is stamped on the replayed clock, never the wall clock
+	for _, line := range strings.Split(strings.TrimSpace(string(first)), "\n") {
+		var e Entry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatal(err)
+		}
+		if e.TS.Year() != 2025 {
+			t.Fatalf("entry stamped off the simulated clock: %s", line)
+		}
+	}
+
+	other, _ := run(8)
+	if bytes.Equal(first, other) {
+		t.Fatal("want the seed to change simulated fills")
+	}
+}
+
+func TestRun_RiskManagerAndCooldownGateOrders(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.RiskManager.Enabled = true
+	var caps config.CapsCooldownFile
+	caps.CapsCooldown.Cooldown.Enforce = true
+	caps.CapsCooldown.Cooldown.DefaultCooldownSec = 3600
+	caps.Integration.SoftGatesEnabled = true
+	caps.Integration.SoftConversionGates = []string{"caps", "cooldown"}
+
+	run := func() ([]byte, Summary) {
+		var buf bytes.Buffer
+		summary, err := Run(context.Background(), Options{Config: cfg, Caps: caps, Seed: 7, Dir: t.TempDir()}, testEvents(t), &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes(), summary
+	}
+	first, summary := run()
+	second, _ := run()
+	if !bytes.Equal(first, second) {
+		t.Fatalf("journals differ with the risk manager on:\n%s\n---\n%s", first, second)
+	}
+
+	entries, err := ReadJournal(bytes.NewReader(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var intents []string
+	for _, e := range entries {
+		if e.Type != "decision" {
+			continue
+		}
+		intents = append(intents, e.Intent)
+		if !strings.Contains(string(e.Reason), `"risk_manager"`) {
+			t.Fatalf("decision not evaluated by the risk manager: %s", e.Reason)
+		}
+	}
+	// The first buy starts an hour's cooldown; buys inside it become HOLD
+	if strings.Join(intents, ",") != "BUY_1X,HOLD,HOLD,BUY_5X" {
+		t.Fatalf("unexpected intents %v\n%s", intents, first)
+	}
+	if summary.Orders != 2 {
+		t.Fatalf("want 2 orders, got %+v", summary)
+	}
+}
+
+func TestRun_CircuitBreakerScalesEntriesOnce(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.RiskManager.Enabled = true
+
+	// The day opened at a NAV 3.2% above the replay's starting cash, which
+	// puts the breaker in restricted (half size) from the first update
+	dir := t.TempDir()
+	state, _ := json.Marshal(map[string]any{
+		"start_of_day_nav": 2000 / 0.968,
+		"high_water_mark":  2000 / 0.968,
+		"last_nav":         2000,
+		"last_update":      "2025-08-25T13:59:00Z",
+		"trading_date":     "2025-08-25",
+	})
+	if err := os.WriteFile(filepath.Join(dir, "nav_state.json"), state, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := Run(context.Background(), Options{Config: cfg, Seed: 7, Dir: dir}, testEvents(t)[:1], &buf); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadJournal(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) < 2 || entries[0].Type != "decision" || entries[1].Type != "order" {
+		t.Fatalf("want a decision and its order, got %+v", entries)
+	}
+	// BUY_1X is $2000; at $200 a full-size order is 10 shares
+	if entries[0].Notional != 1000 || entries[1].Order.Quantity != 5 {
+		t.Fatalf("want $1000 and 5 shares after one 0.5x breaker cut, got $%v and %d shares",
+			entries[0].Notional, entries[1].Order.Quantity)
+	}
+}
+
+func TestRun_StopLossSellsThePosition(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.RiskControls.StopLoss = config.StopLoss{Enabled: true, DefaultStopLossPct: 5, CooldownHours: 24}
+	tick := func(id, ts string, last float64) transport.EventEnvelope {
+		return envelope(t, "tick", id, ts, map[string]any{
+			"ts_utc": ts, "symbol": "AAPL", "last": last, "vwap_5m": last - 1, "rel_volume": 2,
+			"bid": last - 0.05, "ask": last + 0.05,
+		})
+	}
+	// Bought at 200, then 10% lower
+	events := []transport.EventEnvelope{
+		tick("1", "2025-08-25T14:00:00Z", 200),
+		tick("2", "2025-08-25T14:05:00Z", 180),
+	}
+
+	var buf bytes.Buffer
+	summary, err := Run(context.Background(), Options{Config: cfg, Seed: 7, Dir: t.TempDir()}, events, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadJournal(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stop *Entry
+	for i, e := range entries {
+		if e.Type == "order" && e.EventID == "2" {
+			stop = &entries[i]
+			break
+		}
+	}
+	if stop == nil || stop.Intent != "REDUCE" || stop.Order.Side != "SELL" || stop.Order.Quantity != 10 {
+		t.Fatalf("want the stop to sell all 10 shares, got %+v", entries)
+	}
+	if summary.NAV >= 2000 || summary.Fills != 2 {
+		t.Fatalf("want the position bought and stopped out at a loss, got %+v", summary)
+	}
+}
+
+func TestRun_RejectsUsedDirectory(t *testing.T) {
+	cfg := loadTestConfig(t)
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	if _, err := Run(context.Background(), Options{Config: cfg, Dir: dir}, testEvents(t), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Run(context.Background(), Options{Config: cfg, Dir: dir}, testEvents(t), &buf); err == nil {
+		t.Fatal("want an error replaying into a directory that holds a previous run")
+	}
+}
+
+func TestReadEvents(t *testing.T) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, env := range testEvents(t) {
+		if err := enc.Encode(env); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf.WriteString("\n")
+
+	envs, err := ReadEvents(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envs) != 5 || envs[1].Type != "news" || !envs[2].TS.Equal(time.Date(2025, 8, 25, 14, 5, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected events: %+v", envs)
+	}
+	if _, err := ReadEvents(strings.NewReader("{not json}\n")); err == nil {
+		t.Fatal("want an error for a malformed line")
+	}
+}
@@ -0,0 +1,85 @@
+package risk
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Rajchodisetti/trading-app/internal/observ"
+)
+
+// BorrowList is the easy-to-borrow (ETB) list: symbols the broker can locate
+// for a short sale. Symbols not on it are never shorted.
+type BorrowList struct {
+	mu      sync.RWMutex
+	path    string
+	symbols map[string]bool
+}
+
+// LoadBorrowList reads an ETB list: one symbol per line, with blank lines and
+// # comments ignored
+func LoadBorrowList(path string) (*BorrowList, error) {
+	b := &BorrowList{path: path}
+	if err := b.Reload(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// NewBorrowList builds a list from symbols, for tests and fixed universes
+func NewBorrowList(symbols ...string) *BorrowList {
+	b := &BorrowList{symbols: make(map[string]bool, len(symbols))}
+	for _, s := range symbols {
+		b.symbols[strings.ToUpper(s)] = true
+	}
+	return b
+}
+
+// Reload rereads the list from its file; on error the current list is kept
+func (b *BorrowList) Reload() error {
+	f, err := os.Open(b.path)
+	if err != nil {
+		return fmt.Errorf("open borrow list: %w", err)
+	}
+	defer f.Close()
+
+	symbols := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if sym := strings.ToUpper(strings.TrimSpace(line)); sym != "" {
+			symbols[sym] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read borrow list %s: %w", b.path, err)
+	}
+
+	b.mu.Lock()
+	b.symbols = symbols
+	b.mu.Unlock()
+	observ.SetGauge("borrow_list_symbols", float64(len(symbols)), nil)
+	return nil
+}
+
+// EasyToBorrow reports whether symbol can be sold short
+func (b *BorrowList) EasyToBorrow(symbol string) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.symbols[strings.ToUpper(symbol)]
+}
+
+// Len returns the number of borrowable symbols
+func (b *BorrowList) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.symbols)
+}
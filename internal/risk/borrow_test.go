@@ -0,0 +1,49 @@
+package risk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadBorrowList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "etb.txt")
+	if err := os.WriteFile(path, []byte("# locates\naapl\n\n  NVDA  # hot\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := LoadBorrowList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Len() != 2 || !b.EasyToBorrow("AAPL") || !b.EasyToBorrow("nvda") || b.EasyToBorrow("BIOX") {
+		t.Fatalf("unexpected borrow list: %v", b.symbols)
+	}
+
+	// A failed reload keeps the current list
+	os.Remove(path)
+	if err := b.Reload(); err == nil || !b.EasyToBorrow("AAPL") {
+		t.Fatal("want the reload error and the old list kept")
+	}
+	if (*BorrowList)(nil).EasyToBorrow("AAPL") {
+		t.Fatal("want nothing borrowable without a list")
+	}
+}
+
+func TestCheckStopLoss_Short(t *testing.T) {
+	slm := NewStopLossManager()
+	cfg := StopLossConfig{Enabled: true, DefaultStopLossPct: 5, CooldownHours: 1}
+	now := time.Date(2025, 8, 25, 15, 0, 0, 0, time.UTC)
+
+	// A falling price is a gain on a short
+	if triggered, _ := slm.CheckStopLoss("TSLA", 90, 100, true, cfg, false, now); triggered {
+		t.Fatal("short stopped out on a gain")
+	}
+	triggered, err := slm.CheckStopLoss("TSLA", 106, 100, true, cfg, false, now)
+	if err != nil || !triggered {
+		t.Fatalf("want the short stopped 6%% above entry, got %v %v", triggered, err)
+	}
+	if trig := slm.triggers["TSLA_"+generatePositionID("TSLA", now)]; !trig.Short || trig.LossPct < 5.9 {
+		t.Fatalf("unexpected trigger %+v", trig)
+	}
+}
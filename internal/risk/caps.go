@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Rajchodisetti/trading-app/internal/clock"
 	"github.com/Rajchodisetti/trading-app/internal/adapters"
 	"github.com/Rajchodisetti/trading-app/internal/observ"
 	"github.com/Rajchodisetti/trading-app/internal/portfolio"
@@ -29,6 +30,8 @@ type PositionCapsManager struct {
 	
 	// Metrics
 	metricsEnabled  bool
+	
+	clock clock.Clock
 }
 
 // PositionCap represents limits for a specific symbol
@@ -80,9 +83,19 @@ func NewPositionCapsManager(portfolioMgr *portfolio.Manager, quotesAdapter adapt
 		lastResetTime:  time.Now(),
 		configVersion:  1,
 		metricsEnabled: true,
+		clock:          clock.Real{},
 	}
 }
 
+// SetClock sets the time source for cap TTLs and the daily trade reset.
+// The daily counters are treated as last reset at the clock's current time.
+func (pcm *PositionCapsManager) SetClock(c clock.Clock) {
+	pcm.mu.Lock()
+	defer pcm.mu.Unlock()
+	pcm.clock = clock.Or(c)
+	pcm.lastResetTime = pcm.clock.Now()
+}
+
 // CanIncrease checks if a proposed trade would violate position caps
 // Returns (canProceed, reason, exposureInfo, error)
 // Soft semantics: BUY→HOLD if violation, always allow REDUCE/EXIT
@@ -90,8 +103,8 @@ func (pcm *PositionCapsManager) CanIncrease(symbol, intent string, quantity int,
 	pcm.mu.RLock()
 	defer pcm.mu.RUnlock()
 	
-	// Always allow risk-reducing trades
-	if isRiskReducing(intent) {
+	// Always allow trades that add no exposure
+	if !isRiskIncreasing(intent) {
 		return true, "risk_reducing_allowed", &ExposureInfo{}, nil
 	}
 	
@@ -115,7 +128,7 @@ func (pcm *PositionCapsManager) CanIncrease(symbol, intent string, quantity int,
 	}
 	
 	// Round proposed quantity to avoid tiny overflows
-	roundedQuantity := roundQuantity(quantity)
+	roundedQuantity := roundQuantity(quantity, 1)
 	proposedExposure := currentExposure + (float64(roundedQuantity) * midPrice)
 	
 	// Get symbol cap (default or specific)
@@ -232,7 +245,7 @@ func (pcm *PositionCapsManager) UpdateSymbolCap(symbol string, newCapUSD float64
 	pcm.mu.Lock()
 	defer pcm.mu.Unlock()
 	
-	effectiveUntil := time.Now().Add(ttl)
+	effectiveUntil := pcm.clock.Now().Add(ttl)
 	
 	cap := pcm.getSymbolCap(symbol) // Get current or default
 	cap.Symbol = symbol
@@ -255,6 +268,40 @@ func (pcm *PositionCapsManager) UpdateSymbolCap(symbol string, newCapUSD float64
 	return nil
 }
 
+// RenameSymbol moves a symbol's cap override, configured cap and today's
+// trade count to its new symbol after a rename. Caps are in dollars, so
+// splits leave them alone.
+func (pcm *PositionCapsManager) RenameSymbol(from, to string) error {
+	pcm.mu.Lock()
+	defer pcm.mu.Unlock()
+	
+	if cap, exists := pcm.symbolCaps[from]; exists {
+		cap.Symbol = to
+		pcm.symbolCaps[to] = cap
+		delete(pcm.symbolCaps, from)
+	}
+	if capUSD, exists := pcm.config.SymbolSpecificCaps[from]; exists {
+		// The config map may be shared with the caller: copy before changing it
+		caps := make(map[string]float64, len(pcm.config.SymbolSpecificCaps))
+		for symbol, c := range pcm.config.SymbolSpecificCaps {
+			caps[symbol] = c
+		}
+		caps[to] = capUSD
+		delete(caps, from)
+		pcm.config.SymbolSpecificCaps = caps
+	}
+	if trades, exists := pcm.dailyTrades[from]; exists {
+		pcm.dailyTrades[to] += trades
+		delete(pcm.dailyTrades, from)
+	}
+	pcm.configVersion++
+	
+	if err := pcm.persistConfig(); err != nil {
+		return fmt.Errorf("failed to persist config: %w", err)
+	}
+	return nil
+}
+
 // GetAllExposures returns exposure information for all symbols with positions
 func (pcm *PositionCapsManager) GetAllExposures() (map[string]*ExposureInfo, error) {
 	pcm.mu.RLock()
@@ -301,7 +348,7 @@ func (pcm *PositionCapsManager) getSymbolCap(symbol string) PositionCap {
 	// Check for existing cap (including TTL overrides)
 	if cap, exists := pcm.symbolCaps[symbol]; exists {
 		// Check if TTL has expired
-		if !cap.EffectiveUntil.IsZero() && time.Now().After(cap.EffectiveUntil) {
+		if !cap.EffectiveUntil.IsZero() && pcm.clock.Now().After(cap.EffectiveUntil) {
 			// TTL expired, remove override
 			delete(pcm.symbolCaps, symbol)
 		} else {
@@ -329,18 +376,19 @@ func (pcm *PositionCapsManager) getCurrentExposure(symbol string) (float64, erro
 		return 0, nil
 	}
 	
-	// Get current quote for mid price
-	quote, err := pcm.quotesAdapter.GetQuote(context.Background(), symbol)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get quote for %s: %w", symbol, err)
-	}
-	
+	// Get current quote for mid price; without one, use the latest mark
 	var price float64
-	if quote.Bid > 0 && quote.Ask > 0 {
+	quote, err := pcm.quotesAdapter.GetQuote(context.Background(), symbol)
+	switch {
+	case err == nil && quote.Bid > 0 && quote.Ask > 0:
 		price = (quote.Bid + quote.Ask) / 2 // Mid price
-	} else if quote.Last > 0 {
+	case err == nil && quote.Last > 0:
 		price = quote.Last // Fallback to last
-	} else {
+	case position.LastPrice > 0:
+		price = position.LastPrice
+	case err != nil:
+		return 0, fmt.Errorf("failed to get quote for %s: %w", symbol, err)
+	default:
 		return 0, fmt.Errorf("no valid price for %s", symbol)
 	}
 	
@@ -349,33 +397,13 @@ func (pcm *PositionCapsManager) getCurrentExposure(symbol string) (float64, erro
 	return exposure, nil
 }
 
+// getCurrentNAV is cash plus every position at its latest mark
 func (pcm *PositionCapsManager) getCurrentNAV() float64 {
-	// This should integrate with NAVTracker from Session 13
-	// For now, use a simple calculation
-	positions := pcm.portfolioMgr.GetAllPositions()
-	totalValue := 0.0
-	
-	for symbol, position := range positions {
-		if quote, err := pcm.quotesAdapter.GetQuote(context.Background(), symbol); err == nil && quote != nil {
-			var price float64
-			if quote.Bid > 0 && quote.Ask > 0 {
-				price = (quote.Bid + quote.Ask) / 2
-			} else if quote.Last > 0 {
-				price = quote.Last
-			}
-			if price > 0 {
-				totalValue += float64(position.Quantity) * price
-			}
-		}
-	}
-	
-	// For now, return just the position value
-	// In a real implementation, this would integrate with NAVTracker from Session 13
-	return totalValue
+	return pcm.portfolioMgr.GetNAV()
 }
 
 func (pcm *PositionCapsManager) resetDailyTradesIfNeeded() {
-	now := time.Now()
+	now := pcm.clock.Now()
 	
 	// Check if we've passed RTH open
 	loc, err := time.LoadLocation("America/New_York")
@@ -403,7 +431,7 @@ func (pcm *PositionCapsManager) persistConfig() error {
 	
 	data := map[string]interface{}{
 		"version":       pcm.configVersion,
-		"updated_at":    time.Now(),
+		"updated_at":    pcm.clock.Now(),
 		"symbol_caps":   pcm.symbolCaps,
 		"daily_trades":  pcm.dailyTrades,
 		"last_reset":    pcm.lastResetTime,
@@ -438,13 +466,32 @@ func (pcm *PositionCapsManager) recordMetric(name string, value float64, labels
 
 // Helper functions
 
+// isRiskReducing reports whether an intent only shrinks a position. The
+// circuit breaker lets these through even when halted.
 func isRiskReducing(intent string) bool {
-	return intent == "REDUCE" || intent == "EXIT" || intent == "STOP" || intent == "HOLD"
+	switch intent {
+	case "REDUCE", "EXIT", "COVER", "CLOSE":
+		return true
+	}
+	return false
+}
+
+// isRiskIncreasing reports whether an intent opens or adds to a position,
+// long or short. Only these are capped, cooled down or softened to HOLD.
+func isRiskIncreasing(intent string) bool {
+	switch intent {
+	case "BUY_1X", "BUY_5X", "SELL_SHORT":
+		return true
+	}
+	return false
 }
 
-func roundQuantity(quantity int) int {
-	// Simple rounding - could be enhanced based on asset type
-	return quantity
+// roundQuantity rounds a share quantity down to a whole number of lots
+func roundQuantity(quantity, lotSize int) int {
+	if lotSize <= 1 {
+		return quantity
+	}
+	return (quantity / lotSize) * lotSize
 }
 
 // CapsGate implements the RiskGate interface for position caps
@@ -3,9 +3,12 @@ package risk
 import (
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/Rajchodisetti/trading-app/internal/clock"
 	"github.com/Rajchodisetti/trading-app/internal/observ"
 )
 
@@ -52,6 +55,7 @@ type CircuitBreaker struct {
 	// Current state
 	state          CircuitBreakerState
 	stateEnteredAt time.Time
+	clock          clock.Clock
 	sizeMultiplier float64
 	coolingOffUntil time.Time
 	
@@ -122,6 +126,7 @@ func NewCircuitBreaker(eventLogPath string) *CircuitBreaker {
 	cb := &CircuitBreaker{
 		state:          StateNormal,
 		stateEnteredAt: time.Now(),
+		clock:          clock.Real{},
 		sizeMultiplier: 1.0,
 		thresholds:     getDefaultThresholds(),
 		eventLog:       eventLogPath,
@@ -140,6 +145,11 @@ func NewCircuitBreaker(eventLogPath string) *CircuitBreaker {
 	
 	cb.stateStartTime[StateNormal] = time.Now()
 	
+	// Events are appended asynchronously, so the log's directory is made here
+	if err := os.MkdirAll(filepath.Dir(eventLogPath), 0755); err != nil {
+		observ.IncCounter("circuit_breaker_persist_errors_total", map[string]string{"event_type": "init"})
+	}
+	
 	// Load persisted events
 	if err := cb.loadEvents(); err != nil {
 		observ.IncCounter("circuit_breaker_load_errors_total", nil)
@@ -153,6 +163,19 @@ func NewCircuitBreaker(eventLogPath string) *CircuitBreaker {
 	return cb
 }
 
+// SetClock sets the time source for state durations and cooling-off. A state
+// stamped on the wall clock later than the new clock's now is restamped, so a
+// simulated clock set in the past never sees negative time in state.
+func (cb *CircuitBreaker) SetClock(c clock.Clock) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.clock = clock.Or(c)
+	if now := cb.clock.Now(); cb.stateEnteredAt.After(now) {
+		cb.stateEnteredAt = now
+		cb.stateStartTime[cb.state] = now
+	}
+}
+
 // UpdateDrawdown processes new drawdown data and updates circuit breaker state
 func (cb *CircuitBreaker) UpdateDrawdown(dailyDD, weeklyDD float64, navTracker *NAVTracker, correlationID string) {
 	cb.mu.Lock()
@@ -175,9 +198,9 @@ func (cb *CircuitBreaker) UpdateDrawdown(dailyDD, weeklyDD float64, navTracker *
 	}
 	
 	// Check for cooling off expiration
-	if cb.state == StateCoolingOff && time.Now().After(cb.coolingOffUntil) {
+	if cb.state == StateCoolingOff && cb.clock.Now().After(cb.coolingOffUntil) {
 		cb.addEvent(EventCoolingOffExpired, map[string]interface{}{
-			"cooling_off_duration_minutes": time.Since(cb.stateEnteredAt).Minutes(),
+			"cooling_off_duration_minutes": cb.clock.Now().Sub(cb.stateEnteredAt).Minutes(),
 		}, correlationID, "", "cooling_off_period_completed")
 		cb.setState(StateNormal, "cooling_off_expired", correlationID)
 	}
@@ -215,7 +238,7 @@ func (cb *CircuitBreaker) UpdateDrawdown(dailyDD, weeklyDD float64, navTracker *
 		
 		// If transitioning to halted, start cooling off timer
 		if newState == StateHalted {
-			cb.coolingOffUntil = time.Now().Add(cb.recoveryRequirements.CooldownPeriod)
+			cb.coolingOffUntil = cb.clock.Now().Add(cb.recoveryRequirements.CooldownPeriod)
 		}
 	}
 	
@@ -229,18 +252,18 @@ func (cb *CircuitBreaker) setState(newState CircuitBreakerState, reason, correla
 	previousTime := cb.stateEnteredAt
 	
 	cb.state = newState
-	cb.stateEnteredAt = time.Now()
+	cb.stateEnteredAt = cb.clock.Now()
 	cb.sizeMultiplier = cb.getSizeMultiplierForState(newState)
 	
 	// Update state timing metrics
 	if previousState != newState {
-		duration := time.Since(previousTime)
+		duration := cb.clock.Now().Sub(previousTime)
 		observ.Observe("circuit_breaker_state_duration_seconds", 
 			duration.Seconds(), 
 			map[string]string{"state": string(previousState)})
 	}
 	
-	cb.stateStartTime[newState] = time.Now()
+	cb.stateStartTime[newState] = cb.clock.Now()
 	
 	// Increment trigger counter
 	cb.triggerCounts[reason]++
@@ -250,7 +273,7 @@ func (cb *CircuitBreaker) setState(newState CircuitBreakerState, reason, correla
 		"previous_state":    string(previousState),
 		"new_state":         string(newState),
 		"size_multiplier":   cb.sizeMultiplier,
-		"state_duration_ms": time.Since(previousTime).Milliseconds(),
+		"state_duration_ms": cb.clock.Now().Sub(previousTime).Milliseconds(),
 		"trigger_count":     cb.triggerCounts[reason],
 	}, correlationID, "", reason)
 	
@@ -268,7 +291,7 @@ func (cb *CircuitBreaker) ManualHalt(userID, reason string) error {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 	
-	correlationID := fmt.Sprintf("manual_halt_%d", time.Now().UnixNano())
+	correlationID := fmt.Sprintf("manual_halt_%d", cb.clock.Now().UnixNano())
 	
 	cb.manualHalt = true
 	cb.overrideUser = userID
@@ -303,7 +326,7 @@ func (cb *CircuitBreaker) InitiateRecovery(userID, reason string, approvals []st
 		}
 	}
 	
-	correlationID := fmt.Sprintf("recovery_%d", time.Now().UnixNano())
+	correlationID := fmt.Sprintf("recovery_%d", cb.clock.Now().UnixNano())
 	
 	cb.manualHalt = false
 	cb.manualRecovery = true
@@ -319,7 +342,7 @@ func (cb *CircuitBreaker) InitiateRecovery(userID, reason string, approvals []st
 	
 	// Start with cooling off period
 	cb.setState(StateCoolingOff, "manual_recovery", correlationID)
-	cb.coolingOffUntil = time.Now().Add(cb.recoveryRequirements.CooldownPeriod)
+	cb.coolingOffUntil = cb.clock.Now().Add(cb.recoveryRequirements.CooldownPeriod)
 	
 	return cb.persistEvent(cb.events[len(cb.events)-1])
 }
@@ -346,7 +369,7 @@ func (cb *CircuitBreaker) CanTrade(intent string) (bool, string) {
 		
 	case StateHalted, StateCoolingOff:
 		// Only allow risk-reducing trades
-		if intent == "REDUCE" || intent == "CLOSE" {
+		if isRiskReducing(intent) {
 			return true, ""
 		}
 		return false, fmt.Sprintf("circuit_breaker_%s", cb.state)
@@ -382,7 +405,7 @@ func (cb *CircuitBreaker) GetStatus() map[string]interface{} {
 		"override_user":        cb.overrideUser,
 		"override_reason":      cb.overrideReason,
 		"daily_halt_count":     cb.getDailyHaltCount(),
-		"time_in_current_state": time.Since(cb.stateEnteredAt),
+		"time_in_current_state": cb.clock.Now().Sub(cb.stateEnteredAt),
 		"thresholds":           cb.thresholds,
 		"trigger_counts":       cb.triggerCounts,
 	}
@@ -526,7 +549,7 @@ func (cb *CircuitBreaker) getThresholdForState(state CircuitBreakerState, thresh
 
 func (cb *CircuitBreaker) getDailyHaltCount() int {
 	count := 0
-	today := time.Now().UTC().Format("2006-01-02")
+	today := cb.clock.Now().UTC().Format("2006-01-02")
 	
 	for _, event := range cb.events {
 		if event.Type == EventStateChanged &&
@@ -589,7 +612,7 @@ func (cb *CircuitBreaker) updateMetrics(dailyDD, weeklyDD float64) {
 	observ.SetGauge("circuit_breaker_size_multiplier", cb.sizeMultiplier, nil)
 	observ.SetGauge("drawdown_daily_pct", dailyDD, nil)
 	observ.SetGauge("drawdown_weekly_pct", weeklyDD, nil)
-	observ.SetGauge("circuit_breaker_time_in_state_seconds", time.Since(cb.stateEnteredAt).Seconds(), nil)
+	observ.SetGauge("circuit_breaker_time_in_state_seconds", cb.clock.Now().Sub(cb.stateEnteredAt).Seconds(), nil)
 	observ.SetGauge("circuit_breaker_daily_halt_count", float64(cb.getDailyHaltCount()), nil)
 	
 	if cb.state == StateCoolingOff && !cb.coolingOffUntil.IsZero() {
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Rajchodisetti/trading-app/internal/clock"
 	"github.com/Rajchodisetti/trading-app/internal/observ"
 )
 
@@ -18,6 +19,7 @@ type CooldownManager struct {
 	globalLastTrade    time.Time            // global cooldown across all symbols
 	configVersion      int64                // for race-safe config updates
 	metricsEnabled     bool
+	clock              clock.Clock
 }
 
 // TradeInfo stores information about the last trade for cooldown calculations
@@ -60,9 +62,17 @@ func NewCooldownManager(config CooldownConfig) *CooldownManager {
 		lastTradeTimes: make(map[string]TradeInfo),
 		configVersion:  1,
 		metricsEnabled: true,
+		clock:          clock.Real{},
 	}
 }
 
+// SetClock sets the time source for cooldown status and persisted state
+func (cm *CooldownManager) SetClock(c clock.Clock) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.clock = clock.Or(c)
+}
+
 // CanTrade checks if a trade is allowed given cooldown restrictions
 // Returns (canTrade, cooldownInfo, error)
 // Soft semantics: BUY→HOLD if violation, always allow REDUCE/EXIT
@@ -72,8 +82,8 @@ func (cm *CooldownManager) CanTrade(symbol, intent string, timestamp time.Time)
 	
 	side := intentToSide(intent)
 	
-	// Always allow risk-reducing trades regardless of cooldown
-	if isRiskReducing(intent) {
+	// Always allow trades that add no exposure, regardless of cooldown
+	if !isRiskIncreasing(intent) {
 		return true, &CooldownInfo{
 			Symbol:               symbol,
 			OppositeTradeAllowed: true,
@@ -197,7 +207,7 @@ func (cm *CooldownManager) GetCooldownInfo(symbol string) *CooldownInfo {
 		}
 	}
 	
-	now := time.Now()
+	now := cm.clock.Now()
 	timeSinceLastTrade := now.Sub(lastTrade.Timestamp)
 	cooldownPeriod := cm.getCooldownPeriod(symbol, lastTrade.Intent, lastTrade.Side, lastTrade)
 	
@@ -292,7 +302,7 @@ func (cm *CooldownManager) persistState() error {
 	
 	data := map[string]interface{}{
 		"version":          cm.configVersion,
-		"updated_at":       time.Now(),
+		"updated_at":       cm.clock.Now(),
 		"last_trade_times": cm.lastTradeTimes,
 		"global_last_trade": cm.globalLastTrade,
 	}
@@ -347,9 +357,9 @@ func intentToSide(intent string) string {
 	switch intent {
 	case "BUY_1X", "BUY_5X":
 		return "BUY"
-	case "SELL", "SHORT":
+	case "SELL", "SHORT", "SELL_SHORT":
 		return "SELL"
-	case "REDUCE", "EXIT", "STOP":
+	case "REDUCE", "EXIT", "STOP", "COVER":
 		return "REDUCE"
 	default:
 		return "UNKNOWN"
@@ -0,0 +1,128 @@
+package risk
+
+import (
+	"context"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/adapters"
+	"github.com/Rajchodisetti/trading-app/internal/clock"
+	"github.com/Rajchodisetti/trading-app/internal/observ"
+	"github.com/Rajchodisetti/trading-app/internal/portfolio"
+)
+
+// CorporateActionProcessor applies the corporate actions the symbol
+// normalizer knows about to held positions once they take effect: splits
+// rescale quantities and prices (and with them the stop-loss entry VWAP),
+// renames move positions, caps and stop cooldowns, and delistings and
+// acquisitions flag positions for an operator.
+type CorporateActionProcessor struct {
+	normalizer   *adapters.SymbolNormalizer
+	portfolioMgr *portfolio.Manager
+	stopLoss     *StopLossManager     // optional
+	caps         *PositionCapsManager // optional
+	clock        clock.Clock
+}
+
+// NewCorporateActionProcessor creates a processor; stopLoss and caps may be nil
+func NewCorporateActionProcessor(normalizer *adapters.SymbolNormalizer, portfolioMgr *portfolio.Manager, stopLoss *StopLossManager, caps *PositionCapsManager) *CorporateActionProcessor {
+	return &CorporateActionProcessor{
+		normalizer:   normalizer,
+		portfolioMgr: portfolioMgr,
+		stopLoss:     stopLoss,
+		caps:         caps,
+		clock:        clock.Real{},
+	}
+}
+
+// SetClock sets the time source that decides which actions are in effect
+func (p *CorporateActionProcessor) SetClock(c clock.Clock) {
+	p.clock = clock.Or(c)
+}
+
+// Start applies due actions every interval until ctx is done
+func (p *CorporateActionProcessor) Start(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			p.Process()
+		}
+	}
+}
+
+// Process applies every action in effect that the portfolio has not applied
+// yet, oldest first, and returns how many it applied
+func (p *CorporateActionProcessor) Process() int {
+	applied := 0
+	for _, action := range p.normalizer.CorporateActionsAsOf(p.clock.Now()) {
+		a := portfolio.CorporateAction{
+			ID:          CorporateActionID(action),
+			Type:        string(action.ActionType),
+			Symbol:      action.Symbol,
+			NewSymbol:   action.NewSymbol,
+			EffectiveAt: action.EffectiveDate,
+		}
+		if action.Ratio != nil {
+			a.From, a.To = action.Ratio.From, action.Ratio.To
+		}
+
+		before, held := p.portfolioMgr.GetPosition(a.Symbol)
+		ok, err := p.portfolioMgr.ApplyCorporateAction(a)
+		if err != nil {
+			observ.IncCounter("corporate_action_errors_total", map[string]string{"type": a.Type})
+			observ.Log("corporate_action_error", map[string]any{
+				"id":    a.ID,
+				"error": err.Error(),
+			})
+			continue
+		}
+		if !ok {
+			continue
+		}
+		applied++
+
+		if a.Type == portfolio.ActionRename {
+			if p.stopLoss != nil {
+				p.stopLoss.RenameSymbol(a.Symbol, a.NewSymbol)
+			}
+			if p.caps != nil {
+				if err := p.caps.RenameSymbol(a.Symbol, a.NewSymbol); err != nil {
+					observ.IncCounter("corporate_action_errors_total", map[string]string{"type": a.Type})
+				}
+			}
+		}
+
+		fields := map[string]any{
+			"id":           a.ID,
+			"type":         a.Type,
+			"symbol":       a.Symbol,
+			"effective_at": a.EffectiveAt.UTC().Format(time.RFC3339),
+			"held":         held && before.Quantity != 0,
+			"qty_before":   before.Quantity,
+			"entry_before": before.EntryVWAP,
+		}
+		symbol := a.Symbol
+		if a.NewSymbol != "" {
+			fields["new_symbol"] = a.NewSymbol
+			symbol = a.NewSymbol
+		}
+		if after, ok := p.portfolioMgr.GetPosition(symbol); ok {
+			fields["qty_after"] = after.Quantity
+			fields["entry_after"] = after.EntryVWAP
+			if after.Flag != "" {
+				fields["flag"] = after.Flag
+			}
+		}
+		observ.Log("corporate_action_applied", fields)
+	}
+	return applied
+}
+
+// CorporateActionID identifies an action so it is applied exactly once
+func CorporateActionID(a adapters.CorporateAction) string {
+	return a.Symbol + ":" + string(a.ActionType) + ":" + a.EffectiveDate.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,83 @@
+package risk
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/adapters"
+	"github.com/Rajchodisetti/trading-app/internal/clock"
+	"github.com/Rajchodisetti/trading-app/internal/portfolio"
+)
+
+func TestCorporateActionProcessor(t *testing.T) {
+	t0 := time.Date(2025, 8, 25, 13, 0, 0, 0, time.UTC)
+	clk := clock.NewSim(t0)
+	pm := portfolio.NewManager(filepath.Join(t.TempDir(), "portfolio.json"), 100000)
+	pm.SetClock(clk)
+	pm.ApplyFill("o1", "NVDA", 10, 400, portfolio.Costs{}, t0)
+	pm.ApplyFill("o2", "FB", 5, 300, portfolio.Costs{}, t0)
+
+	caps := NewPositionCapsManager(pm, fixedQuotes{}, CapsConfig{SymbolSpecificCaps: map[string]float64{"FB": 5000}})
+	caps.SetClock(clk)
+	stops := NewStopLossManager()
+
+	effective := t0.Add(30 * time.Minute)
+	normalizer := adapters.NewSymbolNormalizer()
+	normalizer.AddCorporateAction(&adapters.CorporateAction{Symbol: "NVDA", ActionType: adapters.ActionSplit, EffectiveDate: effective, Ratio: &adapters.SplitRatio{From: 1, To: 4}})
+	normalizer.AddCorporateAction(&adapters.CorporateAction{Symbol: "FB", NewSymbol: "META", ActionType: adapters.ActionRename, EffectiveDate: effective})
+
+	p := NewCorporateActionProcessor(normalizer, pm, stops, caps)
+	p.SetClock(clk)
+	if n := p.Process(); n != 0 {
+		t.Fatalf("applied %d actions before they took effect", n)
+	}
+
+	clk.Set(effective)
+	if n := p.Process(); n != 2 {
+		t.Fatalf("want both actions applied, got %d", n)
+	}
+	if n := p.Process(); n != 0 {
+		t.Fatalf("reapplied %d actions", n)
+	}
+
+	// The first post-split print is no loss against the adjusted entry
+	entry, _ := pm.GetStopEntry("NVDA", portfolio.StopEntryVWAP)
+	cfg := StopLossConfig{Enabled: true, DefaultStopLossPct: 5, CooldownHours: 1}
+	if fired, _ := stops.CheckStopLoss("NVDA", 101, entry, false, cfg, false, effective); fired {
+		t.Fatalf("stop-loss fired on the split: entry %v", entry)
+	}
+
+	if _, ok := pm.GetPosition("META"); !ok {
+		t.Fatal("want the FB position moved to META")
+	}
+	if cap := caps.GetSymbolCap("META"); cap.MaxPositionUSD != 5000 {
+		t.Fatalf("want the FB cap carried to META, got %+v", cap)
+	}
+}
+
+func TestStopLossManager_RenameWhileChecking(t *testing.T) {
+	stops := NewStopLossManager()
+	cfg := StopLossConfig{Enabled: true, DefaultStopLossPct: 5, CooldownHours: 1}
+	now := time.Date(2025, 8, 25, 13, 0, 0, 0, time.UTC)
+	stops.CheckStopLoss("FB", 90, 100, false, cfg, false, now)
+
+	// The processor renames from its own goroutine while the pipeline checks stops
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			stops.RenameSymbol("FB", "META")
+			stops.RenameSymbol("META", "FB")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		stops.CheckStopLoss("FB", 90, 100, false, cfg, false, now)
+		stops.IsInCooldown("META", now)
+	}
+	<-done
+
+	if !stops.IsInCooldown("FB", now) {
+		t.Fatal("want the cooldown back on FB after renaming it away and back")
+	}
+}
@@ -58,8 +58,8 @@ func (dm *DrawdownManager) CheckDrawdownGates(intent string, config DrawdownConf
 		return false, ""
 	}
 	
-	// Only affect BUY intents, allow REDUCE
-	if intent != "BUY_1X" && intent != "BUY_5X" {
+	// Only affect intents that add exposure, allow REDUCE and COVER
+	if intent != "BUY_1X" && intent != "BUY_5X" && intent != "SELL_SHORT" {
 		return false, ""
 	}
 	
@@ -46,11 +46,6 @@ func (cb *CircuitBreaker) addEvent(eventType string, data map[string]interface{}
 
 // persistEvent appends an event to the append-only event log
 func (cb *CircuitBreaker) persistEvent(event CircuitBreakerEvent) error {
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll("data", 0755); err != nil {
-		return fmt.Errorf("failed to create data directory: %w", err)
-	}
-	
 	// Open file in append mode
 	file, err := os.OpenFile(cb.eventLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
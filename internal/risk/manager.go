@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Rajchodisetti/trading-app/internal/clock"
 	"github.com/Rajchodisetti/trading-app/internal/adapters"
 	"github.com/Rajchodisetti/trading-app/internal/observ"
 	"github.com/Rajchodisetti/trading-app/internal/portfolio"
@@ -35,6 +36,8 @@ type RiskManager struct {
 	
 	// Risk gates
 	riskGates        []RiskGate
+	softGates        map[string]bool // gates that convert BUY→HOLD instead of blocking
+	clock            clock.Clock
 	
 	// Configuration
 	config RiskManagerConfig
@@ -44,6 +47,7 @@ type RiskManager struct {
 	ctx            context.Context
 	cancel         context.CancelFunc
 	lastDecisionID string
+	decisionSeq    int64
 }
 
 // RiskManagerConfig configures the risk management system
@@ -59,6 +63,7 @@ type RiskManagerConfig struct {
 	
 	// Observability settings
 	EventLogPath      string `yaml:"event_log_path"`
+	BreakerLogPath    string `yaml:"breaker_log_path"` // circuit breaker events; default data/circuit_breaker_events.jsonl
 	MetricsEnabled    bool   `yaml:"metrics_enabled"`
 	AlertingEnabled   bool   `yaml:"alerting_enabled"`
 	
@@ -130,7 +135,7 @@ func (g *CircuitBreakerGate) Evaluate(ctx DecisionContext, riskData RiskData) (b
 		
 	case StateHalted, StateCoolingOff:
 		// Only allow risk-reducing orders
-		if ctx.Intent == "REDUCE" || ctx.Intent == "CLOSE" {
+		if isRiskReducing(ctx.Intent) {
 			return true, "", nil
 		}
 		return false, fmt.Sprintf("circuit_breaker_%s", riskData.CircuitState), nil
@@ -198,7 +203,10 @@ func NewRiskManager(
 	
 	// Initialize components
 	navTracker := NewNAVTracker(portfolioMgr, quotesAdapter, config.NAVTracker)
-	circuitBreaker := NewCircuitBreaker("data/circuit_breaker_events.jsonl")
+	if config.BreakerLogPath == "" {
+		config.BreakerLogPath = "data/circuit_breaker_events.jsonl"
+	}
+	circuitBreaker := NewCircuitBreaker(config.BreakerLogPath)
 	volatilityCalc := NewVolatilityCalculator(config.Volatility)
 	observabilityMgr := NewRiskObservabilityManager(config.EventLogPath)
 	
@@ -207,6 +215,8 @@ func NewRiskManager(
 		circuitBreaker:   circuitBreaker,
 		volatilityCalc:   volatilityCalc,
 		observabilityMgr: observabilityMgr,
+		softGates:        map[string]bool{"caps": true, "cooldown": true},
+		clock:            clock.Real{},
 		config:          config,
 		ctx:             ctx,
 		cancel:          cancel,
@@ -222,6 +232,11 @@ func (rm *RiskManager) Start() error {
 		return fmt.Errorf("risk manager already running")
 	}
 	
+	// Take the first NAV snapshot before any decision is evaluated
+	if err := rm.navTracker.Init(rm.ctx); err != nil {
+		return fmt.Errorf("initialize nav tracker: %w", err)
+	}
+	
 	rm.running = true
 	
 	// Start NAV tracking
@@ -242,6 +257,20 @@ func (rm *RiskManager) Start() error {
 	return nil
 }
 
+// Update takes a NAV snapshot and moves the circuit breaker to the
+// drawdown it implies, on the caller's goroutine. Replay calls it after every
+// event instead of Start, whose loops run on wall-clock tickers.
+func (rm *RiskManager) Update(ctx context.Context) error {
+	if err := rm.navTracker.Init(ctx); err != nil {
+		return fmt.Errorf("initialize nav tracker: %w", err)
+	}
+	if err := rm.navTracker.updateNAV(ctx); err != nil {
+		return fmt.Errorf("update nav: %w", err)
+	}
+	rm.updateRiskState()
+	return nil
+}
+
 // Stop shuts down the risk management system
 func (rm *RiskManager) Stop() error {
 	rm.mu.Lock()
@@ -259,12 +288,64 @@ func (rm *RiskManager) Stop() error {
 	return nil
 }
 
+// SetPositionControls adds the caps and cooldown soft gates to decision
+// evaluation; either may be nil
+func (rm *RiskManager) SetPositionControls(capsManager *PositionCapsManager, cooldownManager *CooldownManager) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.capsManager = capsManager
+	rm.cooldownManager = cooldownManager
+}
+
+// SetClock sets the time source for the risk manager, its NAV tracker and
+// circuit breaker. Call it before Start.
+func (rm *RiskManager) SetClock(c clock.Clock) {
+	c = clock.Or(c)
+	rm.mu.Lock()
+	rm.clock = c
+	rm.mu.Unlock()
+	rm.navTracker.SetClock(c)
+	rm.circuitBreaker.SetClock(c)
+}
+
+// SetMarker has the NAV tracker price positions with m. Call it before Start.
+func (rm *RiskManager) SetMarker(m *MarkToMarket) {
+	rm.navTracker.SetMarker(m)
+}
+
+// GateNames lists the gates EvaluateDecision runs, in order; caps and
+// cooldown only run once SetPositionControls provides their managers
+func GateNames() []string {
+	return []string{"circuit_breaker", "data_quality", "volatility", "caps", "cooldown"}
+}
+
+// SetGateClasses chooses which gates convert BUY→HOLD instead of blocking.
+// Gates listed as hard always block, even if also listed as soft. The
+// decision pipeline passes the classification it uses for its own gates
+// (decision.GateConfig), so a gate name means the same thing in both.
+func (rm *RiskManager) SetGateClasses(hard, soft []string) {
+	classes := make(map[string]bool, len(soft))
+	for _, name := range soft {
+		classes[name] = true
+	}
+	for _, name := range hard {
+		delete(classes, name)
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.softGates = classes
+}
+
 // EvaluateDecision evaluates a trading decision against all risk gates
 func (rm *RiskManager) EvaluateDecision(ctx DecisionContext) DecisionResult {
 	start := time.Now()
-	decisionID := fmt.Sprintf("decision_%d", start.UnixNano())
 	
+	// IDs follow the risk manager's clock, so replayed decisions get the same IDs
+	rm.mu.Lock()
+	rm.decisionSeq++
+	decisionID := fmt.Sprintf("decision_%d_%d", rm.clock.Now().UnixNano(), rm.decisionSeq)
 	rm.lastDecisionID = decisionID
+	rm.mu.Unlock()
 	
 	// Get current risk data
 	riskData := rm.getCurrentRiskData()
@@ -293,11 +374,15 @@ func (rm *RiskManager) EvaluateDecision(ctx DecisionContext) DecisionResult {
 	}
 	
 	// Add caps and cooldown gates if available (Session 14)
-	if rm.capsManager != nil {
-		gates = append(gates, NewCapsGate(rm.capsManager))
+	rm.mu.RLock()
+	capsManager, cooldownManager := rm.capsManager, rm.cooldownManager
+	softGates := rm.softGates
+	rm.mu.RUnlock()
+	if capsManager != nil {
+		gates = append(gates, NewCapsGate(capsManager))
 	}
-	if rm.cooldownManager != nil {
-		gates = append(gates, NewCooldownGate(rm.cooldownManager))
+	if cooldownManager != nil {
+		gates = append(gates, NewCooldownGate(cooldownManager))
 	}
 	
 	// Evaluate gates in priority order
@@ -321,7 +406,7 @@ func (rm *RiskManager) EvaluateDecision(ctx DecisionContext) DecisionResult {
 		
 		if !approved {
 			// Check if this is a soft gate that should convert BUY→HOLD instead of blocking
-			if rm.isSoftGate(gate.Name()) && rm.isBuyIntent(ctx.Intent) {
+			if softGates[gate.Name()] && isRiskIncreasing(ctx.Intent) {
 				// Convert BUY to HOLD for caps and cooldown violations
 				result.Intent = "HOLD"
 				result.Warnings = append(result.Warnings, reason)
@@ -475,7 +560,7 @@ func (rm *RiskManager) getCurrentRiskData() RiskData {
 		VolatilityRegime: volatilityRegime,
 		DataQuality:      dataQuality,
 		ComponentHealth:  componentHealth,
-		QuoteStaleness:   time.Since(lastUpdate),
+		QuoteStaleness:   rm.clock.Now().Sub(lastUpdate),
 		LastUpdate:       lastUpdate,
 	}
 }
@@ -566,7 +651,7 @@ func (rm *RiskManager) updateRiskState() {
 	// Update volatility calculations
 	nav, _, _ := rm.navTracker.GetCurrentNAV()
 	if prevData, exists := rm.getPreviousRiskData(); exists {
-		rm.volatilityCalc.UpdateNAVReturn(prevData.CurrentNAV, nav, time.Now())
+		rm.volatilityCalc.UpdateNAVReturn(prevData.CurrentNAV, nav, rm.clock.Now())
 	}
 	
 	// Track observability metrics
@@ -675,18 +760,3 @@ func parseVolatilityMultiplier(reason string) float64 {
 	}
 	return 1.0
 }
-
-// Helper methods for soft gate logic
-
-func (rm *RiskManager) isSoftGate(gateName string) bool {
-	// Caps and cooldown gates are soft - convert BUY→HOLD instead of blocking
-	return gateName == "caps" || gateName == "cooldown"
-}
-
-func (rm *RiskManager) isBuyIntent(intent string) bool {
-	return intent == "BUY_1X" || intent == "BUY_5X"
-}
-
-func (rm *RiskManager) isRiskReducing(intent string) bool {
-	return intent == "REDUCE" || intent == "EXIT" || intent == "STOP" || intent == "HOLD"
-}
\ No newline at end of file
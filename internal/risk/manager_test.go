@@ -107,6 +107,19 @@ func TestCircuitBreakerGate(t *testing.T) {
 			intent:       "REDUCE",
 			shouldPass:   true,
 		},
+		{
+			name:         "halted_state_cover",
+			circuitState: StateHalted,
+			intent:       "COVER",
+			shouldPass:   true,
+		},
+		{
+			name:         "halted_state_sell_short",
+			circuitState: StateHalted,
+			intent:       "SELL_SHORT",
+			shouldPass:   false,
+			expectedReason: "circuit_breaker_halted",
+		},
 		{
 			name:         "emergency_state_any",
 			circuitState: StateEmergency,
@@ -138,6 +151,36 @@ func TestCircuitBreakerGate(t *testing.T) {
 	}
 }
 
+func TestCircuitBreaker_ShortUnderTrippedBreaker(t *testing.T) {
+	cb := NewCircuitBreaker(t.TempDir() + "/circuit_breaker_events.jsonl")
+	gate := &CircuitBreakerGate{}
+
+	for _, state := range []CircuitBreakerState{StateHalted, StateCoolingOff} {
+		cb.mu.Lock()
+		cb.setState(state, "test", "short-test")
+		cb.mu.Unlock()
+
+		// The gate and CanTrade must agree on what a short may do
+		for intent, want := range map[string]bool{
+			"SELL_SHORT": false,
+			"BUY_1X":     false,
+			"COVER":      true,
+			"EXIT":       true,
+			"REDUCE":     true,
+			"CLOSE":      true,
+		} {
+			canTrade, _ := cb.CanTrade(intent)
+			passed, _, err := gate.Evaluate(DecisionContext{Intent: intent}, RiskData{CircuitState: state})
+			if err != nil {
+				t.Fatalf("%s %s: %v", state, intent, err)
+			}
+			if canTrade != want || passed != want {
+				t.Errorf("%s %s: CanTrade=%t gate=%t, want %t", state, intent, canTrade, passed, want)
+			}
+		}
+	}
+}
+
 func TestDataQualityGate(t *testing.T) {
 	gate := &DataQualityGate{
 		MinQualityScore: 0.8,
@@ -0,0 +1,212 @@
+package risk
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/adapters"
+	"github.com/Rajchodisetti/trading-app/internal/clock"
+	"github.com/Rajchodisetti/trading-app/internal/observ"
+	"github.com/Rajchodisetti/trading-app/internal/portfolio"
+)
+
+// Reference prices a position can be marked at
+const (
+	MarkMid  = "mid"
+	MarkLast = "last"
+)
+
+// MarkConfig sets how open positions are priced
+type MarkConfig struct {
+	IntervalSeconds int     // how often Start marks the book
+	PriceSource     string  // MarkMid (default) or MarkLast
+	StaleAfterMs    int     // older quotes keep the previous mark
+	MaxSpreadBps    float64 // wider books mark at last instead of mid; 0 disables
+}
+
+// Mark is the reference price one position was marked at
+type Mark struct {
+	Price  float64       `json:"price"`
+	Source string        `json:"source"` // MarkMid or MarkLast
+	Age    time.Duration `json:"age"`
+}
+
+// MarkResult is one pass over the book
+type MarkResult struct {
+	At            time.Time
+	Marks         map[string]Mark // symbols marked this pass
+	UnrealizedPnL float64         // across every open position, at the latest marks
+	Quality       NAVDataQuality
+}
+
+// MarkToMarket prices open positions from quotes and writes the marks to the
+// portfolio, so unrealized P&L, market value, exposure and NAV follow the market
+type MarkToMarket struct {
+	portfolioMgr  *portfolio.Manager
+	quotesAdapter adapters.QuotesAdapter
+	config        MarkConfig
+
+	mu     sync.Mutex
+	gauged map[string]bool // symbols with per-position gauges set
+	clock  clock.Clock
+}
+
+// NewMarkToMarket creates a mark-to-market service
+func NewMarkToMarket(portfolioMgr *portfolio.Manager, quotesAdapter adapters.QuotesAdapter, config MarkConfig) *MarkToMarket {
+	if config.IntervalSeconds == 0 {
+		config.IntervalSeconds = 5
+	}
+	if config.PriceSource == "" {
+		config.PriceSource = MarkMid
+	}
+	if config.StaleAfterMs == 0 {
+		config.StaleAfterMs = 2000
+	}
+	return &MarkToMarket{
+		portfolioMgr:  portfolioMgr,
+		quotesAdapter: quotesAdapter,
+		config:        config,
+		gauged:        make(map[string]bool),
+		clock:         clock.Real{},
+	}
+}
+
+// SetClock sets the time source for mark times and quote ages
+func (m *MarkToMarket) SetClock(c clock.Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = clock.Or(c)
+}
+
+// Start marks the book every interval until ctx is done
+func (m *MarkToMarket) Start(ctx context.Context) error {
+	m.Mark(ctx)
+
+	ticker := time.NewTicker(time.Duration(m.config.IntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			m.Mark(ctx)
+		}
+	}
+}
+
+// Mark prices every open position once. Positions without a usable quote
+// keep their previous mark and are reported as missing or stale.
+func (m *MarkToMarket) Mark(ctx context.Context) MarkResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	staleAfter := time.Duration(m.config.StaleAfterMs) * time.Millisecond
+	result := MarkResult{At: now, Marks: make(map[string]Mark)}
+
+	positions := m.portfolioMgr.GetAllPositions()
+	prices := make(map[string]float64)
+	for symbol := range positions {
+		quote, err := m.quotesAdapter.GetQuote(ctx, symbol)
+		if err != nil || quote == nil {
+			result.Quality.MissingQuotes = append(result.Quality.MissingQuotes, symbol)
+			observ.IncCounter("mtm_missing_quotes_total", map[string]string{"symbol": symbol})
+			continue
+		}
+
+		age := now.Sub(quote.Timestamp)
+		if age > result.Quality.TotalStaleness {
+			result.Quality.TotalStaleness = age
+		}
+		if age > staleAfter {
+			result.Quality.StaleQuotes = append(result.Quality.StaleQuotes, symbol)
+			observ.IncCounter("mtm_stale_quotes_total", map[string]string{"symbol": symbol})
+			observ.SetGauge("quote_staleness_seconds", age.Seconds(), map[string]string{"symbol": symbol})
+			continue
+		}
+
+		mark, ok := m.referencePrice(quote)
+		if !ok {
+			result.Quality.MissingQuotes = append(result.Quality.MissingQuotes, symbol)
+			observ.IncCounter("mtm_missing_quotes_total", map[string]string{"symbol": symbol})
+			continue
+		}
+		mark.Age = age
+		if mark.Source == MarkMid {
+			result.Quality.UsingMidPrice = append(result.Quality.UsingMidPrice, symbol)
+		} else {
+			result.Quality.UsingLastTrade = append(result.Quality.UsingLastTrade, symbol)
+		}
+		result.Marks[symbol] = mark
+		prices[symbol] = mark.Price
+	}
+
+	if len(prices) > 0 {
+		if err := m.portfolioMgr.MarkPositions(prices, now); err != nil {
+			observ.IncCounter("mtm_errors_total", map[string]string{"error": "save"})
+		}
+	}
+
+	m.publish(now, &result)
+	observ.IncCounter("mtm_runs_total", nil)
+	return result
+}
+
+// referencePrice picks the price a quote marks at: the mid by default, the
+// last trade when configured, halted, or the book is too wide to trust.
+// Either falls back to the other when it is missing.
+func (m *MarkToMarket) referencePrice(q *adapters.Quote) (Mark, bool) {
+	haveMid := q.Bid > 0 && q.Ask > 0 && q.Ask >= q.Bid
+	useMid := m.config.PriceSource != MarkLast && !q.Halted
+	if useMid && m.config.MaxSpreadBps > 0 && q.SpreadBps() > m.config.MaxSpreadBps {
+		useMid = false
+	}
+
+	switch {
+	case useMid && haveMid:
+		return Mark{Price: (q.Bid + q.Ask) / 2, Source: MarkMid}, true
+	case q.Last > 0:
+		return Mark{Price: q.Last, Source: MarkLast}, true
+	case haveMid:
+		return Mark{Price: (q.Bid + q.Ask) / 2, Source: MarkMid}, true
+	}
+	return Mark{}, false
+}
+
+// publish sets the per-position gauges from the portfolio's latest marks and
+// zeroes them for positions that have since closed
+func (m *MarkToMarket) publish(now time.Time, result *MarkResult) {
+	held := make(map[string]bool)
+	for symbol, pos := range m.portfolioMgr.GetAllPositions() {
+		if pos.Quantity == 0 {
+			continue
+		}
+		held[symbol] = true
+		labels := map[string]string{"symbol": symbol}
+		result.UnrealizedPnL += pos.UnrealizedPnL
+		observ.SetGauge("position_market_value_usd", pos.CurrentNotional, labels)
+		observ.SetGauge("position_unrealized_pnl_usd", pos.UnrealizedPnL, labels)
+		observ.SetGauge("position_mark_price", pos.LastPrice, labels)
+		if markedAt, err := time.Parse(time.RFC3339, pos.MarkedAt); err == nil {
+			observ.SetGauge("position_mark_age_seconds", now.Sub(markedAt).Seconds(), labels)
+		}
+	}
+	for symbol := range m.gauged {
+		if held[symbol] {
+			continue
+		}
+		labels := map[string]string{"symbol": symbol}
+		observ.SetGauge("position_market_value_usd", 0, labels)
+		observ.SetGauge("position_unrealized_pnl_usd", 0, labels)
+		delete(m.gauged, symbol)
+	}
+	for symbol := range held {
+		m.gauged[symbol] = true
+	}
+
+	observ.SetGauge("portfolio_market_value_usd", m.portfolioMgr.GetMarketValue(), nil)
+	observ.SetGauge("portfolio_gross_exposure_usd", m.portfolioMgr.GetExposureUSD(), nil)
+	observ.SetGauge("portfolio_net_exposure_usd", m.portfolioMgr.GetNetExposureUSD(), nil)
+}
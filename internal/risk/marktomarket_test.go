@@ -0,0 +1,93 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/adapters"
+	"github.com/Rajchodisetti/trading-app/internal/clock"
+	"github.com/Rajchodisetti/trading-app/internal/portfolio"
+)
+
+// fixedQuotes serves canned quotes; symbols without one are missing
+type fixedQuotes map[string]*adapters.Quote
+
+func (f fixedQuotes) GetQuote(ctx context.Context, symbol string) (*adapters.Quote, error) {
+	if q, ok := f[symbol]; ok {
+		return q, nil
+	}
+	return nil, fmt.Errorf("no quote for %s", symbol)
+}
+
+func (f fixedQuotes) GetQuotes(ctx context.Context, symbols []string) (map[string]*adapters.Quote, error) {
+	return f, nil
+}
+
+func (f fixedQuotes) HealthCheck(ctx context.Context) error { return nil }
+
+func (f fixedQuotes) Close() error { return nil }
+
+func TestMarkToMarket_ReferencePrices(t *testing.T) {
+	t0 := time.Date(2025, 8, 25, 14, 0, 0, 0, time.UTC)
+	clk := clock.NewSim(t0)
+	pm := portfolio.NewManager(filepath.Join(t.TempDir(), "portfolio.json"), 10000)
+	pm.SetClock(clk)
+	if err := pm.Load(); err != nil {
+		t.Fatal(err)
+	}
+	fills := []struct {
+		sym   string
+		qty   int
+		price float64
+	}{{"AAPL", 10, 100}, {"TSLA", -5, 200}, {"MSFT", 2, 300}, {"BIOX", 4, 5}}
+	for i, f := range fills {
+		if err := pm.ApplyFill(fmt.Sprintf("o%d", i), f.sym, f.qty, f.price, portfolio.Costs{}, t0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := t0.Add(time.Minute)
+	clk.Set(now)
+	quotes := fixedQuotes{
+		"AAPL": {Symbol: "AAPL", Bid: 109.5, Ask: 110.5, Last: 112, Timestamp: now},
+		"TSLA": {Symbol: "TSLA", Bid: 180, Ask: 200, Last: 190, Timestamp: now}, // too wide for the mid
+		"MSFT": {Symbol: "MSFT", Bid: 350, Ask: 351, Last: 350, Timestamp: now.Add(-10 * time.Second)},
+	}
+	mtm := NewMarkToMarket(pm, quotes, MarkConfig{PriceSource: MarkMid, StaleAfterMs: 2000, MaxSpreadBps: 100})
+	mtm.SetClock(clk)
+	result := mtm.Mark(context.Background())
+
+	if m := result.Marks["AAPL"]; m.Price != 110 || m.Source != MarkMid {
+		t.Fatalf("want AAPL marked at the 110 mid, got %+v", m)
+	}
+	if m := result.Marks["TSLA"]; m.Price != 190 || m.Source != MarkLast {
+		t.Fatalf("want TSLA marked at the 190 last, got %+v", m)
+	}
+	if len(result.Quality.StaleQuotes) != 1 || len(result.Quality.MissingQuotes) != 1 {
+		t.Fatalf("want MSFT stale and BIOX missing, got %+v", result.Quality)
+	}
+
+	// Marked positions move to market; the others keep their fill price
+	aapl, _ := pm.GetPosition("AAPL")
+	tsla, _ := pm.GetPosition("TSLA")
+	msft, _ := pm.GetPosition("MSFT")
+	if aapl.UnrealizedPnL != 100 || aapl.CurrentNotional != 1100 || tsla.UnrealizedPnL != 50 || tsla.CurrentNotional != -950 {
+		t.Fatalf("unexpected marks: AAPL %+v TSLA %+v", aapl, tsla)
+	}
+	if msft.LastPrice != 300 || msft.UnrealizedPnL != 0 {
+		t.Fatalf("stale quote moved MSFT: %+v", msft)
+	}
+	if math.Abs(result.UnrealizedPnL-150) > 1e-9 {
+		t.Fatalf("want 150 unrealized, got %v", result.UnrealizedPnL)
+	}
+	if gross := pm.GetExposureUSD(); math.Abs(gross-(1100+950+600+20)) > 1e-9 {
+		t.Fatalf("want gross exposure at market, got %v", gross)
+	}
+	if nav := pm.GetNAV(); math.Abs(nav-10150) > 1e-9 {
+		t.Fatalf("want NAV 10150, got %v", nav)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Rajchodisetti/trading-app/internal/clock"
 	"github.com/Rajchodisetti/trading-app/internal/adapters"
 	"github.com/Rajchodisetti/trading-app/internal/observ"
 	"github.com/Rajchodisetti/trading-app/internal/portfolio"
@@ -18,6 +19,7 @@ import (
 type NAVTracker struct {
 	portfolioMgr  *portfolio.Manager
 	quotesAdapter adapters.QuotesAdapter
+	marker        *MarkToMarket // prices positions for each update
 	
 	// State
 	mu            sync.RWMutex
@@ -37,6 +39,11 @@ type NAVTracker struct {
 	
 	// Config
 	config NAVTrackerConfig
+	
+	initOnce sync.Once
+	initErr  error
+	
+	clock clock.Clock
 }
 
 // NAVSnapshot represents a point-in-time portfolio valuation
@@ -66,6 +73,7 @@ type NAVTrackerConfig struct {
 	QuoteStalenessThresholdMs int  `yaml:"quote_staleness_threshold_ms"` // Max age for fresh quotes
 	MaxHistoryEntries         int  `yaml:"max_history_entries"`          // Max NAV history to keep
 	UseMidPrice              bool  `yaml:"use_mid_price"`               // Use mid vs last trade price
+	MaxMidSpreadBps          float64 `yaml:"max_mid_spread_bps"`        // Mark at last when the spread is wider; 0 disables
 	PersistPath              string `yaml:"persist_path"`               // File path for persistence
 }
 
@@ -94,27 +102,79 @@ func NewNAVTracker(portfolioMgr *portfolio.Manager, quotesAdapter adapters.Quote
 		config.PersistPath = "data/nav_state.json"
 	}
 
+	priceSource := MarkLast
+	if config.UseMidPrice {
+		priceSource = MarkMid
+	}
+	marker := NewMarkToMarket(portfolioMgr, quotesAdapter, MarkConfig{
+		IntervalSeconds: config.UpdateIntervalSeconds,
+		PriceSource:     priceSource,
+		StaleAfterMs:    config.QuoteStalenessThresholdMs,
+		MaxSpreadBps:    config.MaxMidSpreadBps,
+	})
+
 	return &NAVTracker{
 		portfolioMgr:            portfolioMgr,
 		quotesAdapter:           quotesAdapter,
+		marker:                  marker,
 		navHistory:              make([]NAVSnapshot, 0, config.MaxHistoryEntries),
 		quoteStalenessThreshold: time.Duration(config.QuoteStalenessThresholdMs) * time.Millisecond,
 		persistPath:             config.PersistPath,
 		config:                  config,
+		clock:                   clock.Real{},
 	}
 }
 
-// Start begins real-time NAV tracking
-func (nt *NAVTracker) Start(ctx context.Context) error {
-	// Load persisted state
-	if err := nt.loadState(); err != nil {
-		observ.IncCounter("nav_tracker_load_errors_total", map[string]string{"error": "state_load"})
-		// Continue with fresh state - don't fail startup
+// SetClock sets the time source for snapshots, quote staleness and daily rollover
+func (nt *NAVTracker) SetClock(c clock.Clock) {
+	nt.mu.Lock()
+	defer nt.mu.Unlock()
+	nt.clock = c
+	if nt.marker != nil {
+		nt.marker.SetClock(c)
 	}
+}
+
+// SetMarker replaces the mark-to-market service the tracker prices positions
+// with, so the NAV follows the same reference-price rules as the rest of the book
+func (nt *NAVTracker) SetMarker(m *MarkToMarket) {
+	nt.mu.Lock()
+	defer nt.mu.Unlock()
+	nt.marker = m
+}
+
+// now reads the tracker's clock; trackers built without NewNAVTracker use the wall clock
+func (nt *NAVTracker) now() time.Time {
+	return clock.Or(nt.clock).Now()
+}
+
+// Init loads persisted state and takes the first NAV snapshot, so decisions
+// made right after startup see fresh data. It runs once; Start calls it too.
+func (nt *NAVTracker) Init(ctx context.Context) error {
+	nt.initOnce.Do(func() {
+		// Load persisted state
+		if err := nt.loadState(); err != nil {
+			observ.IncCounter("nav_tracker_load_errors_total", map[string]string{"error": "state_load"})
+			// Continue with fresh state - don't fail startup
+		}
+
+		// Initialize if needed
+		if err := nt.initializeDailyState(); err != nil {
+			nt.initErr = fmt.Errorf("failed to initialize daily state: %w", err)
+			return
+		}
+
+		if err := nt.updateNAV(ctx); err != nil {
+			observ.IncCounter("nav_tracker_update_errors_total", map[string]string{"error": "update"})
+		}
+	})
+	return nt.initErr
+}
 
-	// Initialize if needed
-	if err := nt.initializeDailyState(); err != nil {
-		return fmt.Errorf("failed to initialize daily state: %w", err)
+// Start begins real-time NAV tracking
+func (nt *NAVTracker) Start(ctx context.Context) error {
+	if err := nt.Init(ctx); err != nil {
+		return err
 	}
 
 	// Start update loop
@@ -145,7 +205,7 @@ func (nt *NAVTracker) updateNAV(ctx context.Context) error {
 	defer nt.mu.Unlock()
 
 	// Check if we're in a frozen state
-	if time.Now().Before(nt.frozenUntil) {
+	if nt.now().Before(nt.frozenUntil) {
 		observ.IncCounter("nav_updates_skipped_total", map[string]string{"reason": "frozen"})
 		return nil
 	}
@@ -153,100 +213,42 @@ func (nt *NAVTracker) updateNAV(ctx context.Context) error {
 	// Get current positions
 	positions := nt.portfolioMgr.GetAllPositions()
 	if len(positions) == 0 {
-		// No positions - NAV is just capital base
+		// No positions - NAV is just cash
 		nav := nt.portfolioMgr.GetNAV()
 		nt.recordNAVSnapshot(nav, 0, 0, nil, NAVDataQuality{})
 		return nil
 	}
 
-	// Get quotes for all position symbols
-	quotes := make(map[string]*adapters.Quote)
-	quoteAges := make(map[string]time.Duration)
-	var staleQuotes, missingQuotes, usingMid, usingLast []string
-
-	totalUnrealizedPnL := 0.0
-	maxStaleness := time.Duration(0)
-
-	for symbol := range positions {
-		quote, err := nt.quotesAdapter.GetQuote(ctx, symbol)
-		if err != nil {
-			missingQuotes = append(missingQuotes, symbol)
-			observ.IncCounter("nav_missing_quotes_total", map[string]string{"symbol": symbol})
-			continue
-		}
-
-		quotes[symbol] = quote
-		age := time.Since(quote.Timestamp)
-		quoteAges[symbol] = age
-		
-		if age > maxStaleness {
-			maxStaleness = age
-		}
-
-		// Check if quote is stale
-		if age > nt.quoteStalenessThreshold {
-			staleQuotes = append(staleQuotes, symbol)
-			observ.SetGauge("quote_staleness_seconds", age.Seconds(), map[string]string{"symbol": symbol})
-		}
-
-		// Determine which price to use and track methodology
-		var mtmPrice float64
-		pos := positions[symbol]
-		
-		if nt.config.UseMidPrice && quote.Bid > 0 && quote.Ask > 0 {
-			mtmPrice = (quote.Bid + quote.Ask) / 2
-			usingMid = append(usingMid, symbol)
-		} else if quote.Last > 0 {
-			mtmPrice = quote.Last
-			usingLast = append(usingLast, symbol)
-		} else {
-			// Fallback to mid if last is missing
-			if quote.Bid > 0 && quote.Ask > 0 {
-				mtmPrice = (quote.Bid + quote.Ask) / 2
-				usingMid = append(usingMid, symbol)
-			} else {
-				missingQuotes = append(missingQuotes, symbol)
-				continue
-			}
-		}
-
-		// Calculate unrealized P&L
-		unrealizedPnL := float64(pos.Quantity) * (mtmPrice - pos.AvgEntryPrice)
-		totalUnrealizedPnL += unrealizedPnL
-		
-		// Update position unrealized P&L in portfolio manager
-		nt.portfolioMgr.UpdateUnrealizedPnL(symbol, mtmPrice)
-	}
-
-	// Check data quality - freeze NAV updates if too much stale data
-	dataQuality := NAVDataQuality{
-		StaleQuotes:    staleQuotes,
-		MissingQuotes:  missingQuotes,
-		UsingMidPrice:  usingMid,
-		UsingLastTrade: usingLast,
-		TotalStaleness: maxStaleness,
+	// Mark every position; unusable quotes keep their previous mark
+	if nt.marker == nil {
+		nt.marker = NewMarkToMarket(nt.portfolioMgr, nt.quotesAdapter, MarkConfig{StaleAfterMs: nt.config.QuoteStalenessThresholdMs})
+		nt.marker.SetClock(nt.clock)
 	}
+	marks := nt.marker.Mark(ctx)
+	dataQuality := marks.Quality
+	totalUnrealizedPnL := marks.UnrealizedPnL
+	maxStaleness := dataQuality.TotalStaleness
 
 	// Freeze NAV updates if data quality is poor
-	if len(staleQuotes) > 0 && maxStaleness > nt.quoteStalenessThreshold*2 {
-		nt.frozenUntil = time.Now().Add(30 * time.Second) // Freeze for 30 seconds
+	if len(dataQuality.StaleQuotes) > 0 && maxStaleness > nt.quoteStalenessThreshold*2 {
+		nt.frozenUntil = nt.now().Add(30 * time.Second) // Freeze for 30 seconds
 		nt.frozenReason = fmt.Sprintf("excessive_staleness_%ds", int(maxStaleness.Seconds()))
 		observ.IncCounter("nav_freezes_total", map[string]string{"reason": "staleness"})
 		return nil
 	}
 
-	// Calculate full NAV
-	capitalBase := nt.portfolioMgr.GetNAV() // This includes realized P&L
+	// Calculate full NAV: cash plus positions at the marks set above
+	nav := nt.portfolioMgr.GetNAV()
 	dailyStats := nt.portfolioMgr.GetDailyStats()
 	
-	// Record NAV snapshot
+	// Record NAV snapshot at the new marks
 	positionPnL := make(map[string]float64)
-	for symbol, pos := range positions {
+	for symbol, pos := range nt.portfolioMgr.GetAllPositions() {
 		positionPnL[symbol] = pos.UnrealizedPnL
 	}
 
 	nt.recordNAVSnapshot(
-		capitalBase, 
+		nav, 
 		dailyStats.PnLToday, 
 		totalUnrealizedPnL,
 		positionPnL,
@@ -272,7 +274,7 @@ func (nt *NAVTracker) updateNAV(ctx context.Context) error {
 
 // recordNAVSnapshot adds a new NAV snapshot to history
 func (nt *NAVTracker) recordNAVSnapshot(nav, realizedPnL, unrealizedPnL float64, positions map[string]float64, quality NAVDataQuality) {
-	now := time.Now()
+	now := nt.now()
 	
 	snapshot := NAVSnapshot{
 		Timestamp:     now,
@@ -337,7 +339,7 @@ func (nt *NAVTracker) GetDrawdowns() (daily, weekly float64) {
 
 // initializeDailyState sets up start-of-day NAV for drawdown calculations
 func (nt *NAVTracker) initializeDailyState() error {
-	today := time.Now().UTC().Format("2006-01-02")
+	today := nt.now().UTC().Format("2006-01-02")
 	
 	// Check if we need to reset for new trading day
 	if nt.startOfDayNAV == 0 || nt.isNewTradingDay() {
@@ -368,7 +370,7 @@ func (nt *NAVTracker) isNewTradingDay() bool {
 	etLocation, _ := time.LoadLocation("America/New_York")
 	
 	lastET := nt.lastUpdate.In(etLocation)
-	nowET := time.Now().In(etLocation)
+	nowET := nt.now().In(etLocation)
 	
 	// Different calendar date OR crossed 4:00 PM ET
 	if lastET.Day() != nowET.Day() || lastET.Month() != nowET.Month() || lastET.Year() != nowET.Year() {
@@ -421,7 +423,7 @@ func (nt *NAVTracker) persistState() error {
 		HighWaterMark: nt.highWaterMark,
 		LastUpdate:    nt.lastUpdate,
 		LastNAV:       nt.lastNAV,
-		TradingDate:   time.Now().UTC().Format("2006-01-02"),
+		TradingDate:   nt.now().UTC().Format("2006-01-02"),
 		Positions:     nt.portfolioMgr.GetAllPositions(),
 	}
 	
@@ -460,7 +462,7 @@ func (nt *NAVTracker) loadState() error {
 	}
 	
 	// Restore state if it's from the same trading day
-	today := time.Now().UTC().Format("2006-01-02")
+	today := nt.now().UTC().Format("2006-01-02")
 	if state.TradingDate == today {
 		nt.startOfDayNAV = state.StartOfDayNAV
 		nt.highWaterMark = state.HighWaterMark
@@ -497,6 +499,6 @@ func (nt *NAVTracker) IsFrozen() (bool, string) {
 	nt.mu.RLock()
 	defer nt.mu.RUnlock()
 	
-	frozen := time.Now().Before(nt.frozenUntil)
+	frozen := nt.now().Before(nt.frozenUntil)
 	return frozen, nt.frozenReason
 }
\ No newline at end of file
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -159,6 +160,8 @@ const (
 
 // NewRiskObservabilityManager creates a new observability manager
 func NewRiskObservabilityManager(logPath string) *RiskObservabilityManager {
+	// Events are written asynchronously, so the log's directory is made here
+	_ = os.MkdirAll(filepath.Dir(logPath), 0755)
 	return &RiskObservabilityManager{
 		eventLogger: &StructuredEventLogger{
 			logPath:   logPath,
@@ -662,11 +665,6 @@ func (sel *StructuredEventLogger) LogEvent(event StructuredEvent) {
 }
 
 func (sel *StructuredEventLogger) persistEvent(event StructuredEvent) {
-	// Create directory if needed
-	if err := os.MkdirAll("data/risk_events", 0755); err != nil {
-		return
-	}
-	
 	// Open log file
 	file, err := os.OpenFile(sel.logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
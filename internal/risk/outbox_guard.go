@@ -2,9 +2,11 @@ package risk
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/Rajchodisetti/trading-app/internal/clock"
 	"github.com/Rajchodisetti/trading-app/internal/adapters"
 	"github.com/Rajchodisetti/trading-app/internal/observ"
 	"github.com/Rajchodisetti/trading-app/internal/outbox"
@@ -15,6 +17,22 @@ type OutboxGuard struct {
 	capsManager   *PositionCapsManager
 	quotesAdapter adapters.QuotesAdapter
 	observMgr     *RiskObservabilityManager
+	config        OutboxGuardConfig
+	clock         clock.Clock
+}
+
+// OutboxGuardConfig sets when a decided order is cancelled instead of sent
+type OutboxGuardConfig struct {
+	MaxPriceDriftPct        float64 `yaml:"max_price_drift_pct"`        // max mid move between decision and send
+	MaxDecisionStalenessSec float64 `yaml:"max_decision_staleness_sec"` // max age of the decision at send
+}
+
+// DefaultOutboxGuardConfig returns the guard limits used when none are configured
+func DefaultOutboxGuardConfig() OutboxGuardConfig {
+	return OutboxGuardConfig{
+		MaxPriceDriftPct:        2.0,
+		MaxDecisionStalenessSec: 10.0,
+	}
 }
 
 // OrderRequest represents an order request with cap validation context
@@ -37,45 +55,107 @@ type GuardResult struct {
 	TimeSinceDecision time.Duration            `json:"time_since_decision"`
 }
 
-// NewOutboxGuard creates a new outbox guard
+// NewOutboxGuard creates a new outbox guard with the default limits
 func NewOutboxGuard(capsManager *PositionCapsManager, quotesAdapter adapters.QuotesAdapter, observMgr *RiskObservabilityManager) *OutboxGuard {
+	return NewOutboxGuardWithConfig(capsManager, quotesAdapter, observMgr, DefaultOutboxGuardConfig())
+}
+
+// NewOutboxGuardWithConfig creates an outbox guard with explicit limits. Zero
+// limits fall back to the defaults. capsManager and observMgr may be nil, in
+// which case caps are not rechecked and cancellations are not event-logged.
+func NewOutboxGuardWithConfig(capsManager *PositionCapsManager, quotesAdapter adapters.QuotesAdapter, observMgr *RiskObservabilityManager, config OutboxGuardConfig) *OutboxGuard {
+	defaults := DefaultOutboxGuardConfig()
+	if config.MaxPriceDriftPct <= 0 {
+		config.MaxPriceDriftPct = defaults.MaxPriceDriftPct
+	}
+	if config.MaxDecisionStalenessSec <= 0 {
+		config.MaxDecisionStalenessSec = defaults.MaxDecisionStalenessSec
+	}
 	return &OutboxGuard{
 		capsManager:   capsManager,
 		quotesAdapter: quotesAdapter,
 		observMgr:     observMgr,
+		config:        config,
+		clock:         clock.Real{},
 	}
 }
 
+// SetClock sets the time source for decision staleness and cancellation timestamps
+func (og *OutboxGuard) SetClock(c clock.Clock) {
+	og.clock = clock.Or(c)
+}
+
+// MaxDecisionAge is how old a decision may be when its order is sent
+func (og *OutboxGuard) MaxDecisionAge() time.Duration {
+	return time.Duration(og.config.MaxDecisionStalenessSec * float64(time.Second))
+}
+
 // ValidateAndWriteOrder validates an order against current market conditions and writes it if approved
 func (og *OutboxGuard) ValidateAndWriteOrder(outboxWriter *outbox.Outbox, request *OrderRequest) error {
+	result, err := og.Validate(request)
+	if err != nil {
+		return err
+	}
+	
+	if !result.Approved {
+		if err := og.WriteCancellation(outboxWriter, request, result); err != nil {
+			return err
+		}
+		return fmt.Errorf("order blocked by outbox guard: %s", result.Reason)
+	}
+	
+	// Order approved - write to outbox
+	return outboxWriter.WriteOrder(request.Order)
+}
+
+// Validate checks an order against a fresh quote and the caps just before it
+// is sent. Callers that send orders elsewhere first (e.g. to a broker) use
+// this and WriteCancellation instead of ValidateAndWriteOrder.
+func (og *OutboxGuard) Validate(request *OrderRequest) (*GuardResult, error) {
 	start := time.Now()
 	
 	// Perform pre-send validation
 	result, err := og.validateOrder(request)
 	if err != nil {
-		return fmt.Errorf("outbox guard validation failed: %w", err)
+		return nil, fmt.Errorf("outbox guard validation failed: %w", err)
 	}
 	
 	// Record validation metrics
 	og.recordValidationMetrics(request, result, time.Since(start))
+	return result, nil
+}
+
+// WriteCancellation records a blocked order in the outbox as a cancelled
+// entry carrying the guard result
+func (og *OutboxGuard) WriteCancellation(outboxWriter *outbox.Outbox, request *OrderRequest, result *GuardResult) error {
+	guard, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("encode guard result: %w", err)
+	}
 	
-	if !result.Approved {
-		// Order was blocked - emit cancellation event and return error
-		cancellationOrder := outbox.Order{
-			ID:             request.Order.ID + "_cancelled",
-			Symbol:         request.Order.Symbol,
-			Intent:         "CANCELLED",
-			Timestamp:      time.Now(),
-			Status:         "cancelled",
-			IdempotencyKey: request.Order.IdempotencyKey + "_cancelled",
-		}
-		
-		// Write cancellation to outbox
-		if err := outboxWriter.WriteOrder(cancellationOrder); err != nil {
-			return fmt.Errorf("failed to write cancellation: %w", err)
-		}
-		
-		// Log structured event
+	cancellationOrder := outbox.Order{
+		ID:             request.Order.ID + "_cancelled",
+		Symbol:         request.Order.Symbol,
+		Intent:         "CANCELLED",
+		Timestamp:      og.clock.Now(),
+		Status:         "cancelled",
+		IdempotencyKey: request.Order.IdempotencyKey + "_cancelled",
+		Side:           request.Order.Side,
+		Quantity:       request.Order.Quantity,
+		NotionalUSD:    request.Order.NotionalUSD,
+		OrderType:      request.Order.OrderType,
+		LimitPrice:     request.Order.LimitPrice,
+		TimeInForce:    request.Order.TimeInForce,
+		Reason:         result.Reason,
+		Guard:          guard,
+	}
+	
+	// Write cancellation to outbox
+	if err := outboxWriter.WriteOrder(cancellationOrder); err != nil {
+		return fmt.Errorf("failed to write cancellation: %w", err)
+	}
+	
+	if og.observMgr != nil {
 		og.observMgr.LogStructuredEvent(
 			"paper_order_cancelled",
 			SeverityWarning,
@@ -89,23 +169,18 @@ func (og *OutboxGuard) ValidateAndWriteOrder(outboxWriter *outbox.Outbox, reques
 				"time_since_decision": result.TimeSinceDecision.Seconds(),
 			},
 			map[string]float64{
-				"price_drift_pct":     result.PriceDrift,
-				"validation_time_ms":  float64(time.Since(start).Milliseconds()),
+				"price_drift_pct": result.PriceDrift,
 			},
 			request.DecisionContext.CorrelationID,
 		)
-		
-		return fmt.Errorf("order blocked by outbox guard: %s", result.Reason)
 	}
-	
-	// Order approved - write to outbox
-	return outboxWriter.WriteOrder(request.Order)
+	return nil
 }
 
 // validateOrder performs the actual pre-send validation
 func (og *OutboxGuard) validateOrder(request *OrderRequest) (*GuardResult, error) {
 	// Calculate time since decision
-	timeSinceDecision := time.Since(request.DecisionTime)
+	timeSinceDecision := og.clock.Now().Sub(request.DecisionTime)
 	
 	// Get current quote for price drift check
 	quote, err := og.quotesAdapter.GetQuote(context.Background(), request.Order.Symbol)
@@ -125,44 +200,52 @@ func (og *OutboxGuard) validateOrder(request *OrderRequest) (*GuardResult, error
 		currentMidPrice = quote.Last
 	}
 	
-	// Calculate price drift
-	originalPrice := request.ExposureInfo.MidPrice
-	priceDrift := ((currentMidPrice - originalPrice) / originalPrice) * 100
-	
-	// Recalculate exposure with current price
-	currentNAV := og.capsManager.getCurrentNAV()
-	canIncrease, reason, currentExposureInfo, err := og.capsManager.CanIncrease(
-		request.Order.Symbol,
-		request.Order.Intent,
-		request.DecisionContext.Quantity,
-		currentMidPrice,
-		currentNAV,
-	)
-	
-	if err != nil {
-		return &GuardResult{
-			Approved:          false,
-			Reason:            "exposure_recalc_error",
-			TimeSinceDecision: timeSinceDecision,
-		}, err
+	// Calculate price drift from the price the decision was made at
+	originalPrice := request.DecisionContext.Price
+	if request.ExposureInfo != nil && request.ExposureInfo.MidPrice > 0 {
+		originalPrice = request.ExposureInfo.MidPrice
+	}
+	priceDrift := 0.0
+	if originalPrice > 0 {
+		priceDrift = ((currentMidPrice - originalPrice) / originalPrice) * 100
 	}
 	
 	result := &GuardResult{
 		OriginalExposure:  request.ExposureInfo,
-		CurrentExposure:   currentExposureInfo,
 		PriceDrift:        priceDrift,
 		TimeSinceDecision: timeSinceDecision,
 	}
 	
-	// Check if caps are now violated due to price drift
-	if !canIncrease {
-		result.Approved = false
-		result.Reason = fmt.Sprintf("cap_violation_on_send_%s", reason)
-		return result, nil
+	if og.capsManager != nil {
+		// Recalculate exposure with current price
+		currentNAV := og.capsManager.getCurrentNAV()
+		canIncrease, reason, currentExposureInfo, err := og.capsManager.CanIncrease(
+			request.Order.Symbol,
+			request.Order.Intent,
+			request.DecisionContext.Quantity,
+			currentMidPrice,
+			currentNAV,
+		)
+		
+		if err != nil {
+			return &GuardResult{
+				Approved:          false,
+				Reason:            "exposure_recalc_error",
+				TimeSinceDecision: timeSinceDecision,
+			}, err
+		}
+		result.CurrentExposure = currentExposureInfo
+		
+		// Check if caps are now violated due to price drift
+		if !canIncrease {
+			result.Approved = false
+			result.Reason = fmt.Sprintf("cap_violation_on_send_%s", reason)
+			return result, nil
+		}
 	}
 	
-	// Check for significant price drift (configurable threshold)
-	maxDriftPct := 2.0 // 2% max drift before cancellation
+	// Check for significant price drift
+	maxDriftPct := og.config.MaxPriceDriftPct
 	if abs(priceDrift) > maxDriftPct {
 		result.Approved = false
 		result.Reason = fmt.Sprintf("price_drift_%.2f_pct_exceeds_%.2f", abs(priceDrift), maxDriftPct)
@@ -170,7 +253,7 @@ func (og *OutboxGuard) validateOrder(request *OrderRequest) (*GuardResult, error
 	}
 	
 	// Check staleness - if too much time has passed since decision
-	maxStalenessSec := 10.0 // 10 second max staleness
+	maxStalenessSec := og.config.MaxDecisionStalenessSec
 	if timeSinceDecision.Seconds() > maxStalenessSec {
 		result.Approved = false
 		result.Reason = fmt.Sprintf("decision_stale_%.1fs_exceeds_%.1fs", timeSinceDecision.Seconds(), maxStalenessSec)
@@ -230,11 +313,14 @@ func abs(x float64) float64 {
 // CreateOrderRequest creates an OrderRequest from decision context and exposure info
 func CreateOrderRequest(order outbox.Order, decisionTime time.Time, decisionCtx DecisionContext, exposureInfo *ExposureInfo, cooldownInfo *CooldownInfo) *OrderRequest {
 	// Create a simple hash from cap state for race detection
-	checkHash := fmt.Sprintf("%.2f_%.2f_%d", 
-		exposureInfo.SymbolCapUSD, 
-		exposureInfo.NAV, 
-		exposureInfo.DailyTradesCount,
-	)
+	checkHash := ""
+	if exposureInfo != nil {
+		checkHash = fmt.Sprintf("%.2f_%.2f_%d", 
+			exposureInfo.SymbolCapUSD, 
+			exposureInfo.NAV, 
+			exposureInfo.DailyTradesCount,
+		)
+	}
 	
 	return &OrderRequest{
 		Order:           order,
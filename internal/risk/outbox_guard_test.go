@@ -0,0 +1,80 @@
+package risk
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/adapters"
+	"github.com/Rajchodisetti/trading-app/internal/outbox"
+)
+
+func TestOutboxGuard_ValidateAndWriteOrder(t *testing.T) {
+	quotes := adapters.NewMockQuotesAdapter()
+	quotes.SetLatency(0)
+	guard := NewOutboxGuardWithConfig(nil, quotes, nil, OutboxGuardConfig{MaxPriceDriftPct: 1, MaxDecisionStalenessSec: 5})
+
+	// AAPL mock mid is 210.05
+	tests := []struct {
+		name       string
+		decidedAt  time.Duration // before now
+		price      float64
+		wantReason string // empty = approved
+	}{
+		{"fresh and close", 0, 209.50, ""},
+		{"price drifted", 0, 200.00, "price_drift_5.03_pct_exceeds_1.00"},
+		{"decision stale", 6 * time.Second, 210.00, "decision_stale_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ob, err := outbox.New(filepath.Join(t.TempDir(), "outbox.jsonl"), 90)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ob.Close()
+
+			order := outbox.Order{ID: "o1", Symbol: "AAPL", Intent: "BUY_1X", Side: "BUY", Quantity: 10, Status: "NEW", IdempotencyKey: "k1"}
+			decidedAt := time.Now().Add(-tt.decidedAt)
+			req := CreateOrderRequest(order, decidedAt, DecisionContext{Symbol: "AAPL", Intent: "BUY_1X", Quantity: 10, Price: tt.price}, &ExposureInfo{MidPrice: tt.price}, nil)
+
+			err = guard.ValidateAndWriteOrder(ob, req)
+			history, rerr := ob.Replay()
+			if rerr != nil {
+				t.Fatal(rerr)
+			}
+
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Fatalf("expected approval, got %v", err)
+				}
+				if len(history) != 1 || history["o1"] == nil {
+					t.Fatalf("expected order o1 written, got %+v", history)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected order to be blocked")
+			}
+			if len(history) != 1 || history["o1_cancelled"] == nil {
+				t.Fatalf("expected only a cancellation entry, got %+v", history)
+			}
+			cancelled := history["o1_cancelled"].Order
+			if cancelled.Status != "cancelled" || cancelled.Quantity != 10 {
+				t.Fatalf("unexpected cancellation entry %+v", cancelled)
+			}
+			if !strings.HasPrefix(cancelled.Reason, tt.wantReason) {
+				t.Fatalf("reason %q, want prefix %q", cancelled.Reason, tt.wantReason)
+			}
+			var result GuardResult
+			if err := json.Unmarshal(cancelled.Guard, &result); err != nil {
+				t.Fatalf("decode guard result: %v", err)
+			}
+			if result.Approved || result.Reason != cancelled.Reason {
+				t.Fatalf("unexpected guard result %+v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package risk
+
+import (
+	"math"
+
+	"github.com/Rajchodisetti/trading-app/internal/observ"
+)
+
+// SizingConfig controls how notional is converted to share quantity
+type SizingConfig struct {
+	LotSize        int     `yaml:"lot_size"`         // shares per lot; buys round down to whole lots
+	MinNotionalUSD float64 `yaml:"min_notional_usd"` // orders below this notional are dropped
+}
+
+// SizeRequest is a proposed trade to size
+type SizeRequest struct {
+	Symbol      string
+	Intent      string  // BUY_1X | BUY_5X | SELL_SHORT | REDUCE | EXIT | COVER
+	NotionalUSD float64 // target notional before multipliers
+	Price       float64 // current reference price
+	PositionQty int     // current position, negative when short; caps sells and covers
+}
+
+// SizeResult is the sized order
+type SizeResult struct {
+	Quantity    int     `json:"quantity"`
+	NotionalUSD float64 `json:"notional_usd"`
+	Multiplier  float64 `json:"multiplier"`
+	Skipped     string  `json:"skipped,omitempty"` // why the order sized to zero
+}
+
+// Sizer converts decision notionals into integer share quantities
+type Sizer struct {
+	config         SizingConfig
+	drawdownMgr    *DrawdownManager
+	circuitBreaker *CircuitBreaker
+}
+
+// NewSizer creates a sizer; drawdown manager and circuit breaker are optional
+func NewSizer(config SizingConfig, drawdownMgr *DrawdownManager, circuitBreaker *CircuitBreaker) *Sizer {
+	if config.LotSize <= 0 {
+		config.LotSize = 1
+	}
+	return &Sizer{
+		config:         config,
+		drawdownMgr:    drawdownMgr,
+		circuitBreaker: circuitBreaker,
+	}
+}
+
+// Multiplier returns the combined drawdown and circuit-breaker size multiplier
+func (s *Sizer) Multiplier() float64 {
+	m := 1.0
+	if s.drawdownMgr != nil {
+		m *= s.drawdownMgr.GetSizeMultiplier()
+	}
+	if s.circuitBreaker != nil {
+		_, cbMult := s.circuitBreaker.GetState()
+		m *= cbMult
+	}
+	return m
+}
+
+// Size converts the request to a quantity. Entries (buys and short sales) are
+// scaled by the risk multipliers and rounded down to whole lots; sells are
+// capped at the open position and never scaled, so risk reduction is never
+// shrunk. EXIT sells the whole position and COVER buys back the whole short.
+func (s *Sizer) Size(req SizeRequest) SizeResult {
+	result := SizeResult{Multiplier: 1.0}
+
+	if req.Price <= 0 {
+		result.Skipped = "no_price"
+		return s.record(req, result)
+	}
+
+	switch req.Intent {
+	case "EXIT":
+		result.Quantity = req.PositionQty
+	case "COVER":
+		result.Quantity = -req.PositionQty
+	case "REDUCE":
+		// A notional built from whole shares sizes back to them despite float error
+		qty := int(math.Floor(req.NotionalUSD/req.Price + 1e-9))
+		if qty < 1 && req.PositionQty > 0 {
+			qty = 1
+		}
+		if qty > req.PositionQty {
+			qty = req.PositionQty
+		}
+		result.Quantity = qty
+	default:
+		result.Multiplier = s.Multiplier()
+		qty := int(math.Floor(req.NotionalUSD * result.Multiplier / req.Price))
+		result.Quantity = roundQuantity(qty, s.config.LotSize)
+	}
+
+	if result.Quantity <= 0 {
+		result.Quantity = 0
+		if result.Skipped == "" {
+			result.Skipped = "below_one_lot"
+		}
+		return s.record(req, result)
+	}
+
+	result.NotionalUSD = float64(result.Quantity) * req.Price
+	if !isRiskReducing(req.Intent) && result.NotionalUSD < s.config.MinNotionalUSD {
+		result.Quantity = 0
+		result.NotionalUSD = 0
+		result.Skipped = "below_min_notional"
+	}
+	return s.record(req, result)
+}
+
+func (s *Sizer) record(req SizeRequest, result SizeResult) SizeResult {
+	if result.Skipped != "" {
+		observ.IncCounter("sizing_skipped_total", map[string]string{
+			"symbol": req.Symbol,
+			"reason": result.Skipped,
+		})
+		return result
+	}
+	observ.Observe("order_quantity_shares", float64(result.Quantity), map[string]string{"symbol": req.Symbol})
+	return result
+}
@@ -0,0 +1,54 @@
+package risk
+
+import "testing"
+
+func TestSizer_Size(t *testing.T) {
+	dm := NewDrawdownManager()
+	dm.sizeMultiplier = 0.5
+	sizer := NewSizer(SizingConfig{LotSize: 10, MinNotionalUSD: 500}, dm, nil)
+
+	tests := []struct {
+		name    string
+		req     SizeRequest
+		wantQty int
+		skipped string
+	}{
+		{"buy scaled and lot rounded", SizeRequest{Symbol: "NVDA", Intent: "BUY_5X", NotionalUSD: 10000, Price: 450}, 10, ""},
+		{"buy below one lot", SizeRequest{Symbol: "NVDA", Intent: "BUY_1X", NotionalUSD: 2000, Price: 450}, 0, "below_one_lot"},
+		{"buy below min notional", SizeRequest{Symbol: "F", Intent: "BUY_1X", NotionalUSD: 900, Price: 10}, 0, "below_min_notional"},
+		{"reduce capped at position, not scaled", SizeRequest{Symbol: "AAPL", Intent: "REDUCE", NotionalUSD: 2000, Price: 100, PositionQty: 7}, 7, ""},
+		{"reduce partial", SizeRequest{Symbol: "AAPL", Intent: "REDUCE", NotionalUSD: 250, Price: 100, PositionQty: 7}, 2, ""},
+		{"exit whole position", SizeRequest{Symbol: "AAPL", Intent: "EXIT", NotionalUSD: 0, Price: 100, PositionQty: 13}, 13, ""},
+		{"short scaled like a buy", SizeRequest{Symbol: "NVDA", Intent: "SELL_SHORT", NotionalUSD: 10000, Price: 450}, 10, ""},
+		{"cover whole short", SizeRequest{Symbol: "AAPL", Intent: "COVER", Price: 100, PositionQty: -13}, 13, ""},
+		{"no price", SizeRequest{Symbol: "AAPL", Intent: "BUY_1X", NotionalUSD: 2000}, 0, "no_price"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sizer.Size(tt.req)
+			if got.Quantity != tt.wantQty || got.Skipped != tt.skipped {
+				t.Fatalf("got qty=%d skipped=%q, want qty=%d skipped=%q", got.Quantity, got.Skipped, tt.wantQty, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestSizer_CircuitBreakerMultiplier(t *testing.T) {
+	cb := NewCircuitBreaker(t.TempDir() + "/circuit_breaker_events.jsonl")
+	cb.mu.Lock()
+	cb.setState(StateRestricted, "test", "sizer-test")
+	want := cb.sizeMultiplier
+	cb.mu.Unlock()
+	sizer := NewSizer(SizingConfig{LotSize: 1}, nil, cb)
+
+	if want >= 1 || sizer.Multiplier() != want {
+		t.Fatalf("want the breaker's %v multiplier, got %v", want, sizer.Multiplier())
+	}
+	// Entries shrink with the breaker; exits are never scaled
+	if got := sizer.Size(SizeRequest{Symbol: "NVDA", Intent: "BUY_1X", NotionalUSD: 10000, Price: 100}); got.Quantity != int(100*want) {
+		t.Fatalf("buy: got qty=%d, want %d", got.Quantity, int(100*want))
+	}
+	if got := sizer.Size(SizeRequest{Symbol: "NVDA", Intent: "EXIT", Price: 100, PositionQty: 40}); got.Quantity != 40 {
+		t.Fatalf("exit: got qty=%d, want 40", got.Quantity)
+	}
+}
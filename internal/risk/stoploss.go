@@ -1,10 +1,10 @@
 package risk
 
 import (
+	"sync"
 	"time"
 	
 	"github.com/Rajchodisetti/trading-app/internal/observ"
-	"github.com/Rajchodisetti/trading-app/internal/outbox"
 )
 
 // StopLossTrigger represents a stop-loss trigger event
@@ -15,6 +15,7 @@ type StopLossTrigger struct {
 	TriggerPrice    float64   `json:"trigger_price"`
 	CurrentPrice    float64   `json:"current_price"`
 	EntryVWAP       float64   `json:"entry_vwap"`
+	Short           bool      `json:"short,omitempty"` // stop on a short: the price rose through it
 	LossPct         float64   `json:"loss_pct"`
 	TimestampUTC    time.Time `json:"timestamp_utc"`
 	TradingSession  string    `json:"trading_session"` // "RTH" or "AH"
@@ -22,22 +23,24 @@ type StopLossTrigger struct {
 
 // StopLossManager manages stop-loss triggers and cooldowns
 type StopLossManager struct {
+	mu            sync.Mutex // corporate actions rename symbols off the pipeline goroutine
 	triggers      map[string]StopLossTrigger // symbol -> last trigger
 	cooldowns     map[string]time.Time       // symbol -> cooldown until
-	outboxManager *outbox.Outbox
 }
 
 // NewStopLossManager creates a new stop-loss manager
-func NewStopLossManager(outboxMgr *outbox.Outbox) *StopLossManager {
+func NewStopLossManager() *StopLossManager {
 	return &StopLossManager{
 		triggers:      make(map[string]StopLossTrigger),
 		cooldowns:     make(map[string]time.Time),
-		outboxManager: outboxMgr,
 	}
 }
 
-// CheckStopLoss evaluates if a position should trigger a stop-loss
-func (slm *StopLossManager) CheckStopLoss(symbol string, currentPrice, entryVWAP float64, config StopLossConfig, isAfterHours bool, now time.Time) (bool, error) {
+// CheckStopLoss evaluates if a position should trigger a stop-loss. A long
+// loses as the price falls below entry, a short as it rises above. The
+// caller closes out a triggered position through its order path, with
+// StopIntent.
+func (slm *StopLossManager) CheckStopLoss(symbol string, currentPrice, entryVWAP float64, short bool, config StopLossConfig, isAfterHours bool, now time.Time) (bool, error) {
 	if !config.Enabled {
 		return false, nil
 	}
@@ -47,6 +50,9 @@ func (slm *StopLossManager) CheckStopLoss(symbol string, currentPrice, entryVWAP
 		return false, nil
 	}
 	
+	slm.mu.Lock()
+	defer slm.mu.Unlock()
+	
 	// Check cooldown
 	if cooldownUntil, exists := slm.cooldowns[symbol]; exists && now.Before(cooldownUntil) {
 		observ.SetGauge("stop_cooldown_active", 1, map[string]string{"symbol": symbol})
@@ -63,6 +69,9 @@ func (slm *StopLossManager) CheckStopLoss(symbol string, currentPrice, entryVWAP
 	}
 	
 	lossPct := ((entryVWAP - currentPrice) / entryVWAP) * 100
+	if short {
+		lossPct = -lossPct
+	}
 	
 	// Check if loss exceeds threshold
 	shouldTrigger := lossPct >= config.DefaultStopLossPct
@@ -86,6 +95,7 @@ func (slm *StopLossManager) CheckStopLoss(symbol string, currentPrice, entryVWAP
 			TriggerPrice:   currentPrice,
 			CurrentPrice:   currentPrice,
 			EntryVWAP:      entryVWAP,
+			Short:          short,
 			LossPct:        lossPct,
 			TimestampUTC:   now,
 			TradingSession: getTradingSession(isAfterHours),
@@ -98,15 +108,8 @@ func (slm *StopLossManager) CheckStopLoss(symbol string, currentPrice, entryVWAP
 		slm.cooldowns[symbol] = now.Add(time.Duration(config.CooldownHours) * time.Hour)
 		observ.SetGauge("stop_cooldown_active", 1, map[string]string{"symbol": symbol})
 		
-		// Emit stop-loss order
-		err := slm.emitStopOrder(trigger)
-		if err != nil {
-			return false, err
-		}
-		
 		// Update metrics
 		observ.IncCounter("stop_triggers_total", map[string]string{"symbol": symbol, "type": "absolute"})
-		observ.IncCounter("stop_orders_sent_total", map[string]string{"symbol": symbol})
 		
 		return true, nil
 	}
@@ -116,6 +119,8 @@ func (slm *StopLossManager) CheckStopLoss(symbol string, currentPrice, entryVWAP
 
 // IsInCooldown checks if a symbol is in stop-loss cooldown
 func (slm *StopLossManager) IsInCooldown(symbol string, now time.Time) bool {
+	slm.mu.Lock()
+	defer slm.mu.Unlock()
 	if cooldownUntil, exists := slm.cooldowns[symbol]; exists {
 		return now.Before(cooldownUntil)
 	}
@@ -124,26 +129,30 @@ func (slm *StopLossManager) IsInCooldown(symbol string, now time.Time) bool {
 
 // GetCooldownUntil returns the cooldown expiry time for a symbol
 func (slm *StopLossManager) GetCooldownUntil(symbol string) (time.Time, bool) {
+	slm.mu.Lock()
+	defer slm.mu.Unlock()
 	cooldownUntil, exists := slm.cooldowns[symbol]
 	return cooldownUntil, exists
 }
 
-// emitStopOrder creates a paper SELL order for the stop-loss
-func (slm *StopLossManager) emitStopOrder(trigger StopLossTrigger) error {
-	if slm.outboxManager == nil {
-		return nil // No outbox available
+// RenameSymbol carries a symbol's stop-loss cooldown over to its new symbol.
+// Entry prices come from the portfolio, which adjusts them for splits.
+func (slm *StopLossManager) RenameSymbol(from, to string) {
+	slm.mu.Lock()
+	defer slm.mu.Unlock()
+	if cooldownUntil, exists := slm.cooldowns[from]; exists {
+		slm.cooldowns[to] = cooldownUntil
+		delete(slm.cooldowns, from)
 	}
-	
-	order := outbox.Order{
-		ID:             "stop_" + trigger.Symbol + "_" + trigger.PositionID,
-		Symbol:         trigger.Symbol,
-		Intent:         "REDUCE", // Reduce position due to stop-loss
-		Timestamp:      trigger.TimestampUTC,
-		Status:         "pending",
-		IdempotencyKey: trigger.Symbol + "_stop_" + trigger.PositionID,
+}
+
+// StopIntent is the intent of the order closing out a stopped position: a
+// REDUCE of the whole position for a long, a COVER for a short
+func StopIntent(short bool) string {
+	if short {
+		return "COVER"
 	}
-	
-	return slm.outboxManager.WriteOrder(order)
+	return "REDUCE"
 }
 
 // generatePositionID creates a unique position ID for stop-loss tracking
@@ -167,4 +176,5 @@ type StopLossConfig struct {
 	EmergencyStopLossPct float64
 	AllowAfterHours      bool
 	CooldownHours        int
+	EntryReference       string // vwap or latest_lot: which entry the loss is measured from
 }
\ No newline at end of file
@@ -0,0 +1,162 @@
+package strategy
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/Rajchodisetti/trading-app/internal/config"
+	"github.com/Rajchodisetti/trading-app/internal/decision"
+)
+
+const LexiconSentimentName = "lexicon_sentiment"
+
+// Default word lists; each can be replaced from config via words.<list>
+var (
+	defaultPositiveWords = []string{
+		"approval", "approved", "beat", "beats", "breakthrough", "exceeds", "efficacy",
+		"gain", "gains", "growth", "outperform", "positive", "profit", "promising",
+		"raise", "raised", "raises", "rally", "record", "soar", "soars", "strong",
+		"surge", "surges", "upgrade", "upgraded", "win", "wins",
+	}
+	defaultNegativeWords = []string{
+		"bankruptcy", "cut", "cuts", "decline", "declines", "delay", "delayed",
+		"downgrade", "downgraded", "fail", "failed", "fails", "fall", "falls", "fraud",
+		"investigation", "lawsuit", "loss", "losses", "lowers", "miss", "misses",
+		"negative", "plunge", "plunges", "probe", "recall", "slip", "slips", "warning", "weak",
+	}
+	defaultNegators = []string{"no", "not", "never", "without"}
+	defaultHedges   = []string{"could", "may", "might", "needs", "reportedly", "rumor", "unclear"}
+)
+
+// LexiconSentiment scores headlines by counting positive and negative
+// lexicon hits. A negator within two tokens before a hit flips its polarity;
+// hedge words lower confidence. Items without any hit produce no advice.
+type LexiconSentiment struct {
+	positive, negative, negators, hedges map[string]bool
+
+	maxScore       float64
+	baseConfidence float64
+	perHit         float64
+	hedgePenalty   float64
+	maxConfidence  float64
+	sourceWeight   float64
+	includeBody    bool
+	ttlSeconds     int
+}
+
+// NewLexiconSentiment builds the scorer from config
+func NewLexiconSentiment(cfg config.StrategyConfig) (Strategy, error) {
+	s := &LexiconSentiment{
+		positive:       wordSet(cfg, "positive", defaultPositiveWords),
+		negative:       wordSet(cfg, "negative", defaultNegativeWords),
+		negators:       wordSet(cfg, "negators", defaultNegators),
+		hedges:         wordSet(cfg, "hedges", defaultHedges),
+		maxScore:       param(cfg, "max_score", 0.8),
+		baseConfidence: param(cfg, "base_confidence", 0.5),
+		perHit:         param(cfg, "confidence_per_hit", 0.1),
+		hedgePenalty:   param(cfg, "hedge_penalty", 0.1),
+		maxConfidence:  param(cfg, "max_confidence", 0.9),
+		sourceWeight:   param(cfg, "default_source_weight", 1.0),
+		includeBody:    param(cfg, "include_body", 0) != 0,
+		ttlSeconds:     int(param(cfg, "ttl_seconds", 0)),
+	}
+	if s.maxScore <= 0 || s.maxScore > 1 {
+		return nil, fmt.Errorf("max_score must be in (0,1], got %v", s.maxScore)
+	}
+	if len(s.positive) == 0 && len(s.negative) == 0 {
+		return nil, fmt.Errorf("lexicon has no positive or negative words")
+	}
+	return s, nil
+}
+
+func (s *LexiconSentiment) Name() string    { return LexiconSentimentName }
+func (s *LexiconSentiment) Version() string { return "1.0.0" }
+
+func (s *LexiconSentiment) Score(in Inputs) []decision.Advice {
+	var advs []decision.Advice
+	for _, n := range in.News {
+		text := n.Headline
+		if s.includeBody {
+			text += " " + n.Body
+		}
+		score, conf, ok := s.ScoreText(text)
+		if !ok {
+			continue
+		}
+		sw := n.SourceWeight
+		if sw <= 0 {
+			sw = s.sourceWeight
+		}
+		advs = append(advs, decision.Advice{
+			Symbol: in.Symbol, Score: score, Confidence: conf, SourceWeight: sw,
+			Provider: n.Provider, IsPR: n.IsPressRelease, PublishedAt: publishedAt(n, in.Now),
+			TTLSeconds: s.ttlSeconds, SourceID: n.ID,
+		})
+	}
+	return advs
+}
+
+// ScoreText returns the sentiment score and confidence for a piece of text;
+// ok is false when no lexicon word matched
+func (s *LexiconSentiment) ScoreText(text string) (score, confidence float64, ok bool) {
+	tokens := tokenize(text)
+
+	var pos, neg, hedges int
+	for i, tok := range tokens {
+		if s.hedges[tok] {
+			hedges++
+			continue
+		}
+		polarity := 0
+		switch {
+		case s.positive[tok]:
+			polarity = 1
+		case s.negative[tok]:
+			polarity = -1
+		default:
+			continue
+		}
+		for j := i - 1; j >= 0 && j >= i-2; j-- {
+			if s.negators[tokens[j]] {
+				polarity = -polarity
+				break
+			}
+		}
+		if polarity > 0 {
+			pos++
+		} else {
+			neg++
+		}
+	}
+
+	hits := pos + neg
+	if hits == 0 {
+		return 0, 0, false
+	}
+
+	score = s.maxScore * float64(pos-neg) / float64(hits)
+	confidence = s.baseConfidence + s.perHit*float64(hits) - s.hedgePenalty*float64(hedges)
+	confidence = math.Max(0.1, math.Min(s.maxConfidence, confidence))
+	return score, confidence, true
+}
+
+// tokenize lowercases text and splits it on anything that is not a letter or digit
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func wordSet(cfg config.StrategyConfig, list string, def []string) map[string]bool {
+	words, ok := cfg.Words[list]
+	if !ok {
+		words = def
+	}
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[strings.ToLower(strings.TrimSpace(w))] = true
+	}
+	return set
+}
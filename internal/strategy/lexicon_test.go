@@ -0,0 +1,92 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Rajchodisetti/trading-app/internal/config"
+	"github.com/Rajchodisetti/trading-app/internal/ingest"
+)
+
+func newLexicon(t *testing.T) *LexiconSentiment {
+	s, err := NewLexiconSentiment(config.StrategyConfig{Name: LexiconSentimentName, Enabled: true})
+	require.NoError(t, err)
+	return s.(*LexiconSentiment)
+}
+
+func TestLexiconSentiment_ScoreText(t *testing.T) {
+	s := newLexicon(t)
+
+	tests := []struct {
+		name     string
+		text     string
+		wantSign int
+		wantOK   bool
+	}{
+		{"positive", "BioX Reports Strong Phase 2 Efficacy Results", 1, true},
+		{"negative", "Apple misses estimates, shares plunge", -1, true},
+		{"negated positive", "Trial results were not strong", -1, true},
+		{"mixed balanced", "Strong revenue but weak guidance", 0, true},
+		{"no hits", "Company schedules annual meeting", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, conf, ok := s.ScoreText(tt.text)
+			assert.Equal(t, tt.wantOK, ok)
+			if !ok {
+				return
+			}
+			switch tt.wantSign {
+			case 1:
+				assert.Greater(t, score, 0.0)
+			case -1:
+				assert.Less(t, score, 0.0)
+			default:
+				assert.Equal(t, 0.0, score)
+			}
+			assert.GreaterOrEqual(t, conf, 0.1)
+			assert.LessOrEqual(t, conf, 0.9)
+		})
+	}
+}
+
+func TestLexiconSentiment_HedgesLowerConfidence(t *testing.T) {
+	s := newLexicon(t)
+
+	_, plain, _ := s.ScoreText("Phase 2 signals promising")
+	_, hedged, _ := s.ScoreText("Phase 2 signals promising but needs Phase 3")
+	assert.Less(t, hedged, plain)
+}
+
+func TestRegistry_BuildStampsStrategy(t *testing.T) {
+	r := DefaultRegistry()
+	strategies, err := r.Build([]config.StrategyConfig{
+		{Name: SourcePriorName, Enabled: true},
+		{Name: TrendLiteName, Enabled: false},
+		{Name: LexiconSentimentName, Enabled: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, strategies, 2)
+
+	in := Inputs{
+		Symbol: "BIOX",
+		News: []ingest.NewsItem{{
+			ID: "bw-1", Provider: "businesswire", Headline: "BioX beats estimates",
+			IsPressRelease: true, PublishedAtUTC: "2025-08-25T14:45:14Z",
+		}},
+		Now: time.Date(2025, 8, 25, 15, 0, 0, 0, time.UTC),
+	}
+	advs := ScoreAll(strategies, in)
+	require.Len(t, advs, 2)
+	assert.Equal(t, SourcePriorName, advs[0].Strategy)
+	assert.Equal(t, 0.8, advs[0].Score)
+	assert.Equal(t, LexiconSentimentName, advs[1].Strategy)
+	assert.Equal(t, "1.0.0", advs[1].Version)
+
+	_, err = r.Build([]config.StrategyConfig{{Name: "nope", Enabled: true}})
+	assert.Error(t, err)
+	assert.Error(t, r.Register(SourcePriorName, NewSourcePrior))
+}
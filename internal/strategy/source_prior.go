@@ -0,0 +1,56 @@
+package strategy
+
+import (
+	"github.com/Rajchodisetti/trading-app/internal/config"
+	"github.com/Rajchodisetti/trading-app/internal/decision"
+)
+
+const SourcePriorName = "source_prior"
+
+// SourcePrior scores every news item with a fixed prior by source type:
+// press releases and editorial coverage each get their own score,
+// confidence and weight. It ignores headline content entirely, so it skips
+// corrections by default: a fixed positive prior would misread a story
+// that was corrected downward.
+type SourcePrior struct {
+	editorialScore, editorialConf, editorialWeight float64
+	prScore, prConf, prWeight                      float64
+	ttlSeconds                                     int
+	scoreCorrections                               bool
+}
+
+// NewSourcePrior builds the strategy; defaults match the original hardcoded priors
+func NewSourcePrior(cfg config.StrategyConfig) (Strategy, error) {
+	return &SourcePrior{
+		editorialScore:   param(cfg, "editorial_score", 0.6),
+		editorialConf:    param(cfg, "editorial_confidence", 0.8),
+		editorialWeight:  param(cfg, "editorial_weight", 1.0),
+		prScore:          param(cfg, "pr_score", 0.8),
+		prConf:           param(cfg, "pr_confidence", 0.8),
+		prWeight:         param(cfg, "pr_weight", 1.2),
+		ttlSeconds:       int(param(cfg, "ttl_seconds", 0)),
+		scoreCorrections: param(cfg, "score_corrections", 0) != 0,
+	}, nil
+}
+
+func (s *SourcePrior) Name() string    { return SourcePriorName }
+func (s *SourcePrior) Version() string { return "1.0.0" }
+
+func (s *SourcePrior) Score(in Inputs) []decision.Advice {
+	var advs []decision.Advice
+	for _, n := range in.News {
+		if n.IsCorrection && !s.scoreCorrections {
+			continue
+		}
+		score, conf, sw := s.editorialScore, s.editorialConf, s.editorialWeight
+		if n.IsPressRelease {
+			score, conf, sw = s.prScore, s.prConf, s.prWeight
+		}
+		advs = append(advs, decision.Advice{
+			Symbol: in.Symbol, Score: score, Confidence: conf, SourceWeight: sw,
+			Provider: n.Provider, IsPR: n.IsPressRelease, PublishedAt: publishedAt(n, in.Now),
+			TTLSeconds: s.ttlSeconds, SourceID: n.ID,
+		})
+	}
+	return advs
+}
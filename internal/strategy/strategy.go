@@ -0,0 +1,149 @@
+package strategy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Rajchodisetti/trading-app/internal/config"
+	"github.com/Rajchodisetti/trading-app/internal/decision"
+	"github.com/Rajchodisetti/trading-app/internal/ingest"
+)
+
+// Inputs is everything a strategy may look at when scoring one symbol
+type Inputs struct {
+	Symbol   string
+	News     []ingest.NewsItem // news for the symbol in arrival order
+	Ticks    []ingest.Tick     // recent ticks, oldest first
+	Features decision.Features
+	Now      time.Time
+}
+
+// Strategy turns market inputs into advice for the decision engine
+type Strategy interface {
+	Name() string
+	Version() string
+	Score(in Inputs) []decision.Advice
+}
+
+// Factory builds a strategy from its config entry
+type Factory func(cfg config.StrategyConfig) (Strategy, error)
+
+// Registry maps strategy names to factories
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// DefaultRegistry returns a registry with all built-in strategies registered
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.mustRegister(SourcePriorName, NewSourcePrior)
+	r.mustRegister(TrendLiteName, NewTrendLite)
+	r.mustRegister(LexiconSentimentName, NewLexiconSentiment)
+	return r
+}
+
+// Register adds a factory under name; names must be unique
+func (r *Registry) Register(name string, f Factory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.factories[name]; exists {
+		return fmt.Errorf("strategy %q already registered", name)
+	}
+	r.factories[name] = f
+	return nil
+}
+
+func (r *Registry) mustRegister(name string, f Factory) {
+	if err := r.Register(name, f); err != nil {
+		panic(err)
+	}
+}
+
+// Names returns the registered strategy names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Build instantiates every enabled strategy in config order
+func (r *Registry) Build(cfgs []config.StrategyConfig) ([]Strategy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []Strategy
+	for _, c := range cfgs {
+		if !c.Enabled {
+			continue
+		}
+		f, ok := r.factories[c.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown strategy %q", c.Name)
+		}
+		s, err := f(c)
+		if err != nil {
+			return nil, fmt.Errorf("build strategy %q: %w", c.Name, err)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// ScoreAll runs every strategy and stamps each advice with its strategy name
+// and version, and with the retracting correction when its source was superseded
+func ScoreAll(strategies []Strategy, in Inputs) []decision.Advice {
+	retractedBy := map[string]string{}
+	for _, n := range in.News {
+		if n.RetractedBy != "" {
+			retractedBy[n.ID] = n.RetractedBy
+		}
+	}
+
+	var advs []decision.Advice
+	for _, s := range strategies {
+		for _, a := range s.Score(in) {
+			if a.Symbol == "" {
+				a.Symbol = in.Symbol
+			}
+			a.Strategy = s.Name()
+			a.Version = s.Version()
+			if a.SourceID != "" {
+				a.RetractedBy = retractedBy[a.SourceID]
+			}
+			advs = append(advs, a)
+		}
+	}
+	return advs
+}
+
+// param returns a numeric parameter or its default
+func param(cfg config.StrategyConfig, key string, def float64) float64 {
+	if v, ok := cfg.Params[key]; ok {
+		return v
+	}
+	return def
+}
+
+// publishedAt parses a news timestamp, falling back to now
+func publishedAt(n ingest.NewsItem, now time.Time) time.Time {
+	t, err := time.Parse(time.RFC3339, n.PublishedAtUTC)
+	if err != nil {
+		return now
+	}
+	return t
+}
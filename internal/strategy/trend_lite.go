@@ -0,0 +1,37 @@
+package strategy
+
+import (
+	"github.com/Rajchodisetti/trading-app/internal/config"
+	"github.com/Rajchodisetti/trading-app/internal/decision"
+)
+
+const TrendLiteName = "trend_lite"
+
+// TrendLite emits a single positive advice when price trades above its 5-minute VWAP
+type TrendLite struct {
+	score, conf, weight float64
+	ttlSeconds          int
+}
+
+// NewTrendLite builds the strategy from config
+func NewTrendLite(cfg config.StrategyConfig) (Strategy, error) {
+	return &TrendLite{
+		score:      param(cfg, "score", 0.6),
+		conf:       param(cfg, "confidence", 0.7),
+		weight:     param(cfg, "source_weight", 1.0),
+		ttlSeconds: int(param(cfg, "ttl_seconds", 0)),
+	}, nil
+}
+
+func (s *TrendLite) Name() string    { return TrendLiteName }
+func (s *TrendLite) Version() string { return "1.0.0" }
+
+func (s *TrendLite) Score(in Inputs) []decision.Advice {
+	if in.Features.Last <= in.Features.VWAP5m {
+		return nil
+	}
+	return []decision.Advice{{
+		Symbol: in.Symbol, Score: s.score, Confidence: s.conf, SourceWeight: s.weight,
+		Provider: "trend-lite", IsPR: false, PublishedAt: in.Now, TTLSeconds: s.ttlSeconds,
+	}}
+}